@@ -0,0 +1,394 @@
+// Package authclient is a typed client for the GraphQL API of go-auth. It keeps the tokens of the logged in user on a
+// pluggable TokenStore, refreshes the access token before it expires and returns the errors of the API as *Error,
+// comparable with the Err values of the package
+package authclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshBefore is how long before the expiration the access token is refreshed
+const DefaultRefreshBefore = time.Minute
+
+// ErrNotLoggedIn is returned by Refresh when there isn't a refresh token on the store
+var ErrNotLoggedIn = errors.New("There isn't a refresh token stored")
+
+const userFields = `
+	id
+	email
+	roles
+	active
+	memberships {
+		organization { id name slug createdAt updatedAt }
+		role
+	}
+	deletionScheduledAt
+	createdAt
+	updatedAt
+`
+
+const authPayloadFields = `
+	user {` + userFields + `}
+	token
+	refreshToken
+`
+
+// Config defines how the Client talks to go-auth. The zero value is usable
+type Config struct {
+	// HTTPClient sends the requests. http.DefaultClient is used when it's nil
+	HTTPClient *http.Client
+
+	// Store keeps the tokens. A new MemoryStore is used when it's nil
+	Store TokenStore
+
+	// RefreshBefore is how long before the expiration the access token is refreshed. DefaultRefreshBefore is used
+	// when it's zero
+	RefreshBefore time.Duration
+}
+
+// Client calls the GraphQL API of a go-auth server. It's safe for concurrent use
+type Client struct {
+	url           string
+	httpClient    *http.Client
+	store         TokenStore
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	// mutex serializes the use of the store, so concurrent calls don't refresh the same token twice
+	mutex sync.Mutex
+}
+
+// Organization is an organization of go-auth
+type Organization struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Membership is the role of a user in an organization
+type Membership struct {
+	Organization Organization `json:"organization"`
+	Role         string       `json:"role"`
+}
+
+// User is a user of go-auth
+type User struct {
+	ID                  string       `json:"id"`
+	Email               string       `json:"email"`
+	Roles               []string     `json:"roles"`
+	Active              bool         `json:"active"`
+	Memberships         []Membership `json:"memberships"`
+	DeletionScheduledAt *string      `json:"deletionScheduledAt"`
+	CreatedAt           string       `json:"createdAt"`
+	UpdatedAt           string       `json:"updatedAt"`
+}
+
+// AuthPayload is the user and the tokens returned by the login, the sign up and the refresh
+type AuthPayload struct {
+	User         User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Claims are the claims of a valid token
+type Claims struct {
+	Iss    string   `json:"iss"`
+	Sub    string   `json:"sub"`
+	Org    *string  `json:"org"`
+	Groups []string `json:"groups"`
+	Exp    int64    `json:"exp"`
+	Iat    int64    `json:"iat"`
+}
+
+// ValidateTokenPayload tells whether a token is valid. The claims and the user are only set for the valid tokens
+type ValidateTokenPayload struct {
+	Valid  bool    `json:"valid"`
+	Claims *Claims `json:"claims"`
+	User   *User   `json:"user"`
+}
+
+// LoginInput are the credentials of the login. The organization is the slug of the organization the token is issued for
+type LoginInput struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// CreateUserInput is the data of a new account. The organizations are joined with an invitation afterwards
+type CreateUserInput struct {
+	Email    string   `json:"email"`
+	Password string   `json:"password"`
+	Roles    []string `json:"roles"`
+	Active   *bool    `json:"active,omitempty"`
+}
+
+// New creates a Client for the go-auth server at the base url, like https://auth.example.com
+func New(baseURL string, config Config) *Client {
+	c := &Client{
+		url:           strings.TrimSuffix(baseURL, "/") + "/query",
+		httpClient:    config.HTTPClient,
+		store:         config.Store,
+		refreshBefore: config.RefreshBefore,
+		now:           time.Now,
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	if c.store == nil {
+		c.store = NewMemoryStore(Tokens{})
+	}
+
+	if c.refreshBefore == 0 {
+		c.refreshBefore = DefaultRefreshBefore
+	}
+
+	return c
+}
+
+// Login logs the user in and stores its tokens
+func (c *Client) Login(ctx context.Context, input LoginInput) (*AuthPayload, error) {
+	var resp struct {
+		Login AuthPayload `json:"login"`
+	}
+
+	query := `mutation ($data: LoginUserInput!) { login(data: $data) {` + authPayloadFields + `} }`
+
+	if err := c.post(ctx, query, map[string]interface{}{"data": input}, "", &resp); err != nil {
+		return nil, err
+	}
+
+	if err := c.save(resp.Login); err != nil {
+		return nil, err
+	}
+
+	return &resp.Login, nil
+}
+
+// CreateUser signs a new user up. The client is logged in as the new user afterwards
+func (c *Client) CreateUser(ctx context.Context, input CreateUserInput) (*AuthPayload, error) {
+	var resp struct {
+		CreateUser AuthPayload `json:"createUser"`
+	}
+
+	if input.Roles == nil {
+		input.Roles = []string{}
+	}
+
+	query := `mutation ($data: CreateUserInput!) { createUser(data: $data) {` + authPayloadFields + `} }`
+
+	if err := c.post(ctx, query, map[string]interface{}{"data": input}, "", &resp); err != nil {
+		return nil, err
+	}
+
+	if err := c.save(resp.CreateUser); err != nil {
+		return nil, err
+	}
+
+	return &resp.CreateUser, nil
+}
+
+// Refresh gets a new access token with the stored refresh token. It's called automatically before the access token
+// expires, so it's only needed to refresh on demand
+func (c *Client) Refresh(ctx context.Context) (*AuthPayload, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tokens, err := c.store.Load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tokens.RefreshToken == "" {
+		return nil, ErrNotLoggedIn
+	}
+
+	return c.refresh(ctx, tokens.RefreshToken)
+}
+
+// ValidateToken asks the server if the token is valid
+func (c *Client) ValidateToken(ctx context.Context, token string) (*ValidateTokenPayload, error) {
+	var resp struct {
+		ValidateToken ValidateTokenPayload `json:"validateToken"`
+	}
+
+	query := `mutation ($token: String!) {
+		validateToken(token: $token) {
+			valid
+			claims { iss sub org groups exp iat }
+			user {` + userFields + `}
+		}
+	}`
+
+	if err := c.post(ctx, query, map[string]interface{}{"token": token}, "", &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.ValidateToken, nil
+}
+
+// Me returns the logged in user
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var resp struct {
+		Me *User `json:"me"`
+	}
+
+	if err := c.Do(ctx, `query { me {`+userFields+`} }`, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Me, nil
+}
+
+// Logout forgets the stored tokens. The tokens stay valid on the server until they expire
+func (c *Client) Logout() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.store.Save(Tokens{})
+}
+
+// Do sends any operation of the API, authenticated with the stored access token, and decodes its data into the
+// result. It's meant for the operations without a typed method
+func (c *Client) Do(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	token, err := c.accessToken(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return c.post(ctx, query, variables, token, result)
+}
+
+// accessToken returns the stored access token, refreshing it first when it's about to expire. An empty token is
+// returned when the client isn't logged in, so the server answers with ErrUnauthorized where it's needed
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tokens, err := c.store.Load()
+
+	if err != nil {
+		return "", err
+	}
+
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		return tokens.AccessToken, nil
+	}
+
+	expiration := expiresAt(tokens.AccessToken)
+
+	if expiration.IsZero() || c.now().Add(c.refreshBefore).Before(expiration) {
+		return tokens.AccessToken, nil
+	}
+
+	payload, err := c.refresh(ctx, tokens.RefreshToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	return payload.Token, nil
+}
+
+// refresh exchanges the refresh token and stores the new tokens. The tokens are forgotten when the server refuses
+// the refresh token, since it was revoked or expired. The caller must hold the mutex
+func (c *Client) refresh(ctx context.Context, refreshToken string) (*AuthPayload, error) {
+	var resp struct {
+		RefreshToken AuthPayload `json:"refreshToken"`
+	}
+
+	query := `mutation ($refreshToken: String!) { refreshToken(refreshToken: $refreshToken) {` + authPayloadFields + `} }`
+
+	err := c.post(ctx, query, map[string]interface{}{"refreshToken": refreshToken}, "", &resp)
+
+	if err != nil {
+		if ErrUnauthorized.Is(err) {
+			if err := c.store.Save(Tokens{}); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, err
+	}
+
+	if err := c.store.Save(Tokens{AccessToken: resp.RefreshToken.Token, RefreshToken: resp.RefreshToken.RefreshToken}); err != nil {
+		return nil, err
+	}
+
+	return &resp.RefreshToken, nil
+}
+
+func (c *Client) save(payload AuthPayload) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.store.Save(Tokens{AccessToken: payload.Token, RefreshToken: payload.RefreshToken})
+}
+
+// post sends the operation and decodes its data into the result. The first error of the response is returned as *Error
+func (c *Client) post(ctx context.Context, query string, variables map[string]interface{}, token string, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to create the request body: %v", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to create the request: %v", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if token != "" {
+		request.Header.Set("Authorization", token)
+	}
+
+	response, err := c.httpClient.Do(request.WithContext(ctx))
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to do the request: %v", err)
+	}
+
+	defer response.Body.Close()
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []responseError `json:"errors"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("Error while trying to read the response of status %d: %v", response.StatusCode, err)
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp.Errors[0].toError()
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status %d", response.StatusCode)
+	}
+
+	if result == nil || len(resp.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Data, result)
+}
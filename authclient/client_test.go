@@ -0,0 +1,291 @@
+package authclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/authclient"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// fakeServer answers the operations by the name of their root field, recording the Authorization header of each
+type fakeServer struct {
+	*httptest.Server
+
+	mutex          sync.Mutex
+	authorizations map[string][]string
+	refreshes      int32
+	operations     map[string]func(request graphQLRequest) interface{}
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{
+		authorizations: map[string][]string{},
+		operations:     map[string]func(request graphQLRequest) interface{}{},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/query", r.URL.Path)
+
+		var request graphQLRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		for name, operation := range s.operations {
+			if strings.Contains(request.Query, name+"(") || strings.Contains(request.Query, name+" {") {
+				s.mutex.Lock()
+				s.authorizations[name] = append(s.authorizations[name], r.Header.Get("Authorization"))
+				s.mutex.Unlock()
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(operation(request))
+				return
+			}
+		}
+
+		t.Fatalf("Unexpected query %s", request.Query)
+	}))
+
+	return s
+}
+
+func generateToken(t *testing.T, subject string, expiresIn time.Duration) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		Subject:   subject,
+		ExpiresAt: time.Now().Add(expiresIn).Unix(),
+	}).SignedString([]byte("secret"))
+
+	require.NoError(t, err)
+
+	return token
+}
+
+func authPayload(token, refreshToken string) map[string]interface{} {
+	return map[string]interface{}{
+		"user": map[string]interface{}{
+			"id":    "5d470b3e98b0116d7d8ca48c",
+			"email": "test1@test.com",
+			"roles": []string{"user"},
+		},
+		"token":        token,
+		"refreshToken": refreshToken,
+	}
+}
+
+func errorResponse(message, code string, extensions map[string]interface{}) map[string]interface{} {
+	if extensions == nil {
+		extensions = map[string]interface{}{}
+	}
+
+	if code != "" {
+		extensions["code"] = code
+	}
+
+	return map[string]interface{}{
+		"errors": []interface{}{
+			map[string]interface{}{"message": message, "path": []string{"login"}, "extensions": extensions},
+		},
+		"data": nil,
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should store the tokens of the login and authenticate the next requests", func(t *testing.T) {
+		srv := newFakeServer(t)
+		defer srv.Close()
+
+		token := generateToken(t, "5d470b3e98b0116d7d8ca48c", time.Hour)
+
+		srv.operations["login"] = func(request graphQLRequest) interface{} {
+			data := request.Variables["data"].(map[string]interface{})
+			require.Equal(t, "test1@test.com", data["email"])
+			require.Equal(t, "12345", data["password"])
+			require.NotContains(t, data, "organization")
+
+			return map[string]interface{}{"data": map[string]interface{}{"login": authPayload(token, "refresh")}}
+		}
+
+		srv.operations["me"] = func(request graphQLRequest) interface{} {
+			return map[string]interface{}{"data": map[string]interface{}{"me": authPayload(token, "")["user"]}}
+		}
+
+		store := authclient.NewMemoryStore(authclient.Tokens{})
+		c := authclient.New(srv.URL+"/", authclient.Config{Store: store})
+
+		payload, err := c.Login(ctx, authclient.LoginInput{Email: "test1@test.com", Password: "12345"})
+
+		require.NoError(t, err)
+		require.Equal(t, "test1@test.com", payload.User.Email)
+		require.Equal(t, []string{"user"}, payload.User.Roles)
+
+		tokens, err := store.Load()
+
+		require.NoError(t, err)
+		require.Equal(t, authclient.Tokens{AccessToken: token, RefreshToken: "refresh"}, tokens)
+
+		user, err := c.Me(ctx)
+
+		require.NoError(t, err)
+		require.Equal(t, "5d470b3e98b0116d7d8ca48c", user.ID)
+		require.Equal(t, []string{""}, srv.authorizations["login"])
+		require.Equal(t, []string{token}, srv.authorizations["me"])
+	})
+
+	t.Run("Should refresh the access token before it expires", func(t *testing.T) {
+		srv := newFakeServer(t)
+		defer srv.Close()
+
+		expiring := generateToken(t, "5d470b3e98b0116d7d8ca48c", 30*time.Second)
+		refreshed := generateToken(t, "5d470b3e98b0116d7d8ca48c", 15*time.Minute)
+
+		srv.operations["refreshToken"] = func(request graphQLRequest) interface{} {
+			atomic.AddInt32(&srv.refreshes, 1)
+			require.Equal(t, "refresh", request.Variables["refreshToken"])
+
+			return map[string]interface{}{"data": map[string]interface{}{"refreshToken": authPayload(refreshed, "refresh")}}
+		}
+
+		srv.operations["me"] = func(request graphQLRequest) interface{} {
+			return map[string]interface{}{"data": map[string]interface{}{"me": authPayload("", "")["user"]}}
+		}
+
+		store := authclient.NewMemoryStore(authclient.Tokens{AccessToken: expiring, RefreshToken: "refresh"})
+		c := authclient.New(srv.URL, authclient.Config{Store: store})
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := c.Me(ctx)
+				require.NoError(t, err)
+			}()
+		}
+
+		wg.Wait()
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&srv.refreshes))
+		require.Equal(t, []string{refreshed, refreshed, refreshed, refreshed, refreshed}, srv.authorizations["me"])
+
+		tokens, err := store.Load()
+
+		require.NoError(t, err)
+		require.Equal(t, refreshed, tokens.AccessToken)
+	})
+
+	t.Run("Should not refresh the access token far from its expiration", func(t *testing.T) {
+		srv := newFakeServer(t)
+		defer srv.Close()
+
+		token := generateToken(t, "5d470b3e98b0116d7d8ca48c", 10*time.Minute)
+
+		srv.operations["me"] = func(request graphQLRequest) interface{} {
+			return map[string]interface{}{"data": map[string]interface{}{"me": nil}}
+		}
+
+		c := authclient.New(srv.URL, authclient.Config{
+			Store:         authclient.NewMemoryStore(authclient.Tokens{AccessToken: token, RefreshToken: "refresh"}),
+			RefreshBefore: 5 * time.Minute,
+		})
+
+		user, err := c.Me(ctx)
+
+		require.NoError(t, err)
+		require.Nil(t, user)
+		require.Equal(t, []string{token}, srv.authorizations["me"])
+	})
+
+	t.Run("Should forget the tokens when the refresh token is refused", func(t *testing.T) {
+		srv := newFakeServer(t)
+		defer srv.Close()
+
+		srv.operations["refreshToken"] = func(request graphQLRequest) interface{} {
+			return errorResponse("Unauthorized", "UNAUTHORIZED", nil)
+		}
+
+		store := authclient.NewMemoryStore(authclient.Tokens{
+			AccessToken:  generateToken(t, "5d470b3e98b0116d7d8ca48c", -time.Minute),
+			RefreshToken: "revoked",
+		})
+
+		c := authclient.New(srv.URL, authclient.Config{Store: store})
+
+		_, err := c.Me(ctx)
+
+		require.Error(t, err)
+		require.True(t, err.(*authclient.Error).Is(authclient.ErrUnauthorized))
+
+		tokens, err := store.Load()
+
+		require.NoError(t, err)
+		require.Equal(t, authclient.Tokens{}, tokens)
+
+		_, err = c.Refresh(ctx)
+
+		require.Equal(t, authclient.ErrNotLoggedIn, err)
+	})
+
+	t.Run("Should map the codes of the errors to the error values", func(t *testing.T) {
+		srv := newFakeServer(t)
+		defer srv.Close()
+
+		srv.operations["login"] = func(request graphQLRequest) interface{} {
+			return errorResponse("Too many failed login attempts, try again later", "ACCOUNT_LOCKED", map[string]interface{}{"retryAfter": 30})
+		}
+
+		srv.operations["createUser"] = func(request graphQLRequest) interface{} {
+			data := request.Variables["data"].(map[string]interface{})
+			require.Equal(t, []interface{}{}, data["roles"])
+
+			return errorResponse("The password doesn't follow the password policy", "BAD_USER_INPUT", map[string]interface{}{
+				"rules": []map[string]interface{}{{"rule": "min_length"}},
+			})
+		}
+
+		srv.operations["validateToken"] = func(request graphQLRequest) interface{} {
+			return errorResponse("Unexpected failure", "", nil)
+		}
+
+		c := authclient.New(srv.URL, authclient.Config{})
+
+		_, err := c.Login(ctx, authclient.LoginInput{Email: "test1@test.com", Password: "1234"})
+
+		locked := err.(*authclient.Error)
+		require.True(t, locked.Is(authclient.ErrAccountLocked))
+		require.False(t, locked.Is(authclient.ErrUnauthorized))
+		require.Equal(t, 30*time.Second, locked.RetryAfter())
+		require.Equal(t, []interface{}{"login"}, locked.Path)
+
+		_, err = c.CreateUser(ctx, authclient.CreateUserInput{Email: "weak@test.com", Password: "weak"})
+
+		var rules []struct {
+			Rule string `json:"rule"`
+		}
+
+		badInput := err.(*authclient.Error)
+		require.True(t, badInput.Is(authclient.ErrBadUserInput))
+		require.NoError(t, badInput.Extension("rules", &rules))
+		require.Equal(t, "min_length", rules[0].Rule)
+
+		_, err = c.ValidateToken(ctx, "token")
+
+		require.True(t, err.(*authclient.Error).Is(authclient.ErrInternal))
+	})
+}
@@ -0,0 +1,111 @@
+package authclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+)
+
+var (
+	// ErrInternal is matched by the errors of the server that have no other code
+	ErrInternal = &Error{Code: gqlerrors.Internal, Message: "Internal server error"}
+
+	// ErrUnauthorized is matched when the credentials or the token aren't valid
+	ErrUnauthorized = &Error{Code: gqlerrors.Unauthorized, Message: "Unauthorized"}
+
+	// ErrForbidden is matched when the user isn't allowed to do the operation
+	ErrForbidden = &Error{Code: gqlerrors.Forbidden, Message: "Forbidden"}
+
+	// ErrNotFound is matched when the requested resource doesn't exist
+	ErrNotFound = &Error{Code: gqlerrors.NotFound, Message: "Not found"}
+
+	// ErrConflict is matched when the resource already exists, like an email already in use
+	ErrConflict = &Error{Code: gqlerrors.Conflict, Message: "Conflict"}
+
+	// ErrBadUserInput is matched when the input is invalid, like a password that doesn't follow the policy
+	ErrBadUserInput = &Error{Code: gqlerrors.BadUserInput, Message: "Bad user input"}
+
+	// ErrAccountLocked is matched when the login is refused after too many failed attempts
+	ErrAccountLocked = &Error{Code: gqlerrors.AccountLocked, Message: "Account locked"}
+
+	// ErrRateLimited is matched when the client exceeded the rate limit
+	ErrRateLimited = &Error{Code: gqlerrors.RateLimited, Message: "Rate limited"}
+
+	// ErrPasswordExpired is matched when the password must be changed before logging in
+	ErrPasswordExpired = &Error{Code: gqlerrors.PasswordExpired, Message: "Password expired"}
+)
+
+// Error is an error returned by go-auth. Its code is one of the gqlerrors codes, so it can be compared with the
+// Err values of this package
+type Error struct {
+	Code       string
+	Message    string
+	Path       []interface{}
+	Extensions map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is reports whether the target is an Error with the same code, which makes the errors comparable with errors.Is
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Code == e.Code
+}
+
+// RetryAfter returns the time until a new attempt is allowed, on ErrAccountLocked and ErrRateLimited
+func (e *Error) RetryAfter() time.Duration {
+	seconds, _ := e.Extensions["retryAfter"].(float64)
+
+	return time.Duration(seconds) * time.Second
+}
+
+// Token returns the token that can only be used to change the password, on ErrPasswordExpired
+func (e *Error) Token() string {
+	token, _ := e.Extensions["token"].(string)
+
+	return token
+}
+
+// Extension decodes the extension with the key into the result, like the rules broken by a weak password
+func (e *Error) Extension(key string, result interface{}) error {
+	value, ok := e.Extensions[key]
+
+	if !ok {
+		return fmt.Errorf("The error has no %s extension", key)
+	}
+
+	encoded, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, result)
+}
+
+// responseError is an error as sent by the GraphQL API
+type responseError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+func (r responseError) toError() *Error {
+	code, _ := r.Extensions["code"].(string)
+
+	if code == "" {
+		code = gqlerrors.Internal
+	}
+
+	return &Error{
+		Code:       code,
+		Message:    r.Message,
+		Path:       r.Path,
+		Extensions: r.Extensions,
+	}
+}
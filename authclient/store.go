@@ -0,0 +1,77 @@
+package authclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tokens are the tokens of the logged in user
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// TokenStore keeps the tokens between the requests. It's pluggable, so the applications can persist them on a file,
+// a keychain or a cookie instead of the memory. The Client serializes its calls to the store
+type TokenStore interface {
+	Load() (Tokens, error)
+	Save(tokens Tokens) error
+}
+
+// MemoryStore keeps the tokens in memory. It's the default store of the Client
+type MemoryStore struct {
+	mutex  sync.Mutex
+	tokens Tokens
+}
+
+// NewMemoryStore creates a MemoryStore holding the tokens, which may be empty
+func NewMemoryStore(tokens Tokens) *MemoryStore {
+	return &MemoryStore{tokens: tokens}
+}
+
+// Load returns the stored tokens
+func (s *MemoryStore) Load() (Tokens, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.tokens, nil
+}
+
+// Save replaces the stored tokens
+func (s *MemoryStore) Save(tokens Tokens) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tokens = tokens
+
+	return nil
+}
+
+// expiresAt reads the exp claim of the token without verifying it. The signature is checked by the server, the client
+// only needs to know when to refresh. The zero time is returned when the token can't be read
+func expiresAt(token string) time.Time {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.ExpiresAt, 0)
+}
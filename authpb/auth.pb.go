@@ -0,0 +1,709 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: authpb/auth.proto
+
+package authpb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type ValidateTokenRequest struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValidateTokenRequest) Reset()         { *m = ValidateTokenRequest{} }
+func (m *ValidateTokenRequest) String() string { return proto.CompactTextString(m) }
+func (*ValidateTokenRequest) ProtoMessage()    {}
+func (*ValidateTokenRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{0}
+}
+
+func (m *ValidateTokenRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateTokenRequest.Unmarshal(m, b)
+}
+func (m *ValidateTokenRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateTokenRequest.Marshal(b, m, deterministic)
+}
+func (m *ValidateTokenRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateTokenRequest.Merge(m, src)
+}
+func (m *ValidateTokenRequest) XXX_Size() int {
+	return xxx_messageInfo_ValidateTokenRequest.Size(m)
+}
+func (m *ValidateTokenRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateTokenRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateTokenRequest proto.InternalMessageInfo
+
+func (m *ValidateTokenRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+type ValidateTokenResponse struct {
+	Valid                bool     `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Claims               *Claims  `protobuf:"bytes,2,opt,name=claims,proto3" json:"claims,omitempty"`
+	User                 *User    `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ValidateTokenResponse) Reset()         { *m = ValidateTokenResponse{} }
+func (m *ValidateTokenResponse) String() string { return proto.CompactTextString(m) }
+func (*ValidateTokenResponse) ProtoMessage()    {}
+func (*ValidateTokenResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{1}
+}
+
+func (m *ValidateTokenResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateTokenResponse.Unmarshal(m, b)
+}
+func (m *ValidateTokenResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateTokenResponse.Marshal(b, m, deterministic)
+}
+func (m *ValidateTokenResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateTokenResponse.Merge(m, src)
+}
+func (m *ValidateTokenResponse) XXX_Size() int {
+	return xxx_messageInfo_ValidateTokenResponse.Size(m)
+}
+func (m *ValidateTokenResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateTokenResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateTokenResponse proto.InternalMessageInfo
+
+func (m *ValidateTokenResponse) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+func (m *ValidateTokenResponse) GetClaims() *Claims {
+	if m != nil {
+		return m.Claims
+	}
+	return nil
+}
+
+func (m *ValidateTokenResponse) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+type Claims struct {
+	Subject      string   `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Issuer       string   `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Organization string   `protobuf:"bytes,3,opt,name=organization,proto3" json:"organization,omitempty"`
+	Groups       []string `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"`
+	// The times are unix timestamps, in seconds
+	IssuedAt             int64    `protobuf:"varint,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Claims) Reset()         { *m = Claims{} }
+func (m *Claims) String() string { return proto.CompactTextString(m) }
+func (*Claims) ProtoMessage()    {}
+func (*Claims) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{2}
+}
+
+func (m *Claims) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Claims.Unmarshal(m, b)
+}
+func (m *Claims) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Claims.Marshal(b, m, deterministic)
+}
+func (m *Claims) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Claims.Merge(m, src)
+}
+func (m *Claims) XXX_Size() int {
+	return xxx_messageInfo_Claims.Size(m)
+}
+func (m *Claims) XXX_DiscardUnknown() {
+	xxx_messageInfo_Claims.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Claims proto.InternalMessageInfo
+
+func (m *Claims) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Claims) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *Claims) GetOrganization() string {
+	if m != nil {
+		return m.Organization
+	}
+	return ""
+}
+
+func (m *Claims) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *Claims) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *Claims) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+type Membership struct {
+	OrganizationId       string   `protobuf:"bytes,1,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	Role                 string   `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Membership) Reset()         { *m = Membership{} }
+func (m *Membership) String() string { return proto.CompactTextString(m) }
+func (*Membership) ProtoMessage()    {}
+func (*Membership) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{3}
+}
+
+func (m *Membership) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Membership.Unmarshal(m, b)
+}
+func (m *Membership) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Membership.Marshal(b, m, deterministic)
+}
+func (m *Membership) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Membership.Merge(m, src)
+}
+func (m *Membership) XXX_Size() int {
+	return xxx_messageInfo_Membership.Size(m)
+}
+func (m *Membership) XXX_DiscardUnknown() {
+	xxx_messageInfo_Membership.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Membership proto.InternalMessageInfo
+
+func (m *Membership) GetOrganizationId() string {
+	if m != nil {
+		return m.OrganizationId
+	}
+	return ""
+}
+
+func (m *Membership) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+type User struct {
+	Id          string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email       string        `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Roles       []string      `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Active      bool          `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	Memberships []*Membership `protobuf:"bytes,5,rep,name=memberships,proto3" json:"memberships,omitempty"`
+	// The times are unix timestamps, in seconds
+	CreatedAt            int64    `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            int64    `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *User) Reset()         { *m = User{} }
+func (m *User) String() string { return proto.CompactTextString(m) }
+func (*User) ProtoMessage()    {}
+func (*User) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{4}
+}
+
+func (m *User) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_User.Unmarshal(m, b)
+}
+func (m *User) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_User.Marshal(b, m, deterministic)
+}
+func (m *User) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_User.Merge(m, src)
+}
+func (m *User) XXX_Size() int {
+	return xxx_messageInfo_User.Size(m)
+}
+func (m *User) XXX_DiscardUnknown() {
+	xxx_messageInfo_User.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_User proto.InternalMessageInfo
+
+func (m *User) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *User) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *User) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *User) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *User) GetMemberships() []*Membership {
+	if m != nil {
+		return m.Memberships
+	}
+	return nil
+}
+
+func (m *User) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *User) GetUpdatedAt() int64 {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return 0
+}
+
+type GetUserRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetUserRequest) Reset()         { *m = GetUserRequest{} }
+func (m *GetUserRequest) String() string { return proto.CompactTextString(m) }
+func (*GetUserRequest) ProtoMessage()    {}
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{5}
+}
+
+func (m *GetUserRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUserRequest.Unmarshal(m, b)
+}
+func (m *GetUserRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUserRequest.Marshal(b, m, deterministic)
+}
+func (m *GetUserRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUserRequest.Merge(m, src)
+}
+func (m *GetUserRequest) XXX_Size() int {
+	return xxx_messageInfo_GetUserRequest.Size(m)
+}
+func (m *GetUserRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUserRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUserRequest proto.InternalMessageInfo
+
+func (m *GetUserRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type CheckPermissionRequest struct {
+	Token                string            `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Action               string            `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Resource             string            `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	Context              map[string]string `protobuf:"bytes,4,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *CheckPermissionRequest) Reset()         { *m = CheckPermissionRequest{} }
+func (m *CheckPermissionRequest) String() string { return proto.CompactTextString(m) }
+func (*CheckPermissionRequest) ProtoMessage()    {}
+func (*CheckPermissionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{6}
+}
+
+func (m *CheckPermissionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckPermissionRequest.Unmarshal(m, b)
+}
+func (m *CheckPermissionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckPermissionRequest.Marshal(b, m, deterministic)
+}
+func (m *CheckPermissionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckPermissionRequest.Merge(m, src)
+}
+func (m *CheckPermissionRequest) XXX_Size() int {
+	return xxx_messageInfo_CheckPermissionRequest.Size(m)
+}
+func (m *CheckPermissionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckPermissionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckPermissionRequest proto.InternalMessageInfo
+
+func (m *CheckPermissionRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetResource() string {
+	if m != nil {
+		return m.Resource
+	}
+	return ""
+}
+
+func (m *CheckPermissionRequest) GetContext() map[string]string {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+type CheckPermissionResponse struct {
+	Allowed              bool     `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	Rule                 string   `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	Reason               string   `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CheckPermissionResponse) Reset()         { *m = CheckPermissionResponse{} }
+func (m *CheckPermissionResponse) String() string { return proto.CompactTextString(m) }
+func (*CheckPermissionResponse) ProtoMessage()    {}
+func (*CheckPermissionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e98ce6d708f87792, []int{7}
+}
+
+func (m *CheckPermissionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckPermissionResponse.Unmarshal(m, b)
+}
+func (m *CheckPermissionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckPermissionResponse.Marshal(b, m, deterministic)
+}
+func (m *CheckPermissionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckPermissionResponse.Merge(m, src)
+}
+func (m *CheckPermissionResponse) XXX_Size() int {
+	return xxx_messageInfo_CheckPermissionResponse.Size(m)
+}
+func (m *CheckPermissionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckPermissionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckPermissionResponse proto.InternalMessageInfo
+
+func (m *CheckPermissionResponse) GetAllowed() bool {
+	if m != nil {
+		return m.Allowed
+	}
+	return false
+}
+
+func (m *CheckPermissionResponse) GetRule() string {
+	if m != nil {
+		return m.Rule
+	}
+	return ""
+}
+
+func (m *CheckPermissionResponse) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ValidateTokenRequest)(nil), "goauth.v1.ValidateTokenRequest")
+	proto.RegisterType((*ValidateTokenResponse)(nil), "goauth.v1.ValidateTokenResponse")
+	proto.RegisterType((*Claims)(nil), "goauth.v1.Claims")
+	proto.RegisterType((*Membership)(nil), "goauth.v1.Membership")
+	proto.RegisterType((*User)(nil), "goauth.v1.User")
+	proto.RegisterType((*GetUserRequest)(nil), "goauth.v1.GetUserRequest")
+	proto.RegisterType((*CheckPermissionRequest)(nil), "goauth.v1.CheckPermissionRequest")
+	proto.RegisterMapType((map[string]string)(nil), "goauth.v1.CheckPermissionRequest.ContextEntry")
+	proto.RegisterType((*CheckPermissionResponse)(nil), "goauth.v1.CheckPermissionResponse")
+}
+
+func init() { proto.RegisterFile("authpb/auth.proto", fileDescriptor_e98ce6d708f87792) }
+
+var fileDescriptor_e98ce6d708f87792 = []byte{
+	// 637 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xdb, 0x6e, 0xd3, 0x4c,
+	0x10, 0x96, 0x93, 0x34, 0x69, 0x26, 0xfd, 0xdb, 0xbf, 0xab, 0xb6, 0xbf, 0xff, 0x20, 0x44, 0x30,
+	0x17, 0x04, 0x89, 0x26, 0x22, 0x08, 0x15, 0xca, 0x55, 0xa8, 0x38, 0x54, 0x02, 0x09, 0x59, 0x80,
+	0x10, 0x37, 0xd5, 0xc6, 0x19, 0x25, 0x4b, 0x6d, 0xaf, 0xd9, 0x43, 0x69, 0x2b, 0xf1, 0x52, 0xbc,
+	0x09, 0x6f, 0x81, 0xc4, 0x4b, 0xa0, 0x3d, 0x38, 0xb8, 0xa1, 0x94, 0xab, 0xf8, 0x9b, 0xf9, 0x26,
+	0x33, 0xdf, 0x1c, 0x16, 0x36, 0xa9, 0x56, 0xf3, 0x62, 0x32, 0x34, 0x3f, 0x83, 0x42, 0x70, 0xc5,
+	0x49, 0x7b, 0xc6, 0x2d, 0x3a, 0xb9, 0x17, 0xdd, 0x85, 0xad, 0x77, 0x34, 0x65, 0x53, 0xaa, 0xf0,
+	0x0d, 0x3f, 0xc6, 0x3c, 0xc6, 0x4f, 0x1a, 0xa5, 0x22, 0x5b, 0xb0, 0xa2, 0x0c, 0x0e, 0x83, 0x5e,
+	0xd0, 0x6f, 0xc7, 0x0e, 0x44, 0x5f, 0x60, 0x7b, 0x89, 0x2d, 0x0b, 0x9e, 0x4b, 0x34, 0xf4, 0x13,
+	0xe3, 0xb0, 0xf4, 0xd5, 0xd8, 0x01, 0x72, 0x07, 0x9a, 0x49, 0x4a, 0x59, 0x26, 0xc3, 0x5a, 0x2f,
+	0xe8, 0x77, 0x46, 0x9b, 0x83, 0x45, 0xe2, 0xc1, 0x81, 0x75, 0xc4, 0x9e, 0x40, 0x6e, 0x41, 0x43,
+	0x4b, 0x14, 0x61, 0xdd, 0x12, 0x37, 0x2a, 0xc4, 0xb7, 0x12, 0x45, 0x6c, 0x9d, 0xd1, 0xd7, 0x00,
+	0x9a, 0x2e, 0x8e, 0x84, 0xd0, 0x92, 0x7a, 0xf2, 0x11, 0x13, 0xe5, 0x2b, 0x2c, 0x21, 0xd9, 0x81,
+	0x26, 0x93, 0x52, 0xa3, 0xb0, 0x49, 0xdb, 0xb1, 0x47, 0x24, 0x82, 0x35, 0x2e, 0x66, 0x34, 0x67,
+	0xe7, 0x54, 0x31, 0x9e, 0xdb, 0x4c, 0xed, 0xf8, 0x82, 0xcd, 0xc4, 0xce, 0x04, 0xd7, 0x85, 0x0c,
+	0x1b, 0xbd, 0xba, 0x89, 0x75, 0x88, 0x5c, 0x83, 0xb6, 0xfd, 0x97, 0xe9, 0x11, 0x55, 0xe1, 0x4a,
+	0x2f, 0xe8, 0xd7, 0xe3, 0x55, 0x67, 0x18, 0x2b, 0x72, 0x1d, 0x00, 0x4f, 0x0b, 0x26, 0x50, 0x1a,
+	0x6f, 0xd3, 0x7a, 0xdb, 0xde, 0x32, 0x56, 0xd1, 0x21, 0xc0, 0x2b, 0xcc, 0x26, 0x28, 0xe4, 0x9c,
+	0x15, 0xe4, 0x36, 0x6c, 0x54, 0x33, 0x1e, 0xf9, 0x96, 0xb5, 0xe3, 0xf5, 0xaa, 0xf9, 0x70, 0x4a,
+	0x08, 0x34, 0x04, 0x4f, 0xd1, 0x8b, 0xb0, 0xdf, 0xd1, 0xb7, 0x00, 0x1a, 0xa6, 0x1d, 0x64, 0x1d,
+	0x6a, 0x8b, 0xc0, 0x1a, 0x9b, 0x9a, 0xf6, 0x63, 0x46, 0x59, 0xea, 0xd9, 0x0e, 0x18, 0xab, 0x09,
+	0x93, 0x61, 0xdd, 0x8a, 0x71, 0xc0, 0x68, 0xa4, 0x89, 0x62, 0x27, 0x18, 0x36, 0xec, 0xac, 0x3c,
+	0x22, 0x7b, 0xd0, 0xc9, 0x16, 0x75, 0xca, 0x70, 0xa5, 0x57, 0xef, 0x77, 0x46, 0xdb, 0x95, 0x41,
+	0xfc, 0x52, 0x11, 0x57, 0x99, 0x46, 0x7f, 0x22, 0x90, 0x2a, 0x9c, 0x56, 0xf4, 0x7b, 0x8b, 0x6b,
+	0x8f, 0x2e, 0xa6, 0xa5, 0xbb, 0xe5, 0xdc, 0xde, 0x32, 0x56, 0x51, 0x0f, 0xd6, 0x9f, 0xa3, 0xb2,
+	0x43, 0xf6, 0xab, 0xb7, 0x24, 0x2e, 0xfa, 0x1e, 0xc0, 0xce, 0xc1, 0x1c, 0x93, 0xe3, 0xd7, 0x28,
+	0x32, 0x26, 0x25, 0xe3, 0x57, 0x6f, 0x69, 0xa9, 0x90, 0xe7, 0xe5, 0x06, 0x38, 0x44, 0xba, 0xb0,
+	0x2a, 0x50, 0x72, 0x2d, 0x12, 0xf4, 0xd3, 0x5f, 0x60, 0xf2, 0x02, 0x5a, 0x09, 0xcf, 0x15, 0x9e,
+	0x2a, 0x3b, 0xfa, 0xce, 0x68, 0x50, 0xdd, 0xd5, 0x4b, 0xb3, 0x0f, 0x0e, 0x5c, 0xc0, 0xd3, 0x5c,
+	0x89, 0xb3, 0xb8, 0x0c, 0xef, 0xee, 0xc3, 0x5a, 0xd5, 0x41, 0xfe, 0x85, 0xfa, 0x31, 0x9e, 0xf9,
+	0x0a, 0xcd, 0xa7, 0x3f, 0x16, 0x5d, 0xce, 0xd6, 0x81, 0xfd, 0xda, 0xc3, 0x20, 0x3a, 0x82, 0xff,
+	0x7e, 0xcb, 0xe5, 0x2f, 0x2c, 0x84, 0x16, 0x4d, 0x53, 0xfe, 0x19, 0xcb, 0x1b, 0x2b, 0xa1, 0xdd,
+	0x14, 0x5d, 0xd9, 0x14, 0x9d, 0xa2, 0x69, 0x81, 0x40, 0x2a, 0x17, 0x6b, 0xee, 0xd1, 0xe8, 0x47,
+	0x00, 0x8d, 0xb1, 0x56, 0x73, 0x12, 0xc3, 0x3f, 0x17, 0x2e, 0x99, 0xdc, 0xa8, 0xe8, 0xbd, 0xec,
+	0x45, 0xe8, 0xf6, 0xfe, 0x4c, 0xf0, 0x25, 0x3e, 0x80, 0x96, 0x1f, 0x25, 0xf9, 0xbf, 0x42, 0xbe,
+	0x38, 0xde, 0xee, 0xf2, 0x6d, 0x93, 0xf7, 0xb0, 0xb1, 0x24, 0x9a, 0xdc, 0xfc, 0x6b, 0xf3, 0xbb,
+	0xd1, 0x55, 0x14, 0x57, 0xd0, 0x93, 0x47, 0x1f, 0xf6, 0x66, 0x4c, 0xcd, 0xf5, 0x64, 0x90, 0xf0,
+	0x6c, 0xf8, 0x52, 0x27, 0x54, 0x3e, 0x13, 0x78, 0x4e, 0x05, 0xcb, 0xd9, 0x70, 0xc6, 0x77, 0x4d,
+	0xfc, 0x6e, 0x46, 0x73, 0x3a, 0x43, 0x31, 0x74, 0xcf, 0xe4, 0x63, 0xf7, 0x33, 0x69, 0xda, 0x97,
+	0xf2, 0xfe, 0xcf, 0x01, 0x00, 0xcc, 0x50, 0xeb, 0x85, 0x3e, 0x05, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AuthClient is the client API for Auth service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AuthClient interface {
+	// ValidateToken checks the signature, the issuer and the user of the token. An invalid token isn't an error,
+	// it's answered with valid = false
+	ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error)
+	// GetUser returns an user. The caller can get itself, and the admins can get the members of their organization
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// CheckPermission evaluates the access policy for the user of the token
+	CheckPermission(ctx context.Context, in *CheckPermissionRequest, opts ...grpc.CallOption) (*CheckPermissionResponse, error)
+}
+
+type authClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAuthClient(cc *grpc.ClientConn) AuthClient {
+	return &authClient{cc}
+}
+
+func (c *authClient) ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error) {
+	out := new(ValidateTokenResponse)
+	err := c.cc.Invoke(ctx, "/goauth.v1.Auth/ValidateToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, "/goauth.v1.Auth/GetUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) CheckPermission(ctx context.Context, in *CheckPermissionRequest, opts ...grpc.CallOption) (*CheckPermissionResponse, error) {
+	out := new(CheckPermissionResponse)
+	err := c.cc.Invoke(ctx, "/goauth.v1.Auth/CheckPermission", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthServer is the server API for Auth service.
+type AuthServer interface {
+	// ValidateToken checks the signature, the issuer and the user of the token. An invalid token isn't an error,
+	// it's answered with valid = false
+	ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error)
+	// GetUser returns an user. The caller can get itself, and the admins can get the members of their organization
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// CheckPermission evaluates the access policy for the user of the token
+	CheckPermission(context.Context, *CheckPermissionRequest) (*CheckPermissionResponse, error)
+}
+
+// UnimplementedAuthServer can be embedded to have forward compatible implementations.
+type UnimplementedAuthServer struct {
+}
+
+func (*UnimplementedAuthServer) ValidateToken(ctx context.Context, req *ValidateTokenRequest) (*ValidateTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateToken not implemented")
+}
+func (*UnimplementedAuthServer) GetUser(ctx context.Context, req *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (*UnimplementedAuthServer) CheckPermission(ctx context.Context, req *CheckPermissionRequest) (*CheckPermissionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPermission not implemented")
+}
+
+func RegisterAuthServer(s *grpc.Server, srv AuthServer) {
+	s.RegisterService(&_Auth_serviceDesc, srv)
+}
+
+func _Auth_ValidateToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ValidateToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goauth.v1.Auth/ValidateToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ValidateToken(ctx, req.(*ValidateTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goauth.v1.Auth/GetUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_CheckPermission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPermissionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).CheckPermission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/goauth.v1.Auth/CheckPermission",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).CheckPermission(ctx, req.(*CheckPermissionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Auth_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "goauth.v1.Auth",
+	HandlerType: (*AuthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateToken",
+			Handler:    _Auth_ValidateToken_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _Auth_GetUser_Handler,
+		},
+		{
+			MethodName: "CheckPermission",
+			Handler:    _Auth_CheckPermission_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "authpb/auth.proto",
+}
@@ -37,5 +37,13 @@ func ValidateCredentials(claims jsonwebtoken.Claims) (*models.User, error) {
 		return nil, fmt.Errorf("Error while validating claims credentials: %v", err)
 	}
 
+	if claims.Organization != "" {
+		organizationID, err := primitive.ObjectIDFromHex(claims.Organization)
+
+		if err != nil || user.MembershipOf(organizationID) == nil {
+			return nil, fmt.Errorf("Error while validating claims credentials: The user isn't a member of the organization <%v>", claims.Organization)
+		}
+	}
+
 	return user, nil
 }
@@ -1,26 +1,61 @@
 package crypt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword encrypt the password using bcrypt with the default salt value, returning a string representating the hashed password
+// HashPassword encrypt the password using the DefaultHasher, returning a string representating the hashed password
 func HashPassword(plainPassword string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(plainPassword), bcrypt.DefaultCost)
+	hash, err := DefaultHasher.Hash(plainPassword)
 	if err != nil {
 		log.Print(err)
-		return "", fmt.Errorf("Error while trying to encrypt the password: %v", err)
+		return "", err
 	}
 
-	return string(hash), nil
+	return hash, nil
 }
 
-// ComparePassword compares the hashedPasword against the plainText password, returning true if the password matches and false otherwise
+// ComparePassword compares the hashedPasword against the plainText password, returning true if the password matches and false otherwise.
+// The algorithm is detected from the hash, so the passwords hashed by any of the supported algorithms can be compared
 func ComparePassword(hashedPasword, plainPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPasword), []byte(plainPassword))
+	hasher := identify(hashedPasword)
+
+	return hasher != nil && hasher.Compare(hashedPasword, plainPassword)
+}
+
+// SupportedHash returns true if the hash was created by one of the supported algorithms and is decoded by it, so
+// it can be compared
+func SupportedHash(hashedPassword string) bool {
+	hasher := identify(hashedPassword)
+
+	return hasher != nil && hasher.Decodes(hashedPassword)
+}
+
+// NeedsRehash returns true if the hash wasn't created by the DefaultHasher, or was created with weaker parameters
+func NeedsRehash(hashedPassword string) bool {
+	return !DefaultHasher.Identifies(hashedPassword) || DefaultHasher.NeedsRehash(hashedPassword)
+}
+
+// GenerateToken returns a random, url safe, token with 256 bits of entropy
+func GenerateToken() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Error while trying to generate a random token: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex encoded SHA-256 of a random token. Unlike passwords, random tokens have enough
+// entropy to be stored with a fast hash, which also allows them to be looked up by its hash
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
 
-	return err == nil
+	return hex.EncodeToString(sum[:])
 }
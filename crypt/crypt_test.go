@@ -0,0 +1,33 @@
+package crypt_test
+
+import (
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken(t *testing.T) {
+	t.Run("Should generate different tokens on each call", func(t *testing.T) {
+		first, err := crypt.GenerateToken()
+		require.Empty(t, err)
+
+		second, err := crypt.GenerateToken()
+		require.Empty(t, err)
+
+		require.NotEqual(t, first, second)
+		require.Len(t, first, 43)
+	})
+}
+
+func TestHashToken(t *testing.T) {
+	t.Run("Should always return the same hash for the same token", func(t *testing.T) {
+		require.Equal(t, crypt.HashToken("token"), crypt.HashToken("token"))
+		require.NotEqual(t, crypt.HashToken("token"), crypt.HashToken("another token"))
+	})
+
+	t.Run("Should not return the token itself", func(t *testing.T) {
+		require.NotContains(t, crypt.HashToken("token"), "token")
+		require.Len(t, crypt.HashToken("token"), 64)
+	})
+}
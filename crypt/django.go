@@ -0,0 +1,197 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// DjangoPBKDF2Hasher verifies the hashes created by the PBKDF2 hashers of Django, like the ones of imported users:
+// pbkdf2_sha256$<iterations>$<salt>$<key>
+type DjangoPBKDF2Hasher struct {
+	Iterations int
+}
+
+var djangoPBKDF2Algorithms = map[string]struct {
+	hash      func() hash.Hash
+	keyLength int
+}{
+	"pbkdf2_sha256": {sha256.New, sha256.Size},
+	"pbkdf2_sha1":   {sha1.New, sha1.Size},
+}
+
+// Hash returns the password hashed with pbkdf2_sha256 on the Django format
+func (h *DjangoPBKDF2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, 12)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("Error while trying to generate the salt: %v", err)
+	}
+
+	encodedSalt := base64.RawURLEncoding.EncodeToString(salt)
+	key := pbkdf2.Key([]byte(password), []byte(encodedSalt), h.Iterations, sha256.Size, sha256.New)
+
+	return fmt.Sprintf("pbkdf2_sha256$%d$%s$%s", h.Iterations, encodedSalt, base64.StdEncoding.EncodeToString(key)), nil
+}
+
+// djangoPBKDF2MaxIterations is the most iterations a hash can use to be verified, twice the iterations of the
+// recent Django releases, so an imported hash can't make each login attempt take minutes
+const djangoPBKDF2MaxIterations = 2000000
+
+type djangoPBKDF2Hash struct {
+	hash       func() hash.Hash
+	keyLength  int
+	iterations int
+	salt       string
+	key        []byte
+}
+
+// Compare returns true if the password matches the Django PBKDF2 hash
+func (h *DjangoPBKDF2Hasher) Compare(encoded, password string) bool {
+	decoded, err := decodeDjangoPBKDF2(encoded)
+
+	if err != nil {
+		return false
+	}
+
+	key := pbkdf2.Key([]byte(password), []byte(decoded.salt), decoded.iterations, decoded.keyLength, decoded.hash)
+
+	return subtle.ConstantTimeCompare(key, decoded.key) == 1
+}
+
+// Decodes returns true if the hash is a well formed Django PBKDF2 hash
+func (h *DjangoPBKDF2Hasher) Decodes(encoded string) bool {
+	_, err := decodeDjangoPBKDF2(encoded)
+
+	return err == nil
+}
+
+// Identifies returns true if the hash was created by one of the PBKDF2 hashers of Django
+func (h *DjangoPBKDF2Hasher) Identifies(encoded string) bool {
+	algorithm := strings.SplitN(encoded, "$", 2)[0]
+	_, ok := djangoPBKDF2Algorithms[algorithm]
+
+	return ok
+}
+
+// NeedsRehash returns true if the hash isn't pbkdf2_sha256 or uses less iterations than the hasher
+func (h *DjangoPBKDF2Hasher) NeedsRehash(encoded string) bool {
+	parts := strings.Split(encoded, "$")
+
+	if len(parts) != 4 || parts[0] != "pbkdf2_sha256" {
+		return true
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+
+	return err != nil || iterations < h.Iterations
+}
+
+func decodeDjangoPBKDF2(encoded string) (*djangoPBKDF2Hash, error) {
+	parts := strings.Split(encoded, "$")
+
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("Invalid Django PBKDF2 hash")
+	}
+
+	algorithm, ok := djangoPBKDF2Algorithms[parts[0]]
+
+	if !ok {
+		return nil, fmt.Errorf("Unsupported Django PBKDF2 algorithm")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+
+	if err != nil || iterations <= 0 || iterations > djangoPBKDF2MaxIterations {
+		return nil, fmt.Errorf("Invalid Django PBKDF2 iterations")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(parts[3])
+
+	if err != nil || len(key) != algorithm.keyLength {
+		return nil, fmt.Errorf("Invalid Django PBKDF2 key")
+	}
+
+	return &djangoPBKDF2Hash{
+		hash:       algorithm.hash,
+		keyLength:  algorithm.keyLength,
+		iterations: iterations,
+		salt:       parts[2],
+		key:        key,
+	}, nil
+}
+
+// DjangoBcryptHasher verifies the hashes created by the bcrypt hashers of Django: bcrypt$<bcrypt hash>, and
+// bcrypt_sha256$<bcrypt hash>, which hashes the hex encoded SHA-256 of the password to avoid the 72 bytes limit
+type DjangoBcryptHasher struct {
+	Cost int
+}
+
+// Hash returns the password hashed with bcrypt_sha256 on the Django format
+func (h *DjangoBcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(sha256Hex(password)), h.Cost)
+
+	if err != nil {
+		return "", fmt.Errorf("Error while trying to encrypt the password: %v", err)
+	}
+
+	return "bcrypt_sha256$" + string(hash), nil
+}
+
+// Compare returns true if the password matches the Django bcrypt hash, as long as its cost isn't above bcryptMaxCost
+func (h *DjangoBcryptHasher) Compare(encoded, password string) bool {
+	switch {
+	case strings.HasPrefix(encoded, "bcrypt_sha256$"):
+		password = sha256Hex(password)
+		encoded = strings.TrimPrefix(encoded, "bcrypt_sha256$")
+	case strings.HasPrefix(encoded, "bcrypt$"):
+		encoded = strings.TrimPrefix(encoded, "bcrypt$")
+	default:
+		return false
+	}
+
+	return validBcrypt(encoded) && bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil
+}
+
+// Identifies returns true if the hash was created by one of the bcrypt hashers of Django
+func (h *DjangoBcryptHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "bcrypt_sha256$") || strings.HasPrefix(encoded, "bcrypt$")
+}
+
+// Decodes returns true if the hash is a complete bcrypt hash with the prefix of one of the bcrypt hashers of Django
+func (h *DjangoBcryptHasher) Decodes(encoded string) bool {
+	for _, prefix := range []string{"bcrypt_sha256$", "bcrypt$"} {
+		if strings.HasPrefix(encoded, prefix) {
+			return validBcrypt(strings.TrimPrefix(encoded, prefix))
+		}
+	}
+
+	return false
+}
+
+// NeedsRehash returns true if the hash isn't bcrypt_sha256 or uses a lower cost than the hasher
+func (h *DjangoBcryptHasher) NeedsRehash(encoded string) bool {
+	if !strings.HasPrefix(encoded, "bcrypt_sha256$") {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(strings.TrimPrefix(encoded, "bcrypt_sha256$")))
+
+	return err != nil || cost < h.Cost
+}
+
+func sha256Hex(password string) string {
+	sum := sha256.Sum256([]byte(password))
+
+	return hex.EncodeToString(sum[:])
+}
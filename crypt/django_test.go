@@ -0,0 +1,87 @@
+package crypt_test
+
+import (
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The hashes of "12345" created by Django hashers
+const (
+	djangoPBKDF2SHA256Hash = "pbkdf2_sha256$1000$seasalt$SWIWxn78mAiUXDypgvSgTsJqzHkRSikNyQB5XvZMUQg="
+	djangoPBKDF2SHA1Hash   = "pbkdf2_sha1$1000$seasalt$UqxA1FfnRU4TGngQwO+CBD+D5a4="
+	sha256Of12345          = "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5"
+)
+
+func TestDjangoPBKDF2Hasher(t *testing.T) {
+	hasher := &crypt.DjangoPBKDF2Hasher{Iterations: 1000}
+
+	t.Run("Should compare the passwords hashed by Django", func(t *testing.T) {
+		require.True(t, hasher.Compare(djangoPBKDF2SHA256Hash, "12345"))
+		require.True(t, hasher.Compare(djangoPBKDF2SHA1Hash, "12345"))
+		require.False(t, hasher.Compare(djangoPBKDF2SHA256Hash, "123456"))
+		require.False(t, hasher.Compare("pbkdf2_sha256$invalid", "12345"))
+	})
+
+	t.Run("Should hash on the Django format", func(t *testing.T) {
+		hash, err := hasher.Hash("12345")
+
+		require.NoError(t, err)
+		require.True(t, hasher.Identifies(hash))
+		require.True(t, hasher.Compare(hash, "12345"))
+	})
+
+	t.Run("Should need a rehash for sha1 or less iterations", func(t *testing.T) {
+		require.False(t, hasher.NeedsRehash(djangoPBKDF2SHA256Hash))
+		require.True(t, hasher.NeedsRehash(djangoPBKDF2SHA1Hash))
+		require.True(t, (&crypt.DjangoPBKDF2Hasher{Iterations: 260000}).NeedsRehash(djangoPBKDF2SHA256Hash))
+	})
+}
+
+func TestDjangoBcryptHasher(t *testing.T) {
+	hasher := &crypt.DjangoBcryptHasher{Cost: 4}
+
+	t.Run("Should compare the passwords hashed by Django with bcrypt_sha256", func(t *testing.T) {
+		hash, _ := bcrypt.GenerateFromPassword([]byte(sha256Of12345), 4)
+
+		require.True(t, hasher.Compare("bcrypt_sha256$"+string(hash), "12345"))
+		require.False(t, hasher.Compare("bcrypt_sha256$"+string(hash), "123456"))
+	})
+
+	t.Run("Should compare the passwords hashed by Django with bcrypt", func(t *testing.T) {
+		require.True(t, hasher.Compare("bcrypt$"+bcryptHash, "12345"))
+		require.False(t, hasher.Compare(bcryptHash, "12345"))
+	})
+}
+
+func TestSupportedHash(t *testing.T) {
+	t.Run("Should support the hashes of the imported users", func(t *testing.T) {
+		require.True(t, crypt.SupportedHash(bcryptHash))
+		require.True(t, crypt.SupportedHash(djangoPBKDF2SHA256Hash))
+		require.True(t, crypt.SupportedHash("bcrypt_sha256$"+bcryptHash))
+		require.True(t, crypt.ComparePassword(djangoPBKDF2SHA256Hash, "12345"))
+		require.True(t, crypt.NeedsRehash(djangoPBKDF2SHA256Hash))
+	})
+
+	t.Run("Should not support unknown hashes", func(t *testing.T) {
+		require.False(t, crypt.SupportedHash("md5$salt$hash"))
+		require.False(t, crypt.SupportedHash("12345"))
+	})
+
+	t.Run("Should not support the malformed hashes of the supported algorithms", func(t *testing.T) {
+		for _, hash := range []string{
+			"$2a$10$Fl2qgZ7DjYarrymLT6tLle",
+			"bcrypt$invalid",
+			"pbkdf2_sha256$0$seasalt$SWIWxn78mAiUXDypgvSgTsJqzHkRSikNyQB5XvZMUQg=",
+			"pbkdf2_sha256$1000$seasalt$invalid",
+			"pbkdf2_sha256$100000000$seasalt$SWIWxn78mAiUXDypgvSgTsJqzHkRSikNyQB5XvZMUQg=",
+			"$2a$31$" + bcryptHash[7:],
+			"bcrypt_sha256$$2a$31$" + bcryptHash[7:],
+			"$argon2id$v=19$m=1024,t=0,p=1$c2Vhc2FsdA$a2V5",
+		} {
+			require.False(t, crypt.SupportedHash(hash), hash)
+		}
+	})
+}
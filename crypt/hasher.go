@@ -0,0 +1,270 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// HasherArgon2id is the name of the argon2id hasher
+	HasherArgon2id = "argon2id"
+
+	// HasherBcrypt is the name of the bcrypt hasher
+	HasherBcrypt = "bcrypt"
+)
+
+// Hasher hashes the passwords with an algorithm, and verifies the hashes created by it
+type Hasher interface {
+	// Hash returns the encoded hash of the password, which carries the algorithm and its parameters
+	Hash(password string) (string, error)
+
+	// Compare returns true if the password matches the hash
+	Compare(hash, password string) bool
+
+	// Identifies returns true if the hash was created by the algorithm of the hasher
+	Identifies(hash string) bool
+
+	// Decodes returns true if the hash is well formed, with parameters the hasher can compare it with
+	Decodes(hash string) bool
+
+	// NeedsRehash returns true if the hash was created with weaker parameters than the ones of the hasher
+	NeedsRehash(hash string) bool
+}
+
+// DefaultHasher is the hasher of the new passwords, defined by the PASSWORD_HASHER environment variable
+var DefaultHasher = hasherFromEnv()
+
+// hashers are all the algorithms the stored hashes may use, including the ones of the imported users
+var hashers = []Hasher{&Argon2idHasher{}, &BcryptHasher{}, &DjangoPBKDF2Hasher{}, &DjangoBcryptHasher{}}
+
+func hasherFromEnv() Hasher {
+	if env.Config.PasswordHasher == HasherBcrypt {
+		return &BcryptHasher{Cost: env.Config.BcryptCost}
+	}
+
+	return &Argon2idHasher{
+		Memory:      uint32(env.Config.Argon2Memory),
+		Iterations:  uint32(env.Config.Argon2Iterations),
+		Parallelism: uint8(env.Config.Argon2Parallelism),
+	}
+}
+
+// identify returns the hasher that created the hash, or nil if the algorithm isn't known
+func identify(hash string) Hasher {
+	for _, hasher := range hashers {
+		if hasher.Identifies(hash) {
+			return hasher
+		}
+	}
+
+	return nil
+}
+
+// Argon2idHasher hashes the passwords with argon2id, encoding them on the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2idHasher struct {
+	// Memory is the memory used by the algorithm, in KiB
+	Memory uint32
+
+	// Iterations is the number of passes over the memory
+	Iterations uint32
+
+	// Parallelism is the number of threads used by the algorithm
+	Parallelism uint8
+}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// argon2MaxMemory is the most memory a hash can use to be verified: the ARGON2_MAX_MEMORY, or the memory of the
+// new hashes when it's larger
+var argon2MaxMemory = maxArgon2Memory()
+
+func maxArgon2Memory() uint32 {
+	if env.Config.Argon2Memory > env.Config.Argon2MaxMemory {
+		return uint32(env.Config.Argon2Memory)
+	}
+
+	return uint32(env.Config.Argon2MaxMemory)
+}
+
+// argon2MaxIterations and argon2MaxParallelism are the most iterations and threads a hash can use to be verified,
+// four times the ones of the new hashes, since each iteration passes over the whole memory and each thread runs on
+// its own goroutine
+var (
+	argon2MaxIterations  = uint32(4 * env.Config.Argon2Iterations)
+	argon2MaxParallelism = 4 * env.Config.Argon2Parallelism
+)
+
+type argon2Hash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// Hash returns the PHC string of the password hashed with argon2id
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("Error while trying to generate the salt: %v", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.Memory,
+		h.Iterations,
+		h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Compare returns true if the password matches the argon2id hash, using the parameters of the hash
+func (h *Argon2idHasher) Compare(hash, password string) bool {
+	decoded, err := decodeArgon2(hash)
+
+	if err != nil {
+		return false
+	}
+
+	key := argon2.IDKey([]byte(password), decoded.salt, decoded.iterations, decoded.memory, decoded.parallelism, uint32(len(decoded.key)))
+
+	return subtle.ConstantTimeCompare(key, decoded.key) == 1
+}
+
+// Identifies returns true if the hash is an argon2id PHC string
+func (h *Argon2idHasher) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// Decodes returns true if the hash is an argon2id PHC string with safe parameters
+func (h *Argon2idHasher) Decodes(hash string) bool {
+	_, err := decodeArgon2(hash)
+
+	return err == nil
+}
+
+// NeedsRehash returns true if the hash isn't argon2id or uses less memory, iterations or parallelism than the hasher
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	decoded, err := decodeArgon2(hash)
+
+	if err != nil {
+		return true
+	}
+
+	return decoded.memory < h.Memory || decoded.iterations < h.Iterations || decoded.parallelism < h.Parallelism
+}
+
+func decodeArgon2(hash string) (*argon2Hash, error) {
+	// The leading $ produces an empty first part
+	parts := strings.Split(hash, "$")
+
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, fmt.Errorf("Invalid argon2id hash")
+	}
+
+	var version int
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("Unsupported argon2id version")
+	}
+
+	decoded := &argon2Hash{}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &decoded.memory, &decoded.iterations, &decoded.parallelism); err != nil {
+		return nil, fmt.Errorf("Invalid argon2id parameters: %v", err)
+	}
+
+	// argon2 panics without iterations or threads, and spends the memory, passes and threads asked by the hash
+	if decoded.iterations < 1 || decoded.parallelism < 1 || decoded.memory > argon2MaxMemory ||
+		decoded.iterations > argon2MaxIterations || int(decoded.parallelism) > argon2MaxParallelism {
+		return nil, fmt.Errorf("Unsupported argon2id parameters")
+	}
+
+	var err error
+
+	if decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("Invalid argon2id salt: %v", err)
+	}
+
+	if decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(decoded.key) == 0 {
+		return nil, fmt.Errorf("Invalid argon2id key")
+	}
+
+	return decoded, nil
+}
+
+// BcryptHasher hashes the passwords with bcrypt, which uses its own modular crypt format: $2a$<cost>$<salt and key>
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash returns the password hashed with bcrypt
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+
+	if err != nil {
+		return "", fmt.Errorf("Error while trying to encrypt the password: %v", err)
+	}
+
+	return string(hash), nil
+}
+
+// Compare returns true if the password matches the bcrypt hash, as long as its cost isn't above bcryptMaxCost
+func (h *BcryptHasher) Compare(hash, password string) bool {
+	return validBcrypt(hash) && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Identifies returns true if the hash was created by bcrypt
+func (h *BcryptHasher) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Decodes returns true if the hash is a complete bcrypt hash
+func (h *BcryptHasher) Decodes(hash string) bool {
+	return h.Identifies(hash) && validBcrypt(hash)
+}
+
+// NeedsRehash returns true if the hash uses a lower cost than the hasher
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost < h.Cost
+}
+
+// bcryptHashLength is the length of the bcrypt hashes: the version, the cost, the salt and the key
+const bcryptHashLength = 60
+
+// bcryptMaxCost is the highest cost a hash can use to be verified: 14, which takes about a second, or the cost of the
+// new hashes when it's higher. bcrypt accepts costs up to 31, which would take days to compare
+var bcryptMaxCost = maxBcryptCost()
+
+func maxBcryptCost() int {
+	if env.Config.BcryptCost > 14 {
+		return env.Config.BcryptCost
+	}
+
+	return 14
+}
+
+// validBcrypt returns true if the hash has the length, the version and a cost bcrypt accepts, up to bcryptMaxCost
+func validBcrypt(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err == nil && cost <= bcryptMaxCost && len(hash) == bcryptHashLength
+}
@@ -0,0 +1,104 @@
+package crypt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/stretchr/testify/require"
+)
+
+// bcryptHash is the hash of "12345" with the cost 10, like the ones created before argon2id
+const bcryptHash = "$2a$10$Fl2qgZ7DjYarrymLT6tLle3CqQ.LLdQ/U1E2XCvB6tqFwN4Q5m09a"
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := &crypt.Argon2idHasher{Memory: 1024, Iterations: 2, Parallelism: 1}
+
+	t.Run("Should hash the password on the PHC string format", func(t *testing.T) {
+		hash, err := hasher.Hash("12345")
+
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=2,p=1$"))
+		require.Len(t, strings.Split(hash, "$"), 6)
+	})
+
+	t.Run("Should compare the password with the hash", func(t *testing.T) {
+		hash, _ := hasher.Hash("12345")
+
+		require.True(t, hasher.Compare(hash, "12345"))
+		require.False(t, hasher.Compare(hash, "123456"))
+		require.False(t, hasher.Compare("$argon2id$invalid", "12345"))
+	})
+
+	t.Run("Should not match the hashes with unsafe parameters", func(t *testing.T) {
+		hash, _ := hasher.Hash("12345")
+		parts := strings.Split(hash, "$")
+
+		for _, parameters := range []string{"m=1024,t=0,p=1", "m=1024,t=2,p=0", "m=1024,t=2,p=256", "m=1024,t=1000,p=1", "m=1024,t=2,p=200", "m=4294967295,t=2,p=1"} {
+			parts[3] = parameters
+
+			require.False(t, hasher.Compare(strings.Join(parts, "$"), "12345"), parameters)
+		}
+	})
+
+	t.Run("Should use a random salt on each hash", func(t *testing.T) {
+		first, _ := hasher.Hash("12345")
+		second, _ := hasher.Hash("12345")
+
+		require.NotEqual(t, first, second)
+	})
+
+	t.Run("Should need a rehash when the hash uses weaker parameters", func(t *testing.T) {
+		weaker := &crypt.Argon2idHasher{Memory: 512, Iterations: 2, Parallelism: 1}
+		hash, _ := weaker.Hash("12345")
+		current, _ := hasher.Hash("12345")
+
+		require.True(t, hasher.NeedsRehash(hash))
+		require.False(t, hasher.NeedsRehash(current))
+		require.True(t, hasher.NeedsRehash(bcryptHash))
+	})
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := &crypt.BcryptHasher{Cost: 10}
+
+	t.Run("Should compare the password with the hash", func(t *testing.T) {
+		require.True(t, hasher.Compare(bcryptHash, "12345"))
+		require.False(t, hasher.Compare(bcryptHash, "123456"))
+	})
+
+	t.Run("Should not compare the hashes with a cost above the max cost", func(t *testing.T) {
+		require.False(t, hasher.Compare("$2a$31$"+bcryptHash[7:], "12345"))
+	})
+
+	t.Run("Should need a rehash when the hash uses a lower cost", func(t *testing.T) {
+		stronger := &crypt.BcryptHasher{Cost: 11}
+
+		require.True(t, stronger.NeedsRehash(bcryptHash))
+		require.False(t, hasher.NeedsRehash(bcryptHash))
+	})
+}
+
+func TestComparePassword(t *testing.T) {
+	t.Run("Should compare the passwords hashed by any of the algorithms", func(t *testing.T) {
+		hash, err := crypt.HashPassword("12345")
+
+		require.NoError(t, err)
+		require.True(t, crypt.ComparePassword(hash, "12345"))
+		require.True(t, crypt.ComparePassword(bcryptHash, "12345"))
+	})
+
+	t.Run("Should not match hashes of unknown algorithms", func(t *testing.T) {
+		require.False(t, crypt.ComparePassword("12345", "12345"))
+		require.False(t, crypt.ComparePassword("", ""))
+	})
+}
+
+func TestNeedsRehash(t *testing.T) {
+	t.Run("Should rehash the passwords of other algorithms than the default", func(t *testing.T) {
+		hash, _ := crypt.HashPassword("12345")
+
+		require.False(t, crypt.NeedsRehash(hash))
+		require.True(t, crypt.NeedsRehash(bcryptHash))
+	})
+}
@@ -0,0 +1,122 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AccountDeletionDao schedules and purges the deletion of the user accounts. It implements the deletion.Store
+type AccountDeletionDao struct{}
+
+// Schedule schedules the deletion of the user to the time received and returns the updated object
+func (d *AccountDeletionDao) Schedule(id primitive.ObjectID, at time.Time) (*models.User, error) {
+	return (&UserDao{}).update(id, bson.M{
+		"$set": bson.M{
+			"deletion_scheduled_at": at,
+			"updated_at":            time.Now(),
+		},
+	})
+}
+
+// Cancel cancels the deletion of the user and returns the updated object. The deletion can only be cancelled
+// while it isn't due, since the purge may already be running after that
+func (d *AccountDeletionDao) Cancel(id primitive.ObjectID, now time.Time) (*models.User, error) {
+	collection := db.Collection(UserCollection)
+	updatedUser := models.User{}
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{
+		"_id":                   id,
+		"deletion_scheduled_at": bson.M{"$gt": now},
+	}, bson.M{
+		"$unset": bson.M{"deletion_scheduled_at": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+	}, &options).Decode(&updatedUser)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to cancel the deletion of user with id %s: %v", id.String(), err)
+	}
+
+	return &updatedUser, nil
+}
+
+// Due returns up to limit users whose deletion was scheduled to before now, the oldest first
+func (d *AccountDeletionDao) Due(now time.Time, limit int) ([]*models.User, error) {
+	opts := options.Find().
+		SetSort(bson.M{"deletion_scheduled_at": 1}).
+		SetLimit(int64(limit))
+
+	return (&UserDao{}).find(bson.M{"deletion_scheduled_at": bson.M{"$lte": now}}, opts)
+}
+
+// Purge removes the user along with its memberships on groups, the invitations it received on its organizations,
+// the audit events about it and their webhook deliveries. The sessions and tokens are stored on the user document,
+// so they go with it.
+// The user document is removed last, so the purge is tried again when any of the other removals fails
+func (d *AccountDeletionDao) Purge(user *models.User) error {
+	ctx := context.Background()
+
+	_, err := db.Collection(GroupCollection).UpdateMany(ctx, bson.M{"user_ids": user.ID}, bson.M{
+		"$pull": bson.M{"user_ids": user.ID},
+	})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to remove the user from the groups: %v", err)
+	}
+
+	if len(user.Memberships) > 0 {
+		organizationIDs := make([]primitive.ObjectID, len(user.Memberships))
+
+		for i, membership := range user.Memberships {
+			organizationIDs[i] = membership.OrganizationID
+		}
+
+		_, err = db.Collection(InvitationCollection).DeleteMany(ctx, bson.M{
+			"organization_id": bson.M{"$in": organizationIDs},
+			"email":           user.Email,
+		})
+
+		if err != nil {
+			return fmt.Errorf("Error while trying to delete the invitations of the user: %v", err)
+		}
+	}
+
+	_, err = db.Collection(AuditEventCollection).DeleteMany(ctx, bson.M{"target_id": user.ID})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete the audit events of the user: %v", err)
+	}
+
+	_, err = db.Collection(WebhookDeliveryCollection).DeleteMany(ctx, bson.M{"user_id": user.ID})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete the webhook deliveries of the user: %v", err)
+	}
+
+	_, err = db.Collection(UserCollection).DeleteOne(ctx, bson.M{
+		"_id":                   user.ID,
+		"deletion_scheduled_at": bson.M{"$lte": user.DeletionScheduledAt},
+	})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete user with id %s: %v", user.ID.String(), err)
+	}
+
+	return nil
+}
+
+// Record stores the audit event left behind by the purge, and adds it to the outbox, so the other services
+// can remove their copies of the user too
+func (d *AccountDeletionDao) Record(event models.AuditEvent) error {
+	return (&AuditEventDao{}).Record(event)
+}
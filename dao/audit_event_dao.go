@@ -0,0 +1,230 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/pagination"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditEventDao is a representation of a AuditEvent DAO
+type AuditEventDao struct{}
+
+// Record stores the event on the audit log and adds it to the outbox, so it's published to the sinks
+func (d *AuditEventDao) Record(event models.AuditEvent) error {
+	return withTransaction(func(ctx mongo.SessionContext) error {
+		return recordEvents(ctx, []models.AuditEvent{event})
+	})
+}
+
+// GetAllByTarget fetch the audit events whose target is the user, from the oldest to the newest
+func (d *AuditEventDao) GetAllByTarget(targetID primitive.ObjectID) ([]*models.AuditEvent, error) {
+	collection := db.Collection(AuditEventCollection)
+	cursor, err := collection.Find(context.Background(), bson.M{"target_id": targetID}, options.Find().SetSort(bson.M{"created_at": 1}))
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the audit events: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var events []*models.AuditEvent
+
+	for cursor.Next(context.Background()) {
+		event := models.AuditEvent{}
+
+		if err := cursor.Decode(&event); err != nil {
+			return nil, fmt.Errorf("Error while trying to fetch the audit events: %v", err)
+		}
+
+		events = append(events, &event)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the audit events: %v", err)
+	}
+
+	return events, nil
+}
+
+// AuditEventQuery represents the filters and the page of a search for audit events, sorted from the newest to the oldest
+type AuditEventQuery struct {
+	// OrganizationID restricts the search to the events of the organization, when it isn't zero
+	OrganizationID primitive.ObjectID
+
+	// Actions matches any of the actions, when it isn't empty
+	Actions       []string
+	ActorID       primitive.ObjectID
+	TargetID      primitive.ObjectID
+	IP            string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	After         *pagination.Cursor
+	Limit         int
+}
+
+// FindPage returns a page of the audit events matching the query, the total of events matching the filters,
+// and if there are more events after the page
+func (d *AuditEventDao) FindPage(query AuditEventQuery) ([]*models.AuditEvent, int64, bool, error) {
+	collection := db.Collection(AuditEventCollection)
+	filter := query.filter()
+
+	total, err := collection.CountDocuments(context.Background(), filter)
+
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("Error while trying to count the audit events: %v", err)
+	}
+
+	if query.After != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, query.After.Value)
+
+		if err != nil {
+			return nil, 0, false, errors.New("Invalid cursor")
+		}
+
+		filter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{"created_at": bson.M{"$lt": createdAt}},
+			bson.M{"created_at": createdAt, "_id": bson.M{"$lt": query.After.ID}},
+		}}}}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cursor, err := collection.Find(context.Background(), filter, opts)
+
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("Error while trying to fetch the audit events: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var events []*models.AuditEvent
+
+	for cursor.Next(context.Background()) {
+		event := models.AuditEvent{}
+
+		if err := cursor.Decode(&event); err != nil {
+			return nil, 0, false, fmt.Errorf("Error while trying to fetch the audit events: %v", err)
+		}
+
+		events = append(events, &event)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, 0, false, fmt.Errorf("Error while trying to fetch the audit events: %v", err)
+	}
+
+	hasNextPage := len(events) > query.Limit
+
+	if hasNextPage {
+		events = events[:query.Limit]
+	}
+
+	return events, total, hasNextPage, nil
+}
+
+// AuditEventCursor returns the cursor pointing to the event on a list sorted by the creation date
+func AuditEventCursor(event *models.AuditEvent) pagination.Cursor {
+	return pagination.Cursor{Value: event.CreatedAt.UTC().Format(time.RFC3339Nano), ID: event.ID}
+}
+
+func (q AuditEventQuery) filter() bson.M {
+	filter := bson.M{}
+
+	if !q.OrganizationID.IsZero() {
+		filter["organization_id"] = q.OrganizationID
+	}
+
+	if len(q.Actions) > 0 {
+		filter["action"] = bson.M{"$in": q.Actions}
+	}
+
+	if !q.ActorID.IsZero() {
+		filter["actor_id"] = q.ActorID
+	}
+
+	if !q.TargetID.IsZero() {
+		filter["target_id"] = q.TargetID
+	}
+
+	if q.IP != "" {
+		filter["ip"] = q.IP
+	}
+
+	createdAt := bson.M{}
+
+	if q.CreatedAfter != nil {
+		createdAt["$gte"] = *q.CreatedAfter
+	}
+
+	if q.CreatedBefore != nil {
+		createdAt["$lte"] = *q.CreatedBefore
+	}
+
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	return filter
+}
+
+// LoginOrigins returns the distinct IPs and user agents the user signed up or logged in from
+func (d *AuditEventDao) LoginOrigins(userID primitive.ObjectID) ([]string, []string, error) {
+	collection := db.Collection(AuditEventCollection)
+	filter := bson.M{
+		"target_id": userID,
+		"action":    bson.M{"$in": bson.A{models.AuditUserSignedUp, models.AuditLoginSucceeded}},
+	}
+
+	ips, err := collection.Distinct(context.Background(), "ip", filter)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while trying to fetch the login origins: %v", err)
+	}
+
+	userAgents, err := collection.Distinct(context.Background(), "user_agent", filter)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while trying to fetch the login origins: %v", err)
+	}
+
+	return distinctStrings(ips), distinctStrings(userAgents), nil
+}
+
+// FailedLoginIPs returns the distinct IPs the logins of the user were refused from since the time received
+func (d *AuditEventDao) FailedLoginIPs(userID primitive.ObjectID, since time.Time) ([]string, error) {
+	filter := bson.M{
+		"target_id":  userID,
+		"action":     models.AuditLoginFailed,
+		"created_at": bson.M{"$gte": since},
+	}
+
+	ips, err := db.Collection(AuditEventCollection).Distinct(context.Background(), "ip", filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the failed login IPs: %v", err)
+	}
+
+	return distinctStrings(ips), nil
+}
+
+func distinctStrings(values []interface{}) []string {
+	result := []string{}
+
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
@@ -2,22 +2,71 @@ package dao
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/LucasFrezarini/go-auth-manager/env"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx"
 )
 
+var client *mongo.Client
 var db *mongo.Database
 
+// transactions tells if the database supports multi-document transactions, which need a replica set or a sharded cluster
+var transactions bool
+
+// ErrDuplicateKey is returned when a document collides with an unique index, like the one of the user emails
+var ErrDuplicateKey = errors.New("The document collides with an unique index")
+
 // UserCollection defines the name of the user collection
 const UserCollection = "users"
 
+// OrganizationCollection defines the name of the organization collection
+const OrganizationCollection = "organizations"
+
+// InvitationCollection defines the name of the invitation collection
+const InvitationCollection = "invitations"
+
+// GroupCollection defines the name of the group collection
+const GroupCollection = "groups"
+
+// AuditEventCollection defines the name of the audit event collection
+const AuditEventCollection = "audit_events"
+
+// LoginAttemptCollection defines the name of the login attempt collection
+const LoginAttemptCollection = "login_attempts"
+
+// WebhookSubscriptionCollection defines the name of the webhook subscription collection
+const WebhookSubscriptionCollection = "webhook_subscriptions"
+
+// WebhookDeliveryCollection defines the name of the webhook delivery collection
+const WebhookDeliveryCollection = "webhook_deliveries"
+
+// OutboxCollection defines the name of the outbox collection
+const OutboxCollection = "outbox"
+
+// OutboxRetention is how long the published messages are kept on the outbox
+const OutboxRetention = 7 * 24 * time.Hour
+
+// WebhookDeliveryRetention is how long the webhook deliveries are kept on the delivery log. The retries of a
+// delivery end long before it
+const WebhookDeliveryRetention = 30 * 24 * time.Hour
+
+const (
+	globalEmailIndex = "email_1"
+	tenantEmailIndex = "memberships.organization_id_1_email_1"
+)
+
 func init() {
-	client, err := mongo.NewClient(options.Client().ApplyURI(env.Config.MongoURI))
+	var err error
+
+	client, err = mongo.NewClient(options.Client().ApplyURI(env.Config.MongoURI))
 	if err != nil {
 		log.Panic(err)
 	}
@@ -33,19 +82,264 @@ func init() {
 	db = client.Database("auth_manager")
 
 	createIndexes()
+
+	transactions = supportsTransactions()
+}
+
+// supportsTransactions checks if the database is a replica set member or a mongos, where the transactions are available.
+// A standalone mongod is refused unless MONGO_ALLOW_STANDALONE is set, since the writes wouldn't be atomic anymore
+func supportsTransactions() bool {
+	var result struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	if err := db.RunCommand(context.Background(), bson.M{"isMaster": 1}).Decode(&result); err != nil {
+		log.Panicf("Error while trying to check the database topology: %v", err)
+	}
+
+	if result.SetName == "" && result.Msg != "isdbgrid" {
+		if !env.Config.MongoAllowStandalone {
+			log.Panic("The database must be a replica set or a sharded cluster, since the transactions need one. " +
+				"Set MONGO_ALLOW_STANDALONE=true to run on a standalone mongod without them")
+		}
+
+		log.Print("The database isn't a replica set, so the outbox events are written without transactions")
+		return false
+	}
+
+	return true
+}
+
+// withTransaction runs fn inside a transaction, committing it when fn succeeds. The operations of fn must use the
+// context received. When a standalone mongod is allowed, fn runs on a session without a transaction, so its writes
+// aren't atomic anymore: a crash between them keeps only the first ones
+func withTransaction(fn func(ctx mongo.SessionContext) error) error {
+	return client.UseSession(context.Background(), func(ctx mongo.SessionContext) error {
+		if !transactions {
+			return fn(ctx)
+		}
+
+		if err := ctx.StartTransaction(); err != nil {
+			return fmt.Errorf("Error while trying to start a transaction: %v", err)
+		}
+
+		if err := fn(ctx); err != nil {
+			ctx.AbortTransaction(context.Background())
+			return err
+		}
+
+		if err := ctx.CommitTransaction(context.Background()); err != nil {
+			return fmt.Errorf("Error while trying to commit the transaction: %v", err)
+		}
+
+		return nil
+	})
 }
 
 func createIndexes() {
-	opts := options.CreateIndexes().SetMaxTime(10 * time.Second)
-	_, err := db.Collection(UserCollection).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+	emailIndex, staleEmailIndex := globalEmailIndex, tenantEmailIndex
+	emailKeys := bsonx.Doc{{
+		Key:   "email",
+		Value: bsonx.Int32(1),
+	}}
+
+	// When the email is unique per tenant, the same email can be registered once in each organization
+	if env.Config.EmailUniqueness == env.EmailUniquenessTenant {
+		emailIndex, staleEmailIndex = tenantEmailIndex, globalEmailIndex
+		emailKeys = bsonx.Doc{
+			{Key: "memberships.organization_id", Value: bsonx.Int32(1)},
+			{Key: "email", Value: bsonx.Int32(1)},
+		}
+	}
+
+	dropIndexIfExists(UserCollection, staleEmailIndex)
+
+	createIndex(UserCollection, mongo.IndexModel{
+		Keys:    emailKeys,
+		Options: options.Index().SetName(emailIndex).SetUnique(true),
+	})
+
+	// Used by the paginated users query, sorted by creation date, with and without the organization scope
+	createIndex(UserCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "created_at", Value: bsonx.Int32(1)},
+			{Key: "_id", Value: bsonx.Int32(1)},
+		},
+	})
+
+	createIndex(UserCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "memberships.organization_id", Value: bsonx.Int32(1)},
+			{Key: "created_at", Value: bsonx.Int32(1)},
+			{Key: "_id", Value: bsonx.Int32(1)},
+		},
+	})
+
+	createIndex(UserCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "roles",
+			Value: bsonx.Int32(1),
+		}},
+	})
+
+	createIndex(OrganizationCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "slug",
+			Value: bsonx.Int32(1),
+		}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	createIndex(GroupCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "organization_id", Value: bsonx.Int32(1)},
+			{Key: "name", Value: bsonx.Int32(1)},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+
+	createIndex(InvitationCollection, mongo.IndexModel{
 		Keys: bsonx.Doc{{
-			Key:   "email",
+			Key:   "token_hash",
 			Value: bsonx.Int32(1),
 		}},
 		Options: options.Index().SetUnique(true),
-	}, opts)
+	})
+
+	// Used by the paginated audit events query, sorted by creation date, with and without the organization scope
+	createIndex(AuditEventCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "created_at", Value: bsonx.Int32(-1)},
+			{Key: "_id", Value: bsonx.Int32(-1)},
+		},
+	})
+
+	createIndex(AuditEventCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "organization_id", Value: bsonx.Int32(1)},
+			{Key: "created_at", Value: bsonx.Int32(-1)},
+			{Key: "_id", Value: bsonx.Int32(-1)},
+		},
+	})
+
+	// Used to list the events of an user, like on the export of its data
+	createIndex(AuditEventCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "target_id", Value: bsonx.Int32(1)},
+			{Key: "created_at", Value: bsonx.Int32(1)},
+		},
+	})
+
+	// Used by the purge of the accounts whose deletion is due. Only the users that requested the deletion are indexed
+	createIndex(UserCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "deletion_scheduled_at",
+			Value: bsonx.Int32(1),
+		}},
+		Options: options.Index().SetSparse(true),
+	})
+
+	// Used by the webhook dispatchers to claim the next pending delivery
+	createIndex(WebhookDeliveryCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "status", Value: bsonx.Int32(1)},
+			{Key: "next_attempt_at", Value: bsonx.Int32(1)},
+		},
+	})
+
+	// Used by the delivery log of each subscription
+	createIndex(WebhookDeliveryCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "subscription_id", Value: bsonx.Int32(1)},
+			{Key: "created_at", Value: bsonx.Int32(-1)},
+			{Key: "_id", Value: bsonx.Int32(-1)},
+		},
+	})
+
+	// The deliveries, along with the payloads and the personal data on them, are removed by mongo after the retention
+	createIndex(WebhookDeliveryCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "created_at",
+			Value: bsonx.Int32(1),
+		}},
+		Options: options.Index().SetExpireAfterSeconds(int32(WebhookDeliveryRetention.Seconds())),
+	})
+
+	// Used by the purge of the accounts, to remove the deliveries of the events about the user
+	createIndex(WebhookDeliveryCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "user_id",
+			Value: bsonx.Int32(1),
+		}},
+		Options: options.Index().SetSparse(true),
+	})
+
+	// Used by the outbox relays to claim the next pending message. It also creates the collection, which can't
+	// be created inside the transactions that write to it
+	createIndex(OutboxCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "status", Value: bsonx.Int32(1)},
+			{Key: "next_attempt_at", Value: bsonx.Int32(1)},
+		},
+	})
+
+	// The published messages are removed by mongo after the retention. The pending ones don't have the date
+	createIndex(OutboxCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "processed_at",
+			Value: bsonx.Int32(1),
+		}},
+		Options: options.Index().SetExpireAfterSeconds(int32(OutboxRetention.Seconds())),
+	})
+
+	// The login attempts are removed by mongo as soon as they expire
+	createIndex(LoginAttemptCollection, mongo.IndexModel{
+		Keys: bsonx.Doc{{
+			Key:   "expires_at",
+			Value: bsonx.Int32(1),
+		}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+}
+
+func createIndex(collection string, model mongo.IndexModel) {
+	opts := options.CreateIndexes().SetMaxTime(10 * time.Second)
+	_, err := db.Collection(collection).Indexes().CreateOne(context.Background(), model, opts)
 
 	if err != nil {
 		log.Panicf("Error while creating indexes on database: %v", err)
 	}
 }
+
+// dropIndexIfExists removes an index that isn't used by the current configuration anymore,
+// for instance, the global email index after switching the email uniqueness to tenant
+func dropIndexIfExists(collection, name string) {
+	_, err := db.Collection(collection).Indexes().DropOne(context.Background(), name)
+
+	if err != nil && !strings.Contains(err.Error(), "index not found") && !strings.Contains(err.Error(), "ns not found") {
+		log.Panicf("Error while dropping stale index %s on database: %v", name, err)
+	}
+}
+
+// isDuplicateKeyError checks if the error of a write was caused by an unique index. The inserts report it
+// as a write exception, and the find and modify commands as a command error
+func isDuplicateKeyError(err error) bool {
+	if commandError, ok := err.(mongo.CommandError); ok {
+		return commandError.Code == 11000
+	}
+
+	exception, ok := err.(mongo.WriteException)
+
+	if !ok {
+		return false
+	}
+
+	for _, writeError := range exception.WriteErrors {
+		if writeError.Code == 11000 {
+			return true
+		}
+	}
+
+	return false
+}
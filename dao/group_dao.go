@@ -0,0 +1,190 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GroupDao is a representation of a Group DAO
+type GroupDao struct{}
+
+// CreateOne create a group in the collection on the database
+func (d *GroupDao) CreateOne(group models.Group) (primitive.ObjectID, error) {
+	collection := db.Collection(GroupCollection)
+	bson, err := bson.Marshal(group)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	res, err := collection.InsertOne(context.Background(), bson)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to insert the data into the collection Group")
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		return oid, nil
+	}
+
+	log.Print("Error while trying to parse the InsertedID")
+	return primitive.NilObjectID, errors.New("Error while trying to parse the InsertedID")
+}
+
+// FindByID returns the group from the database with the respective _id
+func (d *GroupDao) FindByID(id primitive.ObjectID) (*models.Group, error) {
+	collection := db.Collection(GroupCollection)
+
+	result := models.Group{}
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch group with id %s from the database: %v", id.String(), err)
+	}
+
+	return &result, nil
+}
+
+// FindByName returns the group of the organization with the respective name
+func (d *GroupDao) FindByName(organizationID primitive.ObjectID, name string) (*models.Group, error) {
+	collection := db.Collection(GroupCollection)
+
+	result := models.Group{}
+	err := collection.FindOne(context.Background(), bson.M{"organization_id": organizationID, "name": name}).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch group with name %s from the database: %v", name, err)
+	}
+
+	return &result, nil
+}
+
+// GetAllByOrganization fetch all the groups of the organization
+func (d *GroupDao) GetAllByOrganization(organizationID primitive.ObjectID) ([]*models.Group, error) {
+	return d.find(bson.M{"organization_id": organizationID})
+}
+
+// GetAllByUser fetch the groups the user is a direct member of
+func (d *GroupDao) GetAllByUser(userID primitive.ObjectID) ([]*models.Group, error) {
+	return d.find(bson.M{"user_ids": userID})
+}
+
+// GetAllByIDs fetch the groups with the respective ids
+func (d *GroupDao) GetAllByIDs(ids []primitive.ObjectID) ([]*models.Group, error) {
+	return d.find(bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (d *GroupDao) find(filter interface{}) ([]*models.Group, error) {
+	collection := db.Collection(GroupCollection)
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"name": 1}))
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the groups: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var groups []*models.Group
+
+	for cursor.Next(context.Background()) {
+		group := models.Group{}
+
+		if err := cursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("Error while trying to fetch the groups: %v", err)
+		}
+
+		groups = append(groups, &group)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the groups: %v", err)
+	}
+
+	return groups, nil
+}
+
+// AddUser adds the user as a direct member of the group, returning the updated object
+func (d *GroupDao) AddUser(id, userID primitive.ObjectID) (*models.Group, error) {
+	return d.update(id, bson.M{"$addToSet": bson.M{"user_ids": userID}})
+}
+
+// RemoveUser removes the user from the direct members of the group, returning the updated object
+func (d *GroupDao) RemoveUser(id, userID primitive.ObjectID) (*models.Group, error) {
+	return d.update(id, bson.M{"$pull": bson.M{"user_ids": userID}})
+}
+
+// AddSubgroup nests the subgroup inside the group, returning the updated object
+func (d *GroupDao) AddSubgroup(id, subgroupID primitive.ObjectID) (*models.Group, error) {
+	return d.update(id, bson.M{"$addToSet": bson.M{"group_ids": subgroupID}})
+}
+
+// RemoveSubgroup removes the subgroup from the nested groups of the group, returning the updated object
+func (d *GroupDao) RemoveSubgroup(id, subgroupID primitive.ObjectID) (*models.Group, error) {
+	return d.update(id, bson.M{"$pull": bson.M{"group_ids": subgroupID}})
+}
+
+func (d *GroupDao) update(id primitive.ObjectID, update bson.M) (*models.Group, error) {
+	collection := db.Collection(GroupCollection)
+	updatedGroup := models.Group{}
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	update["$set"] = bson.M{"updated_at": time.Now()}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": id}, update, &options).Decode(&updatedGroup)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to update group: %v", err)
+	}
+
+	return &updatedGroup, nil
+}
+
+// RemoveUserFromOrganization removes the user from every group of the organization
+func (d *GroupDao) RemoveUserFromOrganization(organizationID, userID primitive.ObjectID) error {
+	collection := db.Collection(GroupCollection)
+
+	_, err := collection.UpdateMany(context.Background(), bson.M{"organization_id": organizationID}, bson.M{
+		"$pull": bson.M{"user_ids": userID},
+	})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to remove the user from the groups: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteByID removes the group, and removes it from the groups it was nested in
+func (d *GroupDao) DeleteByID(id primitive.ObjectID) error {
+	collection := db.Collection(GroupCollection)
+
+	_, err := collection.UpdateMany(context.Background(), bson.M{"group_ids": id}, bson.M{
+		"$pull": bson.M{"group_ids": id},
+	})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete group: %v", err)
+	}
+
+	_, err = collection.DeleteOne(context.Background(), bson.M{"_id": id})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete group: %v", err)
+	}
+
+	return nil
+}
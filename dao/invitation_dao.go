@@ -0,0 +1,221 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvitationUsed is returned when the invitation isn't pending anymore, since it was accepted or revoked
+var ErrInvitationUsed = errors.New("The invitation isn't pending anymore")
+
+// InvitationDao is a representation of a Invitation DAO
+type InvitationDao struct{}
+
+// CreateOne create an invitation in the collection on the database
+func (d *InvitationDao) CreateOne(invitation models.Invitation) (primitive.ObjectID, error) {
+	collection := db.Collection(InvitationCollection)
+	bson, err := bson.Marshal(invitation)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	res, err := collection.InsertOne(context.Background(), bson)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to insert the data into the collection Invitation")
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		return oid, nil
+	}
+
+	log.Print("Error while trying to parse the InsertedID")
+	return primitive.NilObjectID, errors.New("Error while trying to parse the InsertedID")
+}
+
+// FindByID returns the invitation from the database with the respective _id
+func (d *InvitationDao) FindByID(id primitive.ObjectID) (*models.Invitation, error) {
+	collection := db.Collection(InvitationCollection)
+
+	result := models.Invitation{}
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch invitation with id %s from the database: %v", id.String(), err)
+	}
+
+	return &result, nil
+}
+
+// FindByTokenHash returns the invitation from the database with the respective token hash
+func (d *InvitationDao) FindByTokenHash(tokenHash string) (*models.Invitation, error) {
+	collection := db.Collection(InvitationCollection)
+
+	result := models.Invitation{}
+	err := collection.FindOne(context.Background(), bson.M{"token_hash": tokenHash}).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch invitation from the database: %v", err)
+	}
+
+	return &result, nil
+}
+
+// GetPendingByOrganization fetch all the invitations of the organization waiting to be accepted
+func (d *InvitationDao) GetPendingByOrganization(organizationID primitive.ObjectID) ([]*models.Invitation, error) {
+	collection := db.Collection(InvitationCollection)
+	cursor, err := collection.Find(context.Background(), bson.M{
+		"organization_id": organizationID,
+		"status":          models.InvitationPending,
+	}, options.Find().SetSort(bson.M{"created_at": -1}))
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the invitations: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var invitations []*models.Invitation
+
+	for cursor.Next(context.Background()) {
+		invitation := models.Invitation{}
+
+		if err := cursor.Decode(&invitation); err != nil {
+			return nil, fmt.Errorf("Error while trying to fetch the invitations: %v", err)
+		}
+
+		invitations = append(invitations, &invitation)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the invitations: %v", err)
+	}
+
+	return invitations, nil
+}
+
+// UpdateStatus changes the status of a pending invitation, returning the updated object.
+// Only pending invitations are updated, so an invitation can't be accepted twice
+func (d *InvitationDao) UpdateStatus(id primitive.ObjectID, status string) (*models.Invitation, error) {
+	return updateInvitationStatus(context.Background(), id, status)
+}
+
+func updateInvitationStatus(ctx context.Context, id primitive.ObjectID, status string) (*models.Invitation, error) {
+	collection := db.Collection(InvitationCollection)
+	updatedInvitation := models.Invitation{}
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	err := collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":    id,
+		"status": models.InvitationPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"updated_at": time.Now(),
+		},
+	}, &options).Decode(&updatedInvitation)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvitationUsed
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to update invitation: %v", err)
+	}
+
+	return &updatedInvitation, nil
+}
+
+// AcceptAsNewUser creates the invited user and marks the invitation as accepted in the same transaction, along with
+// the events. The invitation is only consumed when the user is created, and ErrInvitationUsed is returned, without
+// creating the user, when it isn't pending anymore
+func (d *InvitationDao) AcceptAsNewUser(invitationID primitive.ObjectID, user models.User, events ...models.AuditEvent) (*models.User, error) {
+	err := acceptInvitation(invitationID, events, func(ctx context.Context) error {
+		_, err := db.Collection(UserCollection).InsertOne(ctx, user)
+
+		if isDuplicateKeyError(err) {
+			return ErrDuplicateKey
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// AcceptAsMember adds the membership of the invitation to an existing user and marks the invitation as accepted in
+// the same transaction, along with the events
+func (d *InvitationDao) AcceptAsMember(invitationID, userID primitive.ObjectID, membership models.Membership, events ...models.AuditEvent) (*models.User, error) {
+	var user *models.User
+
+	err := acceptInvitation(invitationID, events, func(ctx context.Context) error {
+		var err error
+		user, err = saveMembership(ctx, userID, membership)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// acceptInvitation runs the write that uses the invitation before marking it as accepted, so a failed write leaves
+// the invitation pending
+func acceptInvitation(id primitive.ObjectID, events []models.AuditEvent, write func(ctx context.Context) error) error {
+	return withTransaction(func(ctx mongo.SessionContext) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+
+		if _, err := updateInvitationStatus(ctx, id, models.InvitationAccepted); err != nil {
+			return err
+		}
+
+		return recordEvents(ctx, events)
+	})
+}
+
+// RevokePending revokes the pending invitations sent to the email on the organization
+func (d *InvitationDao) RevokePending(organizationID primitive.ObjectID, email string) error {
+	collection := db.Collection(InvitationCollection)
+
+	_, err := collection.UpdateMany(context.Background(), bson.M{
+		"organization_id": organizationID,
+		"email":           email,
+		"status":          models.InvitationPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":     models.InvitationRevoked,
+			"updated_at": time.Now(),
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to revoke the pending invitations: %v", err)
+	}
+
+	return nil
+}
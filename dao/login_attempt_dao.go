@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptDao is a representation of a LoginAttempt DAO. It implements the lockout.Store,
+// so the failed attempts are shared between all the instances of the server
+type LoginAttemptDao struct{}
+
+type loginAttempt struct {
+	Key         string    `bson:"_id"`
+	Failures    int       `bson:"failures"`
+	LockedUntil time.Time `bson:"locked_until,omitempty"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+func (a loginAttempt) toAttempts() models.LoginAttempts {
+	return models.LoginAttempts{
+		Failures:    a.Failures,
+		LockedUntil: a.LockedUntil,
+		ExpiresAt:   a.ExpiresAt,
+	}
+}
+
+// Get returns the attempts of the key, or zero attempts if there isn't any
+func (d *LoginAttemptDao) Get(key string, now time.Time) (models.LoginAttempts, error) {
+	collection := db.Collection(LoginAttemptCollection)
+
+	result := loginAttempt{}
+	err := collection.FindOne(context.Background(), bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(&result)
+
+	if err == mongo.ErrNoDocuments {
+		return models.LoginAttempts{}, nil
+	}
+
+	if err != nil {
+		return models.LoginAttempts{}, fmt.Errorf("Error while trying to fetch the login attempts from the database: %v", err)
+	}
+
+	return result.toAttempts(), nil
+}
+
+// Increment adds a failure to the key, keeping it until expiresAt, and returns the updated attempts
+func (d *LoginAttemptDao) Increment(key string, now, expiresAt time.Time) (models.LoginAttempts, error) {
+	collection := db.Collection(LoginAttemptCollection)
+
+	// The expired attempts may still be on the collection until the TTL monitor removes them
+	_, err := collection.DeleteOne(context.Background(), bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$lte": now},
+	})
+
+	if err != nil {
+		return models.LoginAttempts{}, fmt.Errorf("Error while trying to remove the expired login attempts from the database: %v", err)
+	}
+
+	result := loginAttempt{}
+	err = collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": key},
+		bson.M{
+			"$inc": bson.M{"failures": 1},
+			"$max": bson.M{"expires_at": expiresAt},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&result)
+
+	if err != nil {
+		return models.LoginAttempts{}, fmt.Errorf("Error while trying to register the login attempt on the database: %v", err)
+	}
+
+	return result.toAttempts(), nil
+}
+
+// Lock locks the key until the time received, keeping it until expiresAt
+func (d *LoginAttemptDao) Lock(key string, until, expiresAt time.Time) error {
+	collection := db.Collection(LoginAttemptCollection)
+
+	_, err := collection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": key},
+		bson.M{
+			"$set": bson.M{"locked_until": until},
+			"$max": bson.M{"expires_at": expiresAt},
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to lock the login attempts on the database: %v", err)
+	}
+
+	return nil
+}
+
+// Reset removes the attempts of the key
+func (d *LoginAttemptDao) Reset(key string) error {
+	collection := db.Collection(LoginAttemptCollection)
+
+	if _, err := collection.DeleteOne(context.Background(), bson.M{"_id": key}); err != nil {
+		return fmt.Errorf("Error while trying to reset the login attempts on the database: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MembershipDao is a representation of a Membership DAO
+type MembershipDao struct{}
+
+// Save sets the role of the user on the organization, pushing a new membership on the memberships user array if the user isn't a member yet.
+// The other fields of an existing membership are kept
+// ErrDuplicateKey is returned when the email uniqueness is per tenant and another member of the organization has the same email
+func (d *MembershipDao) Save(userID primitive.ObjectID, membership models.Membership) (*models.User, error) {
+	return saveMembership(context.Background(), userID, membership)
+}
+
+// saveMembership sets the role of the user on the organization, using the context received so it can be part of a transaction
+func saveMembership(ctx context.Context, userID primitive.ObjectID, membership models.Membership) (*models.User, error) {
+	updatedUser := models.User{}
+	collection := db.Collection(UserCollection)
+
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	err := collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":                         userID,
+		"memberships.organization_id": membership.OrganizationID,
+	}, bson.M{
+		"$set": bson.M{"memberships.$.role": membership.Role},
+	}, &options).Decode(&updatedUser)
+
+	if err == nil {
+		return &updatedUser, nil
+	}
+
+	if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("Error while trying to save the membership: %v", err)
+	}
+
+	err = collection.FindOneAndUpdate(ctx, bson.M{"_id": userID}, bson.M{
+		"$push": bson.M{"memberships": membership},
+	}, &options).Decode(&updatedUser)
+
+	if isDuplicateKeyError(err) {
+		return nil, ErrDuplicateKey
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to save the membership: %v", err)
+	}
+
+	return &updatedUser, nil
+}
+
+// DeleteOne pulls the membership of the organization from the memberships user array
+func (d *MembershipDao) DeleteOne(userID, organizationID primitive.ObjectID) (*models.User, error) {
+	updatedUser := models.User{}
+	collection := db.Collection(UserCollection)
+
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": userID}, bson.M{
+		"$pull": bson.M{
+			"memberships": bson.M{"organization_id": organizationID},
+		},
+	}, &options).Decode(&updatedUser)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to delete the membership: %v", err)
+	}
+
+	return &updatedUser, nil
+}
@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrganizationDao is a representation of a Organization DAO
+type OrganizationDao struct{}
+
+// CreateOne create an organization in the collection on the database
+func (d *OrganizationDao) CreateOne(organization models.Organization) (primitive.ObjectID, error) {
+	collection := db.Collection(OrganizationCollection)
+	bson, err := bson.Marshal(organization)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	res, err := collection.InsertOne(context.Background(), bson)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to insert the data into the collection Organization")
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		return oid, nil
+	}
+
+	log.Print("Error while trying to parse the InsertedID")
+	return primitive.NilObjectID, errors.New("Error while trying to parse the InsertedID")
+}
+
+// FindByID returns the organization from the database with the respective _id
+func (d *OrganizationDao) FindByID(id primitive.ObjectID) (*models.Organization, error) {
+	collection := db.Collection(OrganizationCollection)
+
+	result := models.Organization{}
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch organization with id %s from the database: %v", id.String(), err)
+	}
+
+	return &result, nil
+}
+
+// FindBySlug returns the organization from the database with the respective slug
+func (d *OrganizationDao) FindBySlug(slug string) (*models.Organization, error) {
+	collection := db.Collection(OrganizationCollection)
+
+	result := models.Organization{}
+	err := collection.FindOne(context.Background(), bson.M{"slug": slug}).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch organization with slug %s from the database: %v", slug, err)
+	}
+
+	return &result, nil
+}
+
+// GetAllByIDs fetch the organizations with the respective ids
+func (d *OrganizationDao) GetAllByIDs(ids []primitive.ObjectID) ([]*models.Organization, error) {
+	collection := db.Collection(OrganizationCollection)
+	cursor, err := collection.Find(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the organizations: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var organizations []*models.Organization
+
+	for cursor.Next(context.Background()) {
+		organization := models.Organization{}
+
+		if err := cursor.Decode(&organization); err != nil {
+			return nil, fmt.Errorf("Error while trying to fetch the organizations: %v", err)
+		}
+
+		organizations = append(organizations, &organization)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the organizations: %v", err)
+	}
+
+	return organizations, nil
+}
@@ -0,0 +1,106 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxDao is a representation of an Outbox DAO. It implements the outbox.Store, so the messages
+// are shared between all the instances of the server
+type OutboxDao struct{}
+
+// Claim returns the oldest pending message due before now, pushing its next attempt to leaseUntil. The update
+// is atomic, so each message is claimed by a single relay even with many instances of the server running
+func (d *OutboxDao) Claim(now, leaseUntil time.Time) (*models.OutboxMessage, error) {
+	collection := db.Collection(OutboxCollection)
+	claimedMessage := models.OutboxMessage{}
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+		Sort:           bson.M{"next_attempt_at": 1},
+	}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{
+		"status":          models.OutboxPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"next_attempt_at": leaseUntil},
+	}, &options).Decode(&claimedMessage)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to claim an outbox message: %v", err)
+	}
+
+	return &claimedMessage, nil
+}
+
+// Save stores the outcome of an attempt to publish the message
+func (d *OutboxDao) Save(message *models.OutboxMessage) error {
+	collection := db.Collection(OutboxCollection)
+
+	_, err := collection.ReplaceOne(context.Background(), bson.M{"_id": message.ID}, message)
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to save outbox message with id %s: %v", message.ID.String(), err)
+	}
+
+	return nil
+}
+
+// recordEvents stores the events on the audit log and adds them to the outbox. It's called inside the transaction
+// of the change the events describe, so they're written only if the change is, and always with it
+func recordEvents(ctx context.Context, events []models.AuditEvent) error {
+	for _, event := range events {
+		// The id is sent to the sinks along with the event, so it's defined before the event is stored
+		if event.ID.IsZero() {
+			event.ID = primitive.NewObjectID()
+		}
+
+		_, err := db.Collection(AuditEventCollection).InsertOne(ctx, event)
+
+		if err != nil {
+			return fmt.Errorf("Error while trying to insert the data into the collection AuditEvent: %v", err)
+		}
+
+		_, err = db.Collection(OutboxCollection).InsertOne(ctx, models.OutboxMessage{
+			Event:         event,
+			Status:        models.OutboxPending,
+			NextAttemptAt: event.CreatedAt,
+			CreatedAt:     event.CreatedAt,
+		})
+
+		if err != nil {
+			return fmt.Errorf("Error while trying to insert the data into the collection Outbox: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// withEvents runs the write and records the events in the same transaction. The write must use the context
+// received. Without events, the write runs alone, outside of any transaction
+func withEvents(events []models.AuditEvent, write func(ctx context.Context) error) error {
+	if len(events) == 0 {
+		return write(context.Background())
+	}
+
+	return withTransaction(func(ctx mongo.SessionContext) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+
+		return recordEvents(ctx, events)
+	})
+}
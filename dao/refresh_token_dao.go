@@ -39,3 +39,25 @@ func (r *RefreshTokenDao) CreateOne(userID primitive.ObjectID, token models.Refr
 
 	return updatedUser, nil
 }
+
+// DeleteAll removes every refresh token of the user, so none of its sessions can be refreshed anymore
+func (r *RefreshTokenDao) DeleteAll(userID primitive.ObjectID) (models.User, error) {
+	updatedUser := models.User{}
+	collection := db.Collection(UserCollection)
+
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": userID}, bson.M{
+		"$unset": bson.M{"refresh_tokens": ""},
+	}, &options).Decode(&updatedUser)
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("Error while trying to delete the refresh tokens: %v", err)
+	}
+
+	return updatedUser, nil
+}
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"regexp"
 	"time"
 
 	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/pagination"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,10 +20,164 @@ import (
 // UserDao is a representation of a User DAO
 type UserDao struct{}
 
-// GetAll fetch all the users registered on the database
-func (d *UserDao) GetAll() ([]*models.User, error) {
+// UserQuery represents the filters, the sorting and the page of a search for users
+type UserQuery struct {
+	// OrganizationID restricts the search to the members of the organization, when it isn't zero
+	OrganizationID primitive.ObjectID
+	EmailPrefix    string
+
+	// Role matches the global roles of the user, or its role on the organization
+	Role          string
+	Active        *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	// SortField is the field used to sort the users: UserSortCreatedAt or UserSortEmail
+	SortField  string
+	Descending bool
+	After      *pagination.Cursor
+	Limit      int
+}
+
+const (
+	// UserSortCreatedAt sorts the users by their creation date
+	UserSortCreatedAt = "created_at"
+
+	// UserSortEmail sorts the users by their email
+	UserSortEmail = "email"
+)
+
+// FindPage returns a page of the users matching the query, the total of users matching the filters,
+// and if there are more users after the page
+func (d *UserDao) FindPage(query UserQuery) ([]*models.User, int64, bool, error) {
 	collection := db.Collection(UserCollection)
-	cursor, err := collection.Find(context.Background(), bson.D{})
+	filter := query.filter()
+
+	total, err := collection.CountDocuments(context.Background(), filter)
+
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("Error while trying to count the users: %v", err)
+	}
+
+	direction := 1
+	operator := "$gt"
+
+	if query.Descending {
+		direction = -1
+		operator = "$lt"
+	}
+
+	if query.After != nil {
+		value, err := query.cursorValue(*query.After)
+
+		if err != nil {
+			return nil, 0, false, err
+		}
+
+		filter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{query.SortField: bson.M{operator: value}},
+			bson.M{query.SortField: value, "_id": bson.M{operator: query.After.ID}},
+		}}}}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: query.SortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(query.Limit + 1))
+
+	users, err := d.find(filter, opts)
+
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	hasNextPage := len(users) > query.Limit
+
+	if hasNextPage {
+		users = users[:query.Limit]
+	}
+
+	return users, total, hasNextPage, nil
+}
+
+// UserCursor returns the cursor pointing to the user on a list sorted by the field
+func UserCursor(user *models.User, sortField string) pagination.Cursor {
+	if sortField == UserSortEmail {
+		return pagination.Cursor{Value: user.Email, ID: user.ID}
+	}
+
+	return pagination.Cursor{Value: user.CreatedAt.UTC().Format(time.RFC3339Nano), ID: user.ID}
+}
+
+func (q UserQuery) cursorValue(cursor pagination.Cursor) (interface{}, error) {
+	if q.SortField == UserSortEmail {
+		return cursor.Value, nil
+	}
+
+	value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+
+	if err != nil {
+		return nil, errors.New("Invalid cursor")
+	}
+
+	return value, nil
+}
+
+func (q UserQuery) filter() bson.M {
+	filter := bson.M{}
+
+	if !q.OrganizationID.IsZero() {
+		filter["memberships.organization_id"] = q.OrganizationID
+	}
+
+	if q.EmailPrefix != "" {
+		filter["email"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(q.EmailPrefix)}
+	}
+
+	if q.Role != "" {
+		roles := bson.A{bson.M{"roles": q.Role}}
+
+		if !q.OrganizationID.IsZero() {
+			roles = append(roles, bson.M{"memberships": bson.M{"$elemMatch": bson.M{
+				"organization_id": q.OrganizationID,
+				"role":            q.Role,
+			}}})
+		}
+
+		filter["$or"] = roles
+	}
+
+	// Inactive users may not have the active field at all, since it's omitted when false
+	if q.Active != nil && *q.Active {
+		filter["active"] = true
+	} else if q.Active != nil {
+		filter["active"] = bson.M{"$ne": true}
+	}
+
+	createdAt := bson.M{}
+
+	if q.CreatedAfter != nil {
+		createdAt["$gte"] = *q.CreatedAfter
+	}
+
+	if q.CreatedBefore != nil {
+		createdAt["$lte"] = *q.CreatedBefore
+	}
+
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	return filter
+}
+
+// GetAllByIDs fetch the users with the respective ids
+func (d *UserDao) GetAllByIDs(ids []primitive.ObjectID) ([]*models.User, error) {
+	return d.find(bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (d *UserDao) find(filter interface{}, opts ...*options.FindOptions) ([]*models.User, error) {
+	collection := db.Collection(UserCollection)
+	cursor, err := collection.Find(context.Background(), filter, opts...)
 	if err != nil {
 		log.Print(err)
 		return nil, fmt.Errorf("Error while trying to fetch all users: %v", err)
@@ -50,8 +207,9 @@ func (d *UserDao) GetAll() ([]*models.User, error) {
 	return users, nil
 }
 
-// CreateOne create an user in the collection on the database
-func (d *UserDao) CreateOne(user models.User) (primitive.ObjectID, error) {
+// CreateOne create an user in the collection on the database. The events are recorded in the same transaction,
+// so they're published even when the process stops right after the user is created
+func (d *UserDao) CreateOne(user models.User, events ...models.AuditEvent) (primitive.ObjectID, error) {
 	collection := db.Collection(UserCollection)
 	bson, err := bson.Marshal(user)
 
@@ -60,7 +218,16 @@ func (d *UserDao) CreateOne(user models.User) (primitive.ObjectID, error) {
 		return primitive.NilObjectID, errors.New("Error while trying to convert the input to BSON")
 	}
 
-	res, err := collection.InsertOne(context.Background(), bson)
+	var res *mongo.InsertOneResult
+
+	err = withEvents(events, func(ctx context.Context) error {
+		res, err = collection.InsertOne(ctx, bson)
+		return err
+	})
+
+	if isDuplicateKeyError(err) {
+		return primitive.NilObjectID, ErrDuplicateKey
+	}
 
 	if err != nil {
 		log.Print(err)
@@ -75,6 +242,18 @@ func (d *UserDao) CreateOne(user models.User) (primitive.ObjectID, error) {
 	return primitive.NilObjectID, errors.New("Error while trying to parse the InsertedID")
 }
 
+// ImportUser creates an user imported from another identity provider. It implements the importer.Store,
+// recording the events in the same transaction, and returns models.ErrDuplicateEmail when the email is already registered
+func (d *UserDao) ImportUser(user models.User, events ...models.AuditEvent) error {
+	_, err := d.CreateOne(user, events...)
+
+	if err == ErrDuplicateKey {
+		return models.ErrDuplicateEmail
+	}
+
+	return err
+}
+
 // FindOne returns a result based on the fields passed on the user struct
 func (d *UserDao) FindOne(user models.User) (*models.User, error) {
 	collection := db.Collection(UserCollection)
@@ -95,6 +274,46 @@ func (d *UserDao) FindOne(user models.User) (*models.User, error) {
 	return &result, nil
 }
 
+// FindAll returns every user matching the fields passed on the user struct
+func (d *UserDao) FindAll(user models.User) ([]*models.User, error) {
+	filter, err := bson.Marshal(user)
+
+	if err != nil {
+		log.Print(err)
+		return nil, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	return d.find(filter)
+}
+
+// FindOneInOrganization returns a result based on the fields passed on the user struct, restricted to the members of the organization
+func (d *UserDao) FindOneInOrganization(user models.User, organizationID primitive.ObjectID) (*models.User, error) {
+	collection := db.Collection(UserCollection)
+	filter, err := bson.Marshal(user)
+
+	if err != nil {
+		log.Print(err)
+		return nil, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	var doc bson.M
+	if err = bson.Unmarshal(filter, &doc); err != nil {
+		log.Print(err)
+		return nil, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	doc["memberships.organization_id"] = organizationID
+
+	result := models.User{}
+	err = collection.FindOne(context.Background(), doc).Decode(&result)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch user from the database: %v", err)
+	}
+
+	return &result, nil
+}
+
 // FindByID returns the user from the database with the respective _id
 func (d *UserDao) FindByID(id primitive.ObjectID) (*models.User, error) {
 	collection := db.Collection(UserCollection)
@@ -109,8 +328,83 @@ func (d *UserDao) FindByID(id primitive.ObjectID) (*models.User, error) {
 	return &result, nil
 }
 
-// UpdateByID updates a user by his id and returns the updated object
-func (d *UserDao) UpdateByID(id primitive.ObjectID, data models.User) (*models.User, error) {
+// UpdateByID updates a user by his id and returns the updated object.
+// Only the email, password and roles are updated, and only when they aren't empty. The events are recorded in the same transaction
+func (d *UserDao) UpdateByID(id primitive.ObjectID, data models.User, events ...models.AuditEvent) (*models.User, error) {
+	fields := bson.M{
+		"updated_at": time.Now(),
+	}
+
+	if data.Email != "" {
+		fields["email"] = data.Email
+	}
+
+	if data.Password != "" {
+		fields["password"] = data.Password
+	}
+
+	if data.Roles != nil {
+		fields["roles"] = data.Roles
+	}
+
+	return d.updateWithEvents(id, events, bson.M{"$set": fields})
+}
+
+// ChangePassword replaces the password of the user by the hash, moving the current one to the password history,
+// which keeps only the last historySize hashes, and returns the updated object. The events are recorded in the same transaction
+func (d *UserDao) ChangePassword(user *models.User, hash string, historySize int, events ...models.AuditEvent) (*models.User, error) {
+	now := time.Now()
+
+	return d.updateWithEvents(user.ID, events, bson.M{
+		"$set": bson.M{
+			"password":            hash,
+			"password_changed_at": now,
+			"updated_at":          now,
+		},
+		"$push": bson.M{
+			"password_history": bson.M{
+				"$each":     []string{user.Password},
+				"$position": 0,
+				"$slice":    historySize,
+			},
+		},
+	})
+}
+
+// RehashPassword replaces the hash of the current password by a new hash of the same password. Nothing is
+// changed when the password was changed since the old hash was read
+func (d *UserDao) RehashPassword(id primitive.ObjectID, oldHash, newHash string) error {
+	collection := db.Collection(UserCollection)
+
+	_, err := collection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": id, "password": oldHash},
+		bson.M{"$set": bson.M{"password": newHash}},
+	)
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to rehash the password of user with id %s: %v", id.String(), err)
+	}
+
+	return nil
+}
+
+// SetActive activates or deactivates a user by his id and returns the updated object. The events are recorded in the same transaction
+func (d *UserDao) SetActive(id primitive.ObjectID, active bool, events ...models.AuditEvent) (*models.User, error) {
+	return d.updateWithEvents(id, events, bson.M{
+		"$set": bson.M{
+			"active":     active,
+			"updated_at": time.Now(),
+		},
+	})
+}
+
+func (d *UserDao) update(id primitive.ObjectID, update bson.M) (*models.User, error) {
+	return d.updateWithEvents(id, nil, update)
+}
+
+// updateWithEvents updates the user and records the events in the same transaction
+func (d *UserDao) updateWithEvents(id primitive.ObjectID, events []models.AuditEvent, update bson.M) (*models.User, error) {
 	collection := db.Collection(UserCollection)
 	updatedUser := models.User{}
 	returnDocument := options.After
@@ -119,13 +413,9 @@ func (d *UserDao) UpdateByID(id primitive.ObjectID, data models.User) (*models.U
 		ReturnDocument: &returnDocument,
 	}
 
-	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": id}, bson.M{
-		"$set": bson.M{
-			"password":   data.Password,
-			"active":     data.Active,
-			"updated_at": time.Now(),
-		},
-	}, &options).Decode(&updatedUser)
+	err := withEvents(events, func(ctx context.Context) error {
+		return collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, &options).Decode(&updatedUser)
+	})
 
 	if err != nil {
 		return nil, fmt.Errorf("Error while trying to update document: %v", err)
@@ -133,3 +423,20 @@ func (d *UserDao) UpdateByID(id primitive.ObjectID, data models.User) (*models.U
 
 	return &updatedUser, nil
 }
+
+// DeleteByID removes the user with the respective _id from the database
+func (d *UserDao) DeleteByID(id primitive.ObjectID) error {
+	collection := db.Collection(UserCollection)
+
+	res, err := collection.DeleteOne(context.Background(), bson.M{"_id": id})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete user with id %s: %v", id.String(), err)
+	}
+
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("Error while trying to delete user with id %s: user not found", id.String())
+	}
+
+	return nil
+}
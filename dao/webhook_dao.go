@@ -0,0 +1,254 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookDao is a representation of a Webhook DAO. It implements the webhook.Store, so the outbox
+// of the deliveries is shared between all the instances of the server
+type WebhookDao struct{}
+
+// CreateSubscription create a webhook subscription in the collection on the database
+func (d *WebhookDao) CreateSubscription(subscription models.WebhookSubscription) (primitive.ObjectID, error) {
+	collection := db.Collection(WebhookSubscriptionCollection)
+	bson, err := bson.Marshal(subscription)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to convert the input to BSON")
+	}
+
+	res, err := collection.InsertOne(context.Background(), bson)
+
+	if err != nil {
+		log.Print(err)
+		return primitive.NilObjectID, errors.New("Error while trying to insert the data into the collection WebhookSubscription")
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		return oid, nil
+	}
+
+	log.Print("Error while trying to parse the InsertedID")
+	return primitive.NilObjectID, errors.New("Error while trying to parse the InsertedID")
+}
+
+// Subscription returns the subscription with the respective _id, or models.ErrWebhookSubscriptionNotFound if there isn't any
+func (d *WebhookDao) Subscription(id primitive.ObjectID) (*models.WebhookSubscription, error) {
+	collection := db.Collection(WebhookSubscriptionCollection)
+
+	result := models.WebhookSubscription{}
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&result)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, models.ErrWebhookSubscriptionNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch webhook subscription with id %s from the database: %v", id.String(), err)
+	}
+
+	return &result, nil
+}
+
+// GetSubscriptionsByOrganization fetch the subscriptions of the organization. The global subscriptions,
+// which aren't restricted to any organization, are fetched when the organization is zero
+func (d *WebhookDao) GetSubscriptionsByOrganization(organizationID primitive.ObjectID) ([]*models.WebhookSubscription, error) {
+	filter := bson.M{"organization_id": organizationID}
+
+	if organizationID.IsZero() {
+		filter = bson.M{"organization_id": bson.M{"$exists": false}}
+	}
+
+	return d.findSubscriptions(filter)
+}
+
+// Subscriptions returns the active subscriptions to the event type which receive the events of the organization:
+// the ones of the organization itself and the global ones
+func (d *WebhookDao) Subscriptions(eventType string, organizationID primitive.ObjectID) ([]*models.WebhookSubscription, error) {
+	organizations := bson.A{bson.M{"organization_id": bson.M{"$exists": false}}}
+
+	if !organizationID.IsZero() {
+		organizations = append(organizations, bson.M{"organization_id": organizationID})
+	}
+
+	return d.findSubscriptions(bson.M{
+		"active":      true,
+		"event_types": eventType,
+		"$or":         organizations,
+	})
+}
+
+func (d *WebhookDao) findSubscriptions(filter interface{}) ([]*models.WebhookSubscription, error) {
+	collection := db.Collection(WebhookSubscriptionCollection)
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"created_at": 1}))
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the webhook subscriptions: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var subscriptions []*models.WebhookSubscription
+
+	for cursor.Next(context.Background()) {
+		subscription := models.WebhookSubscription{}
+
+		if err := cursor.Decode(&subscription); err != nil {
+			return nil, fmt.Errorf("Error while trying to fetch the webhook subscriptions: %v", err)
+		}
+
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the webhook subscriptions: %v", err)
+	}
+
+	return subscriptions, nil
+}
+
+// UpdateSubscription stores the URL, the secret, the event types and the status of the subscription,
+// returning the updated object
+func (d *WebhookDao) UpdateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	collection := db.Collection(WebhookSubscriptionCollection)
+	updatedSubscription := models.WebhookSubscription{}
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+	}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": subscription.ID}, bson.M{
+		"$set": bson.M{
+			"url":         subscription.URL,
+			"secret":      subscription.Secret,
+			"event_types": subscription.EventTypes,
+			"active":      subscription.Active,
+			"updated_at":  time.Now(),
+		},
+	}, &options).Decode(&updatedSubscription)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to update webhook subscription: %v", err)
+	}
+
+	return &updatedSubscription, nil
+}
+
+// DeleteSubscription removes the subscription. Its deliveries are kept on the log, and the pending
+// ones fail instead of being sent
+func (d *WebhookDao) DeleteSubscription(id primitive.ObjectID) error {
+	collection := db.Collection(WebhookSubscriptionCollection)
+
+	_, err := collection.DeleteOne(context.Background(), bson.M{"_id": id})
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to delete webhook subscription with id %s: %v", id.String(), err)
+	}
+
+	return nil
+}
+
+// Enqueue adds the deliveries to the outbox
+func (d *WebhookDao) Enqueue(deliveries []models.WebhookDelivery) error {
+	collection := db.Collection(WebhookDeliveryCollection)
+	documents := make([]interface{}, len(deliveries))
+
+	for i, delivery := range deliveries {
+		documents[i] = delivery
+	}
+
+	if _, err := collection.InsertMany(context.Background(), documents); err != nil {
+		return fmt.Errorf("Error while trying to enqueue the webhook deliveries: %v", err)
+	}
+
+	return nil
+}
+
+// Claim returns the oldest pending delivery due before now, pushing its next attempt to leaseUntil. The update
+// is atomic, so each delivery is claimed by a single dispatcher even with many instances of the server running
+func (d *WebhookDao) Claim(now, leaseUntil time.Time) (*models.WebhookDelivery, error) {
+	collection := db.Collection(WebhookDeliveryCollection)
+	claimedDelivery := models.WebhookDelivery{}
+	returnDocument := options.After
+
+	options := options.FindOneAndUpdateOptions{
+		ReturnDocument: &returnDocument,
+		Sort:           bson.M{"next_attempt_at": 1},
+	}
+
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{
+		"status":          models.WebhookDeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"next_attempt_at": leaseUntil},
+	}, &options).Decode(&claimedDelivery)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to claim a webhook delivery: %v", err)
+	}
+
+	return &claimedDelivery, nil
+}
+
+// Save stores the outcome of an attempt to send the delivery
+func (d *WebhookDao) Save(delivery *models.WebhookDelivery) error {
+	collection := db.Collection(WebhookDeliveryCollection)
+
+	_, err := collection.ReplaceOne(context.Background(), bson.M{"_id": delivery.ID}, delivery)
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to save webhook delivery with id %s: %v", delivery.ID.String(), err)
+	}
+
+	return nil
+}
+
+// GetDeliveriesBySubscription fetch up to limit deliveries of the subscription, from the newest to the oldest
+func (d *WebhookDao) GetDeliveriesBySubscription(subscriptionID primitive.ObjectID, limit int) ([]*models.WebhookDelivery, error) {
+	collection := db.Collection(WebhookDeliveryCollection)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(context.Background(), bson.M{"subscription_id": subscriptionID}, opts)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the webhook deliveries: %v", err)
+	}
+
+	defer cursor.Close(context.Background())
+
+	var deliveries []*models.WebhookDelivery
+
+	for cursor.Next(context.Background()) {
+		delivery := models.WebhookDelivery{}
+
+		if err := cursor.Decode(&delivery); err != nil {
+			return nil, fmt.Errorf("Error while trying to fetch the webhook deliveries: %v", err)
+		}
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to fetch the webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
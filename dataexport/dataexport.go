@@ -0,0 +1,199 @@
+// Package dataexport builds the archive with everything stored about an user, which is handed to the user on a data
+// portability request. Secrets, like the password hashes and the refresh tokens, are never part of the archive
+package dataexport
+
+import (
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Archive is the export of the data of an user
+type Archive struct {
+	ExportedAt  time.Time    `json:"exported_at"`
+	Profile     Profile      `json:"profile"`
+	Roles       []string     `json:"roles"`
+	Memberships []Membership `json:"memberships"`
+	Groups      []Group      `json:"groups"`
+
+	// Sessions are the refresh tokens of the user, described by their metadata only
+	Sessions []Session `json:"sessions"`
+
+	// LoginHistory are the successful and failed logins on the account
+	LoginHistory []Event `json:"login_history"`
+
+	// Activity are the other events recorded about the user, like the changes done by admins
+	Activity []Event `json:"activity"`
+}
+
+// Profile is the account data of the user
+type Profile struct {
+	ID                string     `json:"id"`
+	Email             string     `json:"email"`
+	Active            bool       `json:"active"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Membership is an organization the user belongs to
+type Membership struct {
+	OrganizationID   string `json:"organization_id"`
+	OrganizationName string `json:"organization_name,omitempty"`
+	OrganizationSlug string `json:"organization_slug,omitempty"`
+	Role             string `json:"role"`
+}
+
+// Group is a group the user is a direct member of
+type Group struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+}
+
+// Session is a refresh token of the user
+type Session struct {
+	Identifier     string     `json:"identifier"`
+	OrganizationID string     `json:"organization_id,omitempty"`
+	IssuedAt       *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
+
+// Event is an audit event about the user
+type Event struct {
+	Action         string                 `json:"action"`
+	ActorID        string                 `json:"actor_id,omitempty"`
+	OrganizationID string                 `json:"organization_id,omitempty"`
+	IP             string                 `json:"ip,omitempty"`
+	UserAgent      string                 `json:"user_agent,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// Related is the data of the other collections that refers to the user
+type Related struct {
+	// Organizations are the organizations of the memberships of the user
+	Organizations []*models.Organization
+
+	// Groups are the groups the user is a direct member of
+	Groups []*models.Group
+
+	// Events are the audit events whose target is the user
+	Events []*models.AuditEvent
+}
+
+// New builds the archive of the user
+func New(user *models.User, related Related, now time.Time) Archive {
+	archive := Archive{
+		ExportedAt: now,
+		Profile: Profile{
+			ID:                user.ID.Hex(),
+			Email:             user.Email,
+			Active:            user.Active,
+			PasswordChangedAt: optionalTime(user.PasswordChangedAt),
+			CreatedAt:         user.CreatedAt,
+			UpdatedAt:         user.UpdatedAt,
+		},
+		Roles:        append([]string{}, user.Roles...),
+		Memberships:  []Membership{},
+		Groups:       []Group{},
+		Sessions:     []Session{},
+		LoginHistory: []Event{},
+		Activity:     []Event{},
+	}
+
+	organizations := map[primitive.ObjectID]*models.Organization{}
+
+	for _, organization := range related.Organizations {
+		organizations[organization.ID] = organization
+	}
+
+	for _, membership := range user.Memberships {
+		exported := Membership{OrganizationID: membership.OrganizationID.Hex(), Role: membership.Role}
+
+		if organization, ok := organizations[membership.OrganizationID]; ok {
+			exported.OrganizationName = organization.Name
+			exported.OrganizationSlug = organization.Slug
+		}
+
+		archive.Memberships = append(archive.Memberships, exported)
+	}
+
+	for _, group := range related.Groups {
+		archive.Groups = append(archive.Groups, Group{
+			ID:             group.ID.Hex(),
+			OrganizationID: group.OrganizationID.Hex(),
+			Name:           group.Name,
+		})
+	}
+
+	for _, token := range user.RefreshTokens {
+		archive.Sessions = append(archive.Sessions, newSession(token))
+	}
+
+	for _, event := range related.Events {
+		if event.Action == models.AuditLoginSucceeded || event.Action == models.AuditLoginFailed {
+			archive.LoginHistory = append(archive.LoginHistory, newEvent(event))
+		} else {
+			archive.Activity = append(archive.Activity, newEvent(event))
+		}
+	}
+
+	return archive
+}
+
+// newSession describes the refresh token by its claims. The token is only parsed, not validated, since the
+// expired tokens that are still stored are part of the data of the user as well
+func newSession(token models.RefreshToken) Session {
+	session := Session{Identifier: token.Identifier}
+	claims := jsonwebtoken.Claims{}
+
+	if _, _, err := new(jwt.Parser).ParseUnverified(token.Token, &claims); err != nil {
+		return session
+	}
+
+	session.OrganizationID = claims.Organization
+	session.IssuedAt = optionalUnix(claims.IssuedAt)
+	session.ExpiresAt = optionalUnix(claims.ExpiresAt)
+
+	return session
+}
+
+func newEvent(event *models.AuditEvent) Event {
+	exported := Event{
+		Action:    event.Action,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Metadata:  event.Metadata,
+		CreatedAt: event.CreatedAt,
+	}
+
+	if !event.ActorID.IsZero() {
+		exported.ActorID = event.ActorID.Hex()
+	}
+
+	if !event.OrganizationID.IsZero() {
+		exported.OrganizationID = event.OrganizationID.Hex()
+	}
+
+	return exported
+}
+
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+
+	return &value
+}
+
+func optionalUnix(value int64) *time.Time {
+	if value == 0 {
+		return nil
+	}
+
+	return optionalTime(time.Unix(value, 0).UTC())
+}
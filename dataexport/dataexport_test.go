@@ -0,0 +1,95 @@
+package dataexport_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/dataexport"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNew(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	organizationID := primitive.NewObjectID()
+	refreshToken, err := jsonwebtoken.Encode(jsonwebtoken.CreateRefreshTokenClaims("5d470b3e98b0116d7d8ca48c").WithOrganization(organizationID.Hex()))
+
+	if err != nil {
+		t.Fatalf("Error while trying to get the token for test: %v", err)
+	}
+
+	user := &models.User{
+		ID:              primitive.NewObjectID(),
+		Email:           "test@test.com",
+		Password:        "$2a$10$Fl2qgZ7DjYarrymLT6tLle3CqQ.LLdQ/U1E2XCvB6tqFwN4Q5m09a",
+		PasswordHistory: []string{"$2a$10$5eZ9m0Q5m09aFl2qgZ7DjYarrymLT6tLle3CqQ.LLdQ/U1E2XCvB6t"},
+		Roles:           []string{"user"},
+		Active:          true,
+		RefreshTokens:   []models.RefreshToken{{Token: refreshToken, Identifier: "firefox"}},
+		Memberships:     []models.Membership{{OrganizationID: organizationID, Role: models.RoleMember}},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	related := dataexport.Related{
+		Organizations: []*models.Organization{{ID: organizationID, Name: "Acme", Slug: "acme"}},
+		Groups:        []*models.Group{{ID: primitive.NewObjectID(), OrganizationID: organizationID, Name: "engineering"}},
+		Events: []*models.AuditEvent{
+			{Action: models.AuditLoginSucceeded, TargetID: user.ID, IP: "127.0.0.1", CreatedAt: now},
+			{Action: models.AuditLoginFailed, TargetID: user.ID, IP: "10.0.0.1", CreatedAt: now},
+			{Action: models.AuditRolesChanged, ActorID: primitive.NewObjectID(), TargetID: user.ID, CreatedAt: now},
+		},
+	}
+
+	archive := dataexport.New(user, related, now)
+
+	t.Run("Should export the profile, roles, memberships and groups of the user", func(t *testing.T) {
+		require.Equal(t, user.ID.Hex(), archive.Profile.ID)
+		require.Equal(t, "test@test.com", archive.Profile.Email)
+		require.Nil(t, archive.Profile.PasswordChangedAt)
+		require.Equal(t, []string{"user"}, archive.Roles)
+		require.Equal(t, []dataexport.Membership{{
+			OrganizationID:   organizationID.Hex(),
+			OrganizationName: "Acme",
+			OrganizationSlug: "acme",
+			Role:             models.RoleMember,
+		}}, archive.Memberships)
+		require.Equal(t, 1, len(archive.Groups))
+		require.Equal(t, "engineering", archive.Groups[0].Name)
+	})
+
+	t.Run("Should describe the sessions by the claims of the refresh tokens", func(t *testing.T) {
+		require.Equal(t, 1, len(archive.Sessions))
+		require.Equal(t, "firefox", archive.Sessions[0].Identifier)
+		require.Equal(t, organizationID.Hex(), archive.Sessions[0].OrganizationID)
+		require.NotNil(t, archive.Sessions[0].IssuedAt)
+		require.NotNil(t, archive.Sessions[0].ExpiresAt)
+	})
+
+	t.Run("Should split the logins from the other events", func(t *testing.T) {
+		require.Equal(t, 2, len(archive.LoginHistory))
+		require.Equal(t, "127.0.0.1", archive.LoginHistory[0].IP)
+		require.Equal(t, 1, len(archive.Activity))
+		require.Equal(t, models.AuditRolesChanged, archive.Activity[0].Action)
+	})
+
+	t.Run("Should leave the password hashes and the refresh tokens out of the archive", func(t *testing.T) {
+		encoded, err := json.Marshal(archive)
+
+		require.Empty(t, err)
+		require.NotContains(t, string(encoded), user.Password)
+		require.NotContains(t, string(encoded), user.PasswordHistory[0])
+		require.NotContains(t, string(encoded), refreshToken)
+	})
+
+	t.Run("Should keep a session whose token can't be parsed", func(t *testing.T) {
+		user := &models.User{RefreshTokens: []models.RefreshToken{{Token: "invalid", Identifier: "unknown"}}}
+
+		archive := dataexport.New(user, dataexport.Related{}, now)
+
+		require.Equal(t, []dataexport.Session{{Identifier: "unknown"}}, archive.Sessions)
+	})
+}
@@ -0,0 +1,112 @@
+// Package deletion purges the accounts whose deletion was requested by their users, once the grace period
+// in which the deletion can still be cancelled is over
+package deletion
+
+import (
+	"log"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/lockout"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/worker"
+)
+
+// BatchSize is the maximum number of accounts purged on each run
+const BatchSize = 100
+
+// Store finds and removes the accounts scheduled for deletion
+type Store interface {
+	// Due returns up to limit users whose deletion was scheduled to before now
+	Due(now time.Time, limit int) ([]*models.User, error)
+
+	// Purge removes the user and the data related to it, like its sessions, tokens, group memberships and audit
+	// events. It must be safe to call it again for an user whose purge failed halfway
+	Purge(user *models.User) error
+
+	// Record stores the audit event left behind by the purge
+	Record(event models.AuditEvent) error
+}
+
+// GracePeriodFromEnv returns how long an account waits for the deletion, defined by ACCOUNT_DELETION_GRACE_PERIOD
+func GracePeriodFromEnv() time.Duration {
+	return env.Config.AccountDeletionGracePeriod
+}
+
+// Purger removes the accounts whose grace period is over
+type Purger struct {
+	store   Store
+	lockout *lockout.Guard
+	now     func() time.Time
+}
+
+// New creates a Purger that removes the accounts from the store. The failed logins of the purged accounts
+// are cleared from the lockout guard, when it isn't nil
+func New(store Store, guard *lockout.Guard) *Purger {
+	return &Purger{
+		store:   store,
+		lockout: guard,
+		now:     time.Now,
+	}
+}
+
+// Run purges a batch of the accounts whose deletion is due and returns how many were purged. An account
+// that fails to be purged is kept scheduled, so it's tried again on the next run
+func (p *Purger) Run() (int, error) {
+	now := p.now()
+	users, err := p.store.Due(now, BatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, user := range users {
+		if err := p.purge(user, now); err != nil {
+			log.Printf("Error while trying to purge the user with id %s: %v", user.ID.Hex(), err)
+			continue
+		}
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (p *Purger) purge(user *models.User, now time.Time) error {
+	if p.lockout != nil {
+		if err := p.lockout.Unlock(user.Email); err != nil {
+			return err
+		}
+	}
+
+	if err := p.store.Purge(user); err != nil {
+		return err
+	}
+
+	// The account is already gone, so a failure here only loses the trace of the purge
+	if err := p.store.Record(AnonymizedEvent(user, now)); err != nil {
+		log.Printf("Error while trying to record the purge of the user with id %s: %v", user.ID.Hex(), err)
+	}
+
+	return nil
+}
+
+// Start runs the purge on every interval, until the returned function is called
+func (p *Purger) Start(interval time.Duration) (stop func()) {
+	return worker.Start(interval, "purge the deleted accounts", p.Run)
+}
+
+// AnonymizedEvent returns the audit event of the purge of the user. It keeps only the id of the removed account,
+// which doesn't point to anything else anymore, and the dates of the deletion
+func AnonymizedEvent(user *models.User, now time.Time) models.AuditEvent {
+	return models.AuditEvent{
+		Action:   models.AuditUserPurged,
+		TargetID: user.ID,
+		Metadata: map[string]interface{}{
+			"scheduled_at": user.DeletionScheduledAt,
+		},
+		CreatedAt: now,
+	}
+}
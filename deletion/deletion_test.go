@@ -0,0 +1,112 @@
+package deletion_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/deletion"
+	"github.com/LucasFrezarini/go-auth-manager/lockout"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryStore struct {
+	users   []*models.User
+	events  []models.AuditEvent
+	failing map[primitive.ObjectID]bool
+}
+
+func (s *memoryStore) Due(now time.Time, limit int) ([]*models.User, error) {
+	var due []*models.User
+
+	for _, user := range s.users {
+		if !user.DeletionScheduledAt.IsZero() && !user.DeletionScheduledAt.After(now) && len(due) < limit {
+			due = append(due, user)
+		}
+	}
+
+	return due, nil
+}
+
+func (s *memoryStore) Purge(user *models.User) error {
+	if s.failing[user.ID] {
+		return errors.New("database unavailable")
+	}
+
+	for i := range s.users {
+		if s.users[i].ID == user.ID {
+			s.users = append(s.users[:i], s.users[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Record(event models.AuditEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestPurger(t *testing.T) {
+	config := lockout.Config{Threshold: 1, IPThreshold: 10, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+
+	t.Run("Should purge only the accounts whose grace period is over", func(t *testing.T) {
+		due := &models.User{ID: primitive.NewObjectID(), Email: "due@test.com", DeletionScheduledAt: time.Now().Add(-time.Minute)}
+		scheduled := &models.User{ID: primitive.NewObjectID(), Email: "scheduled@test.com", DeletionScheduledAt: time.Now().Add(time.Hour)}
+		kept := &models.User{ID: primitive.NewObjectID(), Email: "kept@test.com"}
+		store := &memoryStore{users: []*models.User{due, scheduled, kept}}
+
+		purged, err := deletion.New(store, nil).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, purged)
+		require.Equal(t, []*models.User{scheduled, kept}, store.users)
+	})
+
+	t.Run("Should leave an anonymized audit event behind", func(t *testing.T) {
+		user := &models.User{ID: primitive.NewObjectID(), Email: "due@test.com", DeletionScheduledAt: time.Now().Add(-time.Minute)}
+		store := &memoryStore{users: []*models.User{user}}
+
+		_, err := deletion.New(store, nil).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, len(store.events))
+		require.Equal(t, models.AuditUserPurged, store.events[0].Action)
+		require.Equal(t, user.ID, store.events[0].TargetID)
+		require.True(t, store.events[0].ActorID.IsZero())
+		require.Empty(t, store.events[0].IP)
+		require.NotContains(t, store.events[0].Metadata, "email")
+	})
+
+	t.Run("Should keep the account scheduled when the purge fails", func(t *testing.T) {
+		failing := &models.User{ID: primitive.NewObjectID(), Email: "failing@test.com", DeletionScheduledAt: time.Now().Add(-time.Minute)}
+		due := &models.User{ID: primitive.NewObjectID(), Email: "due@test.com", DeletionScheduledAt: time.Now().Add(-time.Minute)}
+		store := &memoryStore{users: []*models.User{failing, due}, failing: map[primitive.ObjectID]bool{failing.ID: true}}
+
+		purged, err := deletion.New(store, nil).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, purged)
+		require.Equal(t, []*models.User{failing}, store.users)
+		require.Equal(t, 1, len(store.events))
+	})
+
+	t.Run("Should clear the failed logins of the purged accounts", func(t *testing.T) {
+		user := &models.User{ID: primitive.NewObjectID(), Email: "due@test.com", DeletionScheduledAt: time.Now().Add(-time.Minute)}
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		_, err := guard.Fail("due@test.com", "10.0.0.1")
+		require.NoError(t, err)
+
+		_, err = deletion.New(&memoryStore{users: []*models.User{user}}, guard).Run()
+		require.NoError(t, err)
+
+		locked, err := guard.Check("due@test.com", "10.0.0.2")
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), locked)
+	})
+}
@@ -0,0 +1,28 @@
+#!/bin/bash
+# Runs mongod as a single node replica set, since the transactions of go-auth need a replica set. The members of a
+# replica set with authentication must share a key file, generated here on the first start. The replica set is
+# initiated once mongod accepts connections, announcing the host set on MONGO_REPLICA_SET_HOST. The mongod started by
+# docker-entrypoint.sh to create the root user and run the seeds doesn't join the replica set, so it's retried until
+# the final mongod is up
+set -e
+
+KEY_FILE=/data/db/replica-set.key
+HOST=${MONGO_REPLICA_SET_HOST:-localhost:27017}
+
+if [ ! -f "$KEY_FILE" ]; then
+	head -c 512 /dev/urandom | base64 > "$KEY_FILE"
+fi
+
+chown mongodb:mongodb "$KEY_FILE"
+chmod 400 "$KEY_FILE"
+
+docker-entrypoint.sh mongod --replSet rs0 --keyFile "$KEY_FILE" --bind_ip_all &
+MONGOD_PID=$!
+
+until mongo --quiet admin \
+	-u "$MONGO_INITDB_ROOT_USERNAME" -p "$MONGO_INITDB_ROOT_PASSWORD" \
+	--eval "quit(rs.status().ok || rs.initiate({_id: 'rs0', members: [{_id: 0, host: '$HOST'}]}).ok ? 0 : 1)" > /dev/null 2>&1; do
+	sleep 1
+done
+
+wait $MONGOD_PID
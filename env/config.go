@@ -1,10 +1,150 @@
 package env
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EmailUniquenessGlobal makes an email unique across the whole database
+	EmailUniquenessGlobal = "global"
+
+	// EmailUniquenessTenant makes an email unique only inside each organization
+	EmailUniquenessTenant = "tenant"
+)
 
 type config struct {
-	MongoURI   string
-	ServerHost string
+	MongoURI        string
+	ServerHost      string
+	EmailUniqueness string
+
+	// MongoAllowStandalone lets go-auth run on a standalone mongod, which doesn't support transactions. The events of
+	// a change are then written after it, and lost when the server stops in between
+	MongoAllowStandalone bool
+
+	// PolicyFile is the path of the JSON or YAML file with the access policy rules
+	PolicyFile string
+
+	// GRPCPort is the port of the gRPC server, separated from the HTTP one
+	GRPCPort string
+
+	// TrustProxyHeaders makes the client ip to be read from the X-Forwarded-For header
+	TrustProxyHeaders bool
+
+	// TrustedProxyHops is how many proxies in front of the server append to the X-Forwarded-For header. The client ip
+	// is the entry appended by the outermost of them, since the entries on its left can be faked by the client
+	TrustedProxyHops int
+
+	// AppURL is the address of the frontend, used to build the links sent by email
+	AppURL string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// ForwardAuthCookie is the cookie read by /forward-auth when the request has no Authorization header
+	ForwardAuthCookie string
+
+	// ForwardAuthLoginURL is the login page the reverse proxies are told to redirect to when /forward-auth refuses a request
+	ForwardAuthLoginURL string
+
+	// LockoutThreshold is the number of failed logins of an account before it is locked
+	LockoutThreshold int
+
+	// LockoutIPThreshold is the number of failed logins from an IP before it is locked
+	LockoutIPThreshold int
+
+	// LockoutDuration is how long the first lockout lasts. It doubles on each new failure
+	LockoutDuration time.Duration
+
+	// LockoutMaxDuration is the longest a lockout can last
+	LockoutMaxDuration time.Duration
+
+	// LockoutWindow is how long the failed logins are remembered
+	LockoutWindow time.Duration
+
+	// RateLimits are the limits of the GraphQL operations, like "*=300/1m,login=20/1m"
+	RateLimits string
+
+	// PasswordMinLength is the minimum number of characters of the passwords
+	PasswordMinLength int
+
+	// PasswordMinCharacterClasses is how many character classes the passwords must have
+	PasswordMinCharacterClasses int
+
+	// PasswordBlocklistFile is the path of a file with the passwords that can't be used, one per line
+	PasswordBlocklistFile string
+
+	// PasswordHistorySize is how many previous passwords of each user can't be reused. Zero only refuses the current one
+	PasswordHistorySize int
+
+	// PasswordMaxAge is how long a password can be used before it must be changed. Zero means it never expires
+	PasswordMaxAge time.Duration
+
+	// PasswordHasher is the algorithm of the new password hashes, argon2id or bcrypt
+	PasswordHasher string
+
+	// Argon2Memory is the memory used by argon2id, in KiB
+	Argon2Memory int
+
+	// Argon2MaxMemory is the most memory, in KiB, a stored argon2id hash can use to be verified. The hashes using
+	// more, like an imported hash crafted to exhaust the memory of the server, never match
+	Argon2MaxMemory int
+
+	// Argon2Iterations is the number of passes of argon2id over the memory
+	Argon2Iterations int
+
+	// Argon2Parallelism is the number of threads used by argon2id
+	Argon2Parallelism int
+
+	// BcryptCost is the cost of bcrypt, when it's the password hasher
+	BcryptCost int
+
+	// AccountDeletionGracePeriod is how long the deletion of an account can be cancelled before it's purged
+	AccountDeletionGracePeriod time.Duration
+
+	// AccountPurgeInterval is how often the accounts whose grace period is over are purged
+	AccountPurgeInterval time.Duration
+
+	// Notifier is how the users are warned about the activity on their accounts: mail, or log to only write it on the log
+	Notifier string
+
+	// WebhookMaxAttempts is how many times a webhook delivery is sent before giving up on it
+	WebhookMaxAttempts int
+
+	// WebhookRetryDelay is how long the first retry of a webhook delivery waits. It doubles on each new failure
+	WebhookRetryDelay time.Duration
+
+	// WebhookMaxRetryDelay is the longest a retry of a webhook delivery can wait
+	WebhookMaxRetryDelay time.Duration
+
+	// WebhookTimeout is how long the receivers of the webhooks have to answer
+	WebhookTimeout time.Duration
+
+	// WebhookAllowPrivateNetworks lets the webhooks be sent to loopback, link-local and private addresses
+	WebhookAllowPrivateNetworks bool
+
+	// WebhookDispatchInterval is how often the pending webhook deliveries are sent
+	WebhookDispatchInterval time.Duration
+
+	// OutboxSinks are where the events of the outbox are published, separated by commas: webhook, stdout, or both
+	OutboxSinks string
+
+	// OutboxRetryDelay is how long the first retry of an outbox message waits. It doubles on each new failure
+	OutboxRetryDelay time.Duration
+
+	// OutboxMaxRetryDelay is the longest a retry of an outbox message can wait
+	OutboxMaxRetryDelay time.Duration
+
+	// OutboxLease is how long a message being published is hidden from the other relays
+	OutboxLease time.Duration
+
+	// OutboxRelayInterval is how often the pending outbox messages are published
+	OutboxRelayInterval time.Duration
 }
 
 // Config represents the environment variables this project uses
@@ -21,8 +161,152 @@ func createConfig() config {
 		mongoURI = "mongodb://127.0.0.1:27017"
 	}
 
+	emailUniqueness := os.Getenv("EMAIL_UNIQUENESS")
+
+	if emailUniqueness != EmailUniquenessTenant {
+		emailUniqueness = EmailUniquenessGlobal
+	}
+
+	passwordHasher := os.Getenv("PASSWORD_HASHER")
+
+	if passwordHasher != "bcrypt" {
+		passwordHasher = "argon2id"
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+
+	if smtpPort == "" {
+		smtpPort = "587"
+	}
+
+	grpcPort := os.Getenv("GRPC_PORT")
+
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+
+	forwardAuthCookie := os.Getenv("FORWARD_AUTH_COOKIE")
+
+	if forwardAuthCookie == "" {
+		forwardAuthCookie = "access_token"
+	}
+
+	forwardAuthLoginURL := os.Getenv("FORWARD_AUTH_LOGIN_URL")
+
+	if forwardAuthLoginURL == "" && os.Getenv("APP_URL") != "" {
+		forwardAuthLoginURL = strings.TrimSuffix(os.Getenv("APP_URL"), "/") + "/login"
+	}
+
+	outboxSinks := os.Getenv("OUTBOX_SINKS")
+
+	if outboxSinks == "" {
+		outboxSinks = "webhook"
+	}
+
 	return config{
-		MongoURI:   mongoURI,
-		ServerHost: os.Getenv("SERVER_HOST"),
+		MongoURI:          mongoURI,
+		ServerHost:        os.Getenv("SERVER_HOST"),
+		EmailUniqueness:   emailUniqueness,
+		PolicyFile:        os.Getenv("POLICY_FILE"),
+		GRPCPort:          grpcPort,
+		TrustProxyHeaders: os.Getenv("TRUST_PROXY_HEADERS") == "true",
+		TrustedProxyHops:  intFromEnv("TRUSTED_PROXY_HOPS", 1),
+		AppURL:            os.Getenv("APP_URL"),
+		SMTPHost:          os.Getenv("SMTP_HOST"),
+		SMTPPort:          smtpPort,
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+		MailFrom:          os.Getenv("MAIL_FROM"),
+
+		MongoAllowStandalone: os.Getenv("MONGO_ALLOW_STANDALONE") == "true",
+
+		ForwardAuthCookie:   forwardAuthCookie,
+		ForwardAuthLoginURL: forwardAuthLoginURL,
+
+		LockoutThreshold:   intFromEnv("LOCKOUT_THRESHOLD", 5),
+		LockoutIPThreshold: intFromEnv("LOCKOUT_IP_THRESHOLD", 50),
+		LockoutDuration:    durationFromEnv("LOCKOUT_DURATION", time.Minute),
+		LockoutMaxDuration: durationFromEnv("LOCKOUT_MAX_DURATION", time.Hour),
+		LockoutWindow:      durationFromEnv("LOCKOUT_WINDOW", time.Hour),
+
+		RateLimits: os.Getenv("RATE_LIMITS"),
+
+		PasswordMinLength:           intFromEnv("PASSWORD_MIN_LENGTH", 8),
+		PasswordMinCharacterClasses: intFromEnv("PASSWORD_MIN_CHARACTER_CLASSES", 3),
+		PasswordBlocklistFile:       os.Getenv("PASSWORD_BLOCKLIST_FILE"),
+		PasswordHistorySize:         countFromEnv("PASSWORD_HISTORY_SIZE", 5),
+		PasswordMaxAge:              optionalDurationFromEnv("PASSWORD_MAX_AGE", 0),
+
+		PasswordHasher:    passwordHasher,
+		Argon2Memory:      intFromEnv("ARGON2_MEMORY", 19456),
+		Argon2MaxMemory:   intFromEnv("ARGON2_MAX_MEMORY", 262144),
+		Argon2Iterations:  intFromEnv("ARGON2_ITERATIONS", 2),
+		Argon2Parallelism: intFromEnv("ARGON2_PARALLELISM", 1),
+		BcryptCost:        intFromEnv("BCRYPT_COST", 10),
+
+		AccountDeletionGracePeriod: durationFromEnv("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+		AccountPurgeInterval:       durationFromEnv("ACCOUNT_PURGE_INTERVAL", time.Hour),
+
+		Notifier: os.Getenv("NOTIFIER"),
+
+		WebhookMaxAttempts:      intFromEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryDelay:       durationFromEnv("WEBHOOK_RETRY_DELAY", 30*time.Second),
+		WebhookMaxRetryDelay:    durationFromEnv("WEBHOOK_MAX_RETRY_DELAY", time.Hour),
+		WebhookTimeout:          durationFromEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookDispatchInterval: durationFromEnv("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second),
+
+		WebhookAllowPrivateNetworks: os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS") == "true",
+
+		OutboxSinks:         outboxSinks,
+		OutboxRetryDelay:    durationFromEnv("OUTBOX_RETRY_DELAY", 5*time.Second),
+		OutboxMaxRetryDelay: durationFromEnv("OUTBOX_MAX_RETRY_DELAY", 10*time.Minute),
+		OutboxLease:         durationFromEnv("OUTBOX_LEASE", time.Minute),
+		OutboxRelayInterval: durationFromEnv("OUTBOX_RELAY_INTERVAL", time.Second),
+	}
+}
+
+// intFromEnv returns the positive integer defined on the env variable, or the fallback if it isn't valid
+func intFromEnv(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// countFromEnv returns the integer defined on the env variable, which can be zero to turn the setting off, or the
+// fallback if it isn't valid
+func countFromEnv(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+
+	if err != nil || value < 0 {
+		return fallback
 	}
+
+	return value
+}
+
+// durationFromEnv returns the positive duration defined on the env variable, like 15m, or the fallback if it isn't valid
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// optionalDurationFromEnv returns the duration defined on the env variable, which can be zero to turn the setting
+// off, or the fallback if it isn't valid
+func optionalDurationFromEnv(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+
+	if err != nil || value < 0 {
+		return fallback
+	}
+
+	return value
 }
@@ -3,6 +3,7 @@ package env
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -29,4 +30,65 @@ func TestConfig(t *testing.T) {
 		require.Equal(t, "mongodb://mongo_host:27017", config.MongoURI)
 		require.Equal(t, "http://unit.test.io", config.ServerHost)
 	})
+
+	t.Run("Should use global as the default EMAIL_UNIQUENESS", func(t *testing.T) {
+		os.Setenv("EMAIL_UNIQUENESS", "")
+
+		config := createConfig()
+
+		require.Equal(t, EmailUniquenessGlobal, config.EmailUniqueness)
+	})
+
+	t.Run("Should allow the email uniqueness to be defined per tenant", func(t *testing.T) {
+		os.Setenv("EMAIL_UNIQUENESS", "tenant")
+
+		config := createConfig()
+
+		require.Equal(t, EmailUniquenessTenant, config.EmailUniqueness)
+	})
+
+	t.Run("Should use the default lockout config when it isn't valid", func(t *testing.T) {
+		os.Setenv("LOCKOUT_THRESHOLD", "none")
+		os.Setenv("LOCKOUT_DURATION", "-1m")
+
+		config := createConfig()
+
+		require.Equal(t, 5, config.LockoutThreshold)
+		require.Equal(t, time.Minute, config.LockoutDuration)
+	})
+
+	t.Run("Should define the lockout config by env params", func(t *testing.T) {
+		os.Setenv("LOCKOUT_THRESHOLD", "3")
+		os.Setenv("LOCKOUT_DURATION", "30s")
+
+		config := createConfig()
+
+		require.Equal(t, 3, config.LockoutThreshold)
+		require.Equal(t, 30*time.Second, config.LockoutDuration)
+	})
+	t.Run("Should allow the password history and the password max age to be turned off", func(t *testing.T) {
+		os.Setenv("PASSWORD_HISTORY_SIZE", "0")
+		os.Setenv("PASSWORD_MAX_AGE", "0")
+
+		config := createConfig()
+
+		require.Equal(t, 0, config.PasswordHistorySize)
+		require.Equal(t, time.Duration(0), config.PasswordMaxAge)
+	})
+
+	t.Run("Should use the default password history size when it's negative", func(t *testing.T) {
+		os.Setenv("PASSWORD_HISTORY_SIZE", "-1")
+
+		config := createConfig()
+
+		require.Equal(t, 5, config.PasswordHistorySize)
+	})
+
+	t.Run("Should trust a single proxy hop by default", func(t *testing.T) {
+		os.Setenv("TRUSTED_PROXY_HOPS", "")
+
+		config := createConfig()
+
+		require.Equal(t, 1, config.TrustedProxyHops)
+	})
 }
@@ -6,6 +6,7 @@ require (
 	github.com/99designs/gqlgen v0.9.1
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/go-stack/stack v1.8.0 // indirect
+	github.com/golang/protobuf v1.3.2
 	github.com/golang/snappy v0.0.1 // indirect
 	github.com/google/go-cmp v0.3.0 // indirect
 	github.com/joho/godotenv v1.3.0
@@ -17,4 +18,6 @@ require (
 	go.mongodb.org/mongo-driver v1.0.4
 	golang.org/x/crypto v0.0.0-20190701094942-4def268fd1a4
 	golang.org/x/text v0.3.2 // indirect
+	google.golang.org/grpc v1.24.0
+	gopkg.in/yaml.v2 v2.2.2
 )
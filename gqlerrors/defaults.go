@@ -1,6 +1,11 @@
 package gqlerrors
 
-import "github.com/vektah/gqlparser/gqlerror"
+import (
+	"math"
+	"time"
+
+	"github.com/vektah/gqlparser/gqlerror"
+)
 
 // CreateInternalServerError creates a default GraphQL internal server error from a request
 func CreateInternalServerError(message string) *gqlerror.Error {
@@ -31,3 +36,66 @@ func CreateConflictError(message string) *gqlerror.Error {
 		},
 	}
 }
+
+// CreateForbiddenError creates a default GraphQL forbidden error from a request
+func CreateForbiddenError() *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: "Forbidden",
+		Extensions: map[string]interface{}{
+			"code": Forbidden,
+		},
+	}
+}
+
+// CreateNotFoundError creates a default GraphQL not found error from a request
+func CreateNotFoundError(message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code": NotFound,
+		},
+	}
+}
+
+// CreateBadUserInputError creates a default GraphQL bad user input error from a request
+func CreateBadUserInputError(message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code": BadUserInput,
+		},
+	}
+}
+
+// CreateAccountLockedError creates a default GraphQL account locked error, with the seconds until the login is allowed again
+func CreateAccountLockedError(retryAfter time.Duration) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: "Too many failed login attempts, try again later",
+		Extensions: map[string]interface{}{
+			"code":       AccountLocked,
+			"retryAfter": int(math.Ceil(retryAfter.Seconds())),
+		},
+	}
+}
+
+// CreateRateLimitedError creates a default GraphQL rate limited error, with the seconds until a new request is allowed
+func CreateRateLimitedError(retryAfter time.Duration) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: "Too many requests, try again later",
+		Extensions: map[string]interface{}{
+			"code":       RateLimited,
+			"retryAfter": int(math.Ceil(retryAfter.Seconds())),
+		},
+	}
+}
+
+// CreatePasswordExpiredError creates a default GraphQL password expired error, carrying a token that can only be used to change the password
+func CreatePasswordExpiredError(token string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: "The password expired and must be changed",
+		Extensions: map[string]interface{}{
+			"code":  PasswordExpired,
+			"token": token,
+		},
+	}
+}
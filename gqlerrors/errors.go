@@ -8,3 +8,21 @@ const Unauthorized = "UNAUTHORIZED"
 
 // Conflict defines the error code from an conflicted request
 const Conflict = "CONFLICT"
+
+// Forbidden defines the error code from an authenticated request without enough permissions
+const Forbidden = "FORBIDDEN"
+
+// NotFound defines the error code from a request to a resource that doesn't exist
+const NotFound = "NOT_FOUND"
+
+// BadUserInput defines the error code from a request with invalid input
+const BadUserInput = "BAD_USER_INPUT"
+
+// AccountLocked defines the error code from a login refused because of too many failed attempts
+const AccountLocked = "ACCOUNT_LOCKED"
+
+// RateLimited defines the error code from a request refused because the client exceeded the rate limit
+const RateLimited = "RATE_LIMITED"
+
+// PasswordExpired defines the error code from a login with a password that must be changed
+const PasswordExpired = "PASSWORD_EXPIRED"
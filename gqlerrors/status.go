@@ -0,0 +1,40 @@
+package gqlerrors
+
+import (
+	"net/http"
+
+	"github.com/vektah/gqlparser/gqlerror"
+)
+
+var statuses = map[string]int{
+	Internal:        http.StatusInternalServerError,
+	Unauthorized:    http.StatusUnauthorized,
+	Conflict:        http.StatusConflict,
+	Forbidden:       http.StatusForbidden,
+	NotFound:        http.StatusNotFound,
+	BadUserInput:    http.StatusBadRequest,
+	AccountLocked:   http.StatusTooManyRequests,
+	RateLimited:     http.StatusTooManyRequests,
+	PasswordExpired: http.StatusForbidden,
+}
+
+// HTTPStatus returns the HTTP status code matching the code of the error, used by the REST endpoints.
+// The unknown codes are internal server errors
+func HTTPStatus(code string) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Code returns the code on the extensions of the error, or Internal when the error doesn't have one
+func Code(err error) string {
+	if e, ok := err.(*gqlerror.Error); ok {
+		if code, ok := e.Extensions["code"].(string); ok {
+			return code
+		}
+	}
+
+	return Internal
+}
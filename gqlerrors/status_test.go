@@ -0,0 +1,27 @@
+package gqlerrors_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	t.Run("Should map the codes of the errors to the HTTP status codes", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, gqlerrors.HTTPStatus(gqlerrors.Code(gqlerrors.CreateAuthorizationError())))
+		require.Equal(t, http.StatusForbidden, gqlerrors.HTTPStatus(gqlerrors.Code(gqlerrors.CreateForbiddenError())))
+		require.Equal(t, http.StatusNotFound, gqlerrors.HTTPStatus(gqlerrors.Code(gqlerrors.CreateNotFoundError("User not found"))))
+		require.Equal(t, http.StatusConflict, gqlerrors.HTTPStatus(gqlerrors.Code(gqlerrors.CreateConflictError("Email already in use"))))
+		require.Equal(t, http.StatusBadRequest, gqlerrors.HTTPStatus(gqlerrors.Code(gqlerrors.CreateBadUserInputError("Invalid email"))))
+		require.Equal(t, http.StatusTooManyRequests, gqlerrors.HTTPStatus(gqlerrors.Code(gqlerrors.CreateAccountLockedError(time.Minute))))
+	})
+
+	t.Run("Should treat the errors without a known code as internal server errors", func(t *testing.T) {
+		require.Equal(t, gqlerrors.Internal, gqlerrors.Code(errors.New("connection refused")))
+		require.Equal(t, http.StatusInternalServerError, gqlerrors.HTTPStatus("UNKNOWN"))
+	})
+}
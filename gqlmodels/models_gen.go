@@ -3,34 +3,237 @@
 package gqlmodels
 
 import (
+	"fmt"
+	"io"
+	"strconv"
+
 	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
 	"github.com/LucasFrezarini/go-auth-manager/models"
 )
 
+type AdminUpdateUserInput struct {
+	Email *string  `json:"email"`
+	Roles []string `json:"roles"`
+}
+
+type AuditEventConnection struct {
+	Edges      []*AuditEventEdge `json:"edges"`
+	PageInfo   *PageInfo         `json:"pageInfo"`
+	TotalCount int               `json:"totalCount"`
+}
+
+type AuditEventEdge struct {
+	Cursor string             `json:"cursor"`
+	Node   *models.AuditEvent `json:"node"`
+}
+
+type AuditEventFilter struct {
+	Action        *string `json:"action"`
+	ActorID       *string `json:"actorId"`
+	TargetID      *string `json:"targetId"`
+	IP            *string `json:"ip"`
+	CreatedAfter  *string `json:"createdAfter"`
+	CreatedBefore *string `json:"createdBefore"`
+}
+
 type AuthUserPayload struct {
 	User         *models.User `json:"user"`
 	Token        string       `json:"token"`
 	RefreshToken string       `json:"refreshToken"`
 }
 
+type CreateOrganizationInput struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
 type CreateUserInput struct {
-	Email    string   `json:"email"`
-	Password string   `json:"password"`
-	Roles    []string `json:"roles"`
-	Active   *bool    `json:"active"`
+	Email        string   `json:"email"`
+	Password     string   `json:"password"`
+	Roles        []string `json:"roles"`
+	Active       *bool    `json:"active"`
+	Organization *string  `json:"organization"`
+}
+
+type CreateWebhookSubscriptionInput struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
 }
 
 type LoginUserInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email        string  `json:"email"`
+	Password     string  `json:"password"`
+	Organization *string `json:"organization"`
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
 }
 
 type UpdateUserInput struct {
 	Password string `json:"password"`
 }
 
+type UpdateWebhookSubscriptionInput struct {
+	URL        *string  `json:"url"`
+	Secret     *string  `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+	Active     *bool    `json:"active"`
+}
+
+type UserConnection struct {
+	Edges      []*UserEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type UserEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *models.User `json:"node"`
+}
+
+type UserFilter struct {
+	EmailPrefix   *string `json:"emailPrefix"`
+	Role          *string `json:"role"`
+	Active        *bool   `json:"active"`
+	CreatedAfter  *string `json:"createdAfter"`
+	CreatedBefore *string `json:"createdBefore"`
+}
+
+type UserSort struct {
+	Field     UserSortField `json:"field"`
+	Direction SortDirection `json:"direction"`
+}
+
 type ValidateTokenPayload struct {
 	Claims *jsonwebtoken.Claims `json:"claims"`
 	User   *models.User         `json:"user"`
 	Valid  bool                 `json:"valid"`
 }
+
+type ImportFormat string
+
+const (
+	ImportFormatJSON ImportFormat = "JSON"
+	ImportFormatCsv  ImportFormat = "CSV"
+)
+
+var AllImportFormat = []ImportFormat{
+	ImportFormatJSON,
+	ImportFormatCsv,
+}
+
+func (e ImportFormat) IsValid() bool {
+	switch e {
+	case ImportFormatJSON, ImportFormatCsv:
+		return true
+	}
+	return false
+}
+
+func (e ImportFormat) String() string {
+	return string(e)
+}
+
+func (e *ImportFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportFormat", str)
+	}
+	return nil
+}
+
+func (e ImportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type UserSortField string
+
+const (
+	UserSortFieldCreatedAt UserSortField = "CREATED_AT"
+	UserSortFieldEmail     UserSortField = "EMAIL"
+)
+
+var AllUserSortField = []UserSortField{
+	UserSortFieldCreatedAt,
+	UserSortFieldEmail,
+}
+
+func (e UserSortField) IsValid() bool {
+	switch e {
+	case UserSortFieldCreatedAt, UserSortFieldEmail:
+		return true
+	}
+	return false
+}
+
+func (e UserSortField) String() string {
+	return string(e)
+}
+
+func (e *UserSortField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserSortField", str)
+	}
+	return nil
+}
+
+func (e UserSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,218 @@
+// Package grpcserver serves the gRPC API of go-auth, used by the other services to validate the tokens, look up the
+// users and check their permissions on every request, without the overhead of GraphQL over HTTP/1.1
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/LucasFrezarini/go-auth-manager/authpb"
+	"github.com/LucasFrezarini/go-auth-manager/credentials"
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the name of the Auth service, used on the health checks
+const ServiceName = "goauth.v1.Auth"
+
+var userDao = dao.UserDao{}
+
+// Server implements the Auth service
+type Server struct {
+	// Policy evaluates the access rules used by CheckPermission
+	Policy *policy.Engine
+}
+
+// New returns a gRPC server with the Auth service, the health checks and the reflection, so tools like grpcurl can
+// list and call the methods
+func New(policy *policy.Engine) *grpc.Server {
+	server := grpc.NewServer()
+	authpb.RegisterAuthServer(server, &Server{Policy: policy})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server
+}
+
+// ListenAndServe serves the gRPC API on the port, returning only when the server stops
+func ListenAndServe(port string, policy *policy.Engine) error {
+	listener, err := net.Listen("tcp", ":"+port)
+
+	if err != nil {
+		return err
+	}
+
+	return New(policy).Serve(listener)
+}
+
+// ValidateToken checks the token like the validateToken mutation. An invalid token is answered with valid = false
+func (s *Server) ValidateToken(ctx context.Context, request *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
+	claims, user, err := authenticate(request.Token)
+
+	if err != nil {
+		return &authpb.ValidateTokenResponse{Valid: false}, nil
+	}
+
+	return &authpb.ValidateTokenResponse{
+		Valid:  true,
+		Claims: newClaims(claims),
+		User:   newUser(user),
+	}, nil
+}
+
+// GetUser returns the user with the id. Like the user query, the caller can get itself, and the admins of an
+// organization can get its members. The sysadmins can get any user
+func (s *Server) GetUser(ctx context.Context, request *authpb.GetUserRequest) (*authpb.User, error) {
+	claims, caller, err := authenticate(authorization(ctx))
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+
+	id, err := primitive.ObjectIDFromHex(request.Id)
+
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid user id")
+	}
+
+	if id == caller.ID {
+		return newUser(caller), nil
+	}
+
+	organizationID, _ := primitive.ObjectIDFromHex(claims.Organization)
+
+	if !caller.IsOrganizationAdmin(organizationID) {
+		return nil, status.Error(codes.PermissionDenied, "Forbidden")
+	}
+
+	user, err := userDao.FindByID(id)
+
+	if err != nil || (!caller.HasRole(models.RoleSysadmin) && user.MembershipOf(organizationID) == nil) {
+		return nil, status.Error(codes.NotFound, "User not found")
+	}
+
+	return newUser(user), nil
+}
+
+// CheckPermission evaluates the access policy for the user of the token, like the /authorize endpoint
+func (s *Server) CheckPermission(ctx context.Context, request *authpb.CheckPermissionRequest) (*authpb.CheckPermissionResponse, error) {
+	claims, user, err := authenticate(request.Token)
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+
+	if request.Action == "" || request.Resource == "" {
+		return nil, status.Error(codes.InvalidArgument, "The action and resource are required")
+	}
+
+	attributes := map[string]interface{}{}
+
+	for key, value := range request.Context {
+		attributes[key] = value
+	}
+
+	decision := s.Policy.Evaluate(policy.NewInput(request.Action, request.Resource, user, claims, requestAttributes(ctx), attributes))
+
+	return &authpb.CheckPermissionResponse{
+		Allowed: decision.Allowed,
+		Rule:    decision.RuleID,
+		Reason:  decision.Reason,
+	}, nil
+}
+
+// authenticate decodes the token and validates its credentials. The restricted tokens can't be used to
+// authenticate on other services
+func authenticate(token string) (jsonwebtoken.Claims, *models.User, error) {
+	claims, err := jsonwebtoken.Decode(token)
+
+	if err != nil {
+		return claims, nil, err
+	}
+
+	if claims.Restricted() {
+		return claims, nil, errors.New("The token is restricted")
+	}
+
+	user, err := credentials.ValidateCredentials(claims)
+
+	return claims, user, err
+}
+
+// authorization returns the token of the caller, sent on the authorization metadata
+func authorization(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get("authorization"); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// requestAttributes returns the attributes of the call available to the policy rules, like on the HTTP requests
+func requestAttributes(ctx context.Context) map[string]interface{} {
+	attributes := map[string]interface{}{}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			attributes["ip"] = host
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get("user-agent"); len(values) > 0 {
+		attributes["userAgent"] = values[0]
+	}
+
+	return attributes
+}
+
+func newClaims(claims jsonwebtoken.Claims) *authpb.Claims {
+	return &authpb.Claims{
+		Subject:      claims.Subject,
+		Issuer:       claims.Issuer,
+		Organization: claims.Organization,
+		Groups:       claims.Groups,
+		IssuedAt:     claims.IssuedAt,
+		ExpiresAt:    claims.ExpiresAt,
+	}
+}
+
+func newUser(user *models.User) *authpb.User {
+	memberships := []*authpb.Membership{}
+
+	for _, membership := range user.Memberships {
+		memberships = append(memberships, &authpb.Membership{
+			OrganizationId: membership.OrganizationID.Hex(),
+			Role:           membership.Role,
+		})
+	}
+
+	return &authpb.User{
+		Id:          user.ID.Hex(),
+		Email:       user.Email,
+		Roles:       user.Roles,
+		Active:      user.Active,
+		Memberships: memberships,
+		CreatedAt:   user.CreatedAt.Unix(),
+		UpdatedAt:   user.UpdatedAt.Unix(),
+	}
+}
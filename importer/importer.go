@@ -0,0 +1,133 @@
+// Package importer imports the users exported by other identity providers, like Auth0 and Django, keeping their
+// password hashes so they can login with their existing passwords
+package importer
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store creates the imported users
+type Store interface {
+	// ImportUser creates the user, recording the events in the same transaction, and returns models.ErrDuplicateEmail
+	// when its email collides with the unique email index
+	ImportUser(user models.User, events ...models.AuditEvent) error
+}
+
+// Failure is a user of the export that wasn't imported
+type Failure struct {
+	// Record is the position of the user on the export, starting at 1
+	Record int    `json:"record"`
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
+}
+
+// Result is the report of an import
+type Result struct {
+	Imported int `json:"imported"`
+
+	// Duplicates are the emails that collide with the users already registered, or with other users of the export
+	Duplicates []string `json:"duplicates"`
+
+	Failures []Failure `json:"failures"`
+}
+
+// Options defines how the users are imported
+type Options struct {
+	// OrganizationID is the organization the users become members of. The users aren't added to any organization when it's empty
+	OrganizationID primitive.ObjectID
+
+	// AllowRoles decides if the roles of an user can be imported. Every role is allowed when it's nil
+	AllowRoles func(roles []string) bool
+
+	// Event returns the event recorded along with each imported user, like its user.created audit event. No event is
+	// recorded when it's nil
+	Event func(user models.User) models.AuditEvent
+}
+
+// ImportFrom parses the export on the format and imports its users on the store
+func ImportFrom(store Store, reader io.Reader, format string, options Options) (*Result, error) {
+	records, failures, err := Parse(reader, format)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Import(store, records, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.Failures = append(failures, result.Failures...)
+
+	return result, nil
+}
+
+// Import creates the users of the records on the store. The records are imported one by one, so a duplicate or an invalid
+// record doesn't stop the others from being imported. The error is only returned when the database fails
+func Import(store Store, records []Record, options Options) (*Result, error) {
+	result := &Result{Duplicates: []string{}, Failures: []Failure{}}
+
+	for _, record := range records {
+		if !crypt.SupportedHash(record.PasswordHash) {
+			result.Failures = append(result.Failures, Failure{Record: record.Position, Email: record.Email, Reason: "Unsupported password hash"})
+			continue
+		}
+
+		if options.AllowRoles != nil && !options.AllowRoles(record.Roles) {
+			result.Failures = append(result.Failures, Failure{Record: record.Position, Email: record.Email, Reason: "The roles can't be imported"})
+			continue
+		}
+
+		now := time.Now()
+		user := models.User{
+			ID:                primitive.NewObjectID(),
+			Email:             record.Email,
+			Password:          record.PasswordHash,
+			PasswordChangedAt: now,
+			Roles:             record.Roles,
+			Active:            record.Active,
+			CreatedAt:         record.CreatedAt,
+			UpdatedAt:         now,
+		}
+
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = now
+		}
+
+		if !options.OrganizationID.IsZero() {
+			user.Memberships = []models.Membership{{
+				OrganizationID: options.OrganizationID,
+				Role:           models.RoleMember,
+				Managed:        true,
+			}}
+		}
+
+		events := []models.AuditEvent{}
+
+		if options.Event != nil {
+			events = append(events, options.Event(user))
+		}
+
+		err := store.ImportUser(user, events...)
+
+		if err == models.ErrDuplicateEmail {
+			result.Duplicates = append(result.Duplicates, record.Email)
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Error while trying to import the user %d: %v", record.Position, err)
+		}
+
+		result.Imported++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/importer"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore keeps the imported users and their events, enforcing the unique email like the database
+type memoryStore struct {
+	users  map[string]models.User
+	events []models.AuditEvent
+}
+
+func (s *memoryStore) ImportUser(user models.User, events ...models.AuditEvent) error {
+	if _, ok := s.users[user.Email]; ok {
+		return models.ErrDuplicateEmail
+	}
+
+	s.users[user.Email] = user
+	s.events = append(s.events, events...)
+
+	return nil
+}
+
+const bcryptHash = "$2a$10$Fl2qgZ7DjYarrymLT6tLle3CqQ.LLdQ/U1E2XCvB6tqFwN4Q5m09a"
+
+func TestImport(t *testing.T) {
+	t.Run("Should import the users keeping their password hashes", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{}}
+		csv := "email,password_hash\njohn@test.com," + bcryptHash + "\n"
+
+		result, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Imported)
+		require.Equal(t, bcryptHash, store.users["john@test.com"].Password)
+		require.True(t, store.users["john@test.com"].Active)
+		require.Empty(t, store.users["john@test.com"].Memberships)
+	})
+
+	t.Run("Should report the duplicated emails", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{"john@test.com": {}}}
+		csv := "email,password_hash\njohn@test.com," + bcryptHash + "\nmary@test.com," + bcryptHash + "\nmary@test.com," + bcryptHash + "\n"
+
+		result, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Imported)
+		require.Equal(t, []string{"john@test.com", "mary@test.com"}, result.Duplicates)
+	})
+
+	t.Run("Should not import unsupported password hashes", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{}}
+		csv := "email,password_hash\njohn@test.com,md5$salt$hash\n"
+
+		result, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{})
+
+		require.NoError(t, err)
+		require.Equal(t, 0, result.Imported)
+		require.Equal(t, []importer.Failure{{Record: 1, Email: "john@test.com", Reason: "Unsupported password hash"}}, result.Failures)
+	})
+
+	t.Run("Should not import malformed password hashes of a supported algorithm", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{}}
+		csv := "email,password_hash\njohn@test.com,$2a$10$Fl2qgZ7DjYarrymLT6tLle\n"
+
+		result, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{})
+
+		require.NoError(t, err)
+		require.Equal(t, 0, result.Imported)
+		require.Equal(t, "Unsupported password hash", result.Failures[0].Reason)
+	})
+
+	t.Run("Should record the event of each imported user", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{"john@test.com": {}}}
+		csv := "email,password_hash\njohn@test.com," + bcryptHash + "\nmary@test.com," + bcryptHash + "\n"
+
+		_, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{
+			Event: func(user models.User) models.AuditEvent {
+				return models.AuditEvent{Action: models.AuditUserCreated, TargetID: user.ID}
+			},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, len(store.events))
+		require.Equal(t, models.AuditUserCreated, store.events[0].Action)
+		require.Equal(t, store.users["mary@test.com"].ID, store.events[0].TargetID)
+		require.False(t, store.events[0].TargetID.IsZero())
+	})
+
+	t.Run("Should not import the roles that aren't allowed", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{}}
+		csv := "email,password_hash,roles\njohn@test.com," + bcryptHash + ",sysadmin\nmary@test.com," + bcryptHash + ",user\n"
+
+		result, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{
+			AllowRoles: func(roles []string) bool {
+				return !(&models.User{Roles: roles}).HasRole(models.RoleSysadmin)
+			},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Imported)
+		require.Equal(t, "The roles can't be imported", result.Failures[0].Reason)
+	})
+
+	t.Run("Should add the users to the organization as managed members", func(t *testing.T) {
+		store := &memoryStore{users: map[string]models.User{}}
+		organizationID := primitive.NewObjectID()
+		csv := "email,password_hash\njohn@test.com," + bcryptHash + "\n"
+
+		_, err := importer.ImportFrom(store, strings.NewReader(csv), importer.FormatCSV, importer.Options{OrganizationID: organizationID})
+
+		require.NoError(t, err)
+		require.Equal(t, []models.Membership{{OrganizationID: organizationID, Role: models.RoleMember, Managed: true}}, store.users["john@test.com"].Memberships)
+	})
+}
@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatJSON is a JSON array of users, or one JSON user per line, like the exports of Auth0 and the dumpdata of Django
+	FormatJSON = "json"
+
+	// FormatCSV is a CSV file with a header row naming the fields of the users
+	FormatCSV = "csv"
+)
+
+// The names each field of the user may have on the exports
+var (
+	emailFields     = []string{"email"}
+	passwordFields  = []string{"password_hash", "passwordHash", "password"}
+	rolesFields     = []string{"roles"}
+	activeFields    = []string{"active", "is_active"}
+	blockedFields   = []string{"blocked"}
+	createdAtFields = []string{"created_at", "createdAt", "date_joined"}
+)
+
+// dateLayouts are the layouts of the creation dates accepted, the last one is the default of Django without time zone
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+
+// Record is a user read from an export
+type Record struct {
+	// Position is the position of the user on the export, starting at 1
+	Position int
+
+	Email        string
+	PasswordHash string
+	Roles        []string
+	Active       bool
+	CreatedAt    time.Time
+}
+
+// Parse reads the users of an export on the format. The users that can't be read are returned as failures,
+// while the error is only returned when the export itself can't be read
+func Parse(reader io.Reader, format string) ([]Record, []Failure, error) {
+	var entries []map[string]interface{}
+	var err error
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		entries, err = readJSON(reader)
+	case FormatCSV:
+		entries, err = readCSV(reader)
+	default:
+		return nil, nil, fmt.Errorf("Unsupported import format <%s>", format)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := []Record{}
+	failures := []Failure{}
+
+	for i, entry := range entries {
+		record, err := recordFromFields(entry)
+		record.Position = i + 1
+
+		if err != nil {
+			failures = append(failures, Failure{Record: i + 1, Email: record.Email, Reason: err.Error()})
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	return records, failures, nil
+}
+
+func readJSON(reader io.Reader) ([]map[string]interface{}, error) {
+	content, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to read the import: %v", err)
+	}
+
+	entries := []map[string]interface{}{}
+
+	if content = bytes.TrimSpace(content); bytes.HasPrefix(content, []byte("[")) {
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return nil, fmt.Errorf("Error while trying to parse the import: %v", err)
+		}
+
+		return entries, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	for decoder.More() {
+		entry := map[string]interface{}{}
+
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("Error while trying to parse the user %d of the import: %v", len(entries)+1, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func readCSV(reader io.Reader) ([]map[string]interface{}, error) {
+	rows, err := csv.NewReader(reader).ReadAll()
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to parse the import: %v", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("Error while trying to parse the import: the header is missing")
+	}
+
+	header := rows[0]
+	entries := make([]map[string]interface{}, 0, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		entry := map[string]interface{}{}
+
+		for i, name := range header {
+			if i < len(row) && row[i] != "" {
+				entry[strings.TrimSpace(name)] = row[i]
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// recordFromFields reads the user from the fields of an entry. The fields of the users exported by the dumpdata of
+// Django are nested on the "fields" object
+func recordFromFields(fields map[string]interface{}) (Record, error) {
+	if nested, ok := fields["fields"].(map[string]interface{}); ok {
+		fields = nested
+	}
+
+	record := Record{
+		Email:        strings.TrimSpace(stringField(fields, emailFields)),
+		PasswordHash: stringField(fields, passwordFields),
+		Roles:        rolesField(fields),
+		Active:       true,
+	}
+
+	if record.Email == "" || !strings.Contains(record.Email, "@") {
+		return record, fmt.Errorf("Invalid email")
+	}
+
+	if record.PasswordHash == "" {
+		return record, fmt.Errorf("The password hash is missing")
+	}
+
+	if active, ok := boolField(fields, activeFields); ok {
+		record.Active = active
+	}
+
+	if blocked, ok := boolField(fields, blockedFields); ok && blocked {
+		record.Active = false
+	}
+
+	if createdAt := stringField(fields, createdAtFields); createdAt != "" {
+		date, err := parseDate(createdAt)
+
+		if err != nil {
+			return record, err
+		}
+
+		record.CreatedAt = date
+	}
+
+	return record, nil
+}
+
+func stringField(fields map[string]interface{}, names []string) string {
+	for _, name := range names {
+		if value, ok := fields[name].(string); ok && value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func boolField(fields map[string]interface{}, names []string) (bool, bool) {
+	for _, name := range names {
+		switch value := fields[name].(type) {
+		case bool:
+			return value, true
+		case string:
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				return parsed, true
+			}
+		}
+	}
+
+	return false, false
+}
+
+// rolesField reads the roles from a JSON array, or from a string with the roles separated by semicolons, used on CSV
+func rolesField(fields map[string]interface{}) []string {
+	roles := []string{}
+
+	for _, name := range rolesFields {
+		switch value := fields[name].(type) {
+		case []interface{}:
+			for _, role := range value {
+				if role, ok := role.(string); ok && strings.TrimSpace(role) != "" {
+					roles = append(roles, strings.TrimSpace(role))
+				}
+			}
+		case string:
+			for _, role := range strings.Split(value, ";") {
+				if strings.TrimSpace(role) != "" {
+					roles = append(roles, strings.TrimSpace(role))
+				}
+			}
+		}
+	}
+
+	if len(roles) == 0 {
+		return []string{"user"}
+	}
+
+	return roles
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid creation date <%s>", value)
+}
@@ -0,0 +1,79 @@
+package importer_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/importer"
+	"github.com/stretchr/testify/require"
+)
+
+func parseFile(t *testing.T, path, format string) ([]importer.Record, []importer.Failure) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		t.Fatalf("Error while trying to open the import: %v", err)
+	}
+
+	defer file.Close()
+
+	records, failures, err := importer.Parse(file, format)
+
+	require.NoError(t, err)
+
+	return records, failures
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Should parse the users exported by the dumpdata of Django", func(t *testing.T) {
+		records, failures := parseFile(t, "testdata/django.json", importer.FormatJSON)
+
+		require.Equal(t, 1, len(records))
+		require.Equal(t, "john@django.com", records[0].Email)
+		require.True(t, strings.HasPrefix(records[0].PasswordHash, "pbkdf2_sha256$"))
+		require.True(t, records[0].Active)
+		require.Equal(t, []string{"user"}, records[0].Roles)
+		require.Equal(t, time.Date(2019, 8, 7, 0, 58, 7, 162000000, time.UTC), records[0].CreatedAt)
+
+		require.Equal(t, []importer.Failure{{Record: 2, Reason: "Invalid email"}}, failures)
+	})
+
+	t.Run("Should parse the users exported by Auth0, one per line", func(t *testing.T) {
+		records, failures := parseFile(t, "testdata/auth0.json", importer.FormatJSON)
+
+		require.Equal(t, 0, len(failures))
+		require.Equal(t, 2, len(records))
+		require.Equal(t, "jane@auth0.com", records[0].Email)
+		require.True(t, records[0].Active)
+		require.Equal(t, "blocked@auth0.com", records[1].Email)
+		require.False(t, records[1].Active)
+	})
+
+	t.Run("Should parse the users of a CSV", func(t *testing.T) {
+		records, failures := parseFile(t, "testdata/users.csv", importer.FormatCSV)
+
+		require.Equal(t, 1, len(records))
+		require.Equal(t, "csv@test.com", records[0].Email)
+		require.Equal(t, []string{"user", "billing"}, records[0].Roles)
+		require.Equal(t, 1, records[0].Position)
+
+		require.Equal(t, 1, len(failures))
+		require.Equal(t, 2, failures[0].Record)
+		require.Equal(t, "nohash@test.com", failures[0].Email)
+		require.Equal(t, "The password hash is missing", failures[0].Reason)
+	})
+
+	t.Run("Should return error for an unsupported format", func(t *testing.T) {
+		_, _, err := importer.Parse(strings.NewReader(""), "xml")
+
+		require.Error(t, err)
+	})
+
+	t.Run("Should return error for an invalid JSON", func(t *testing.T) {
+		_, _, err := importer.Parse(strings.NewReader("[{"), importer.FormatJSON)
+
+		require.Error(t, err)
+	})
+}
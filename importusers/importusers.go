@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/importer"
+)
+
+// Imports the users exported by other identity providers, printing the report of the import:
+//
+//	go run importusers/importusers.go -file users.json [-format json|csv] [-organization slug]
+func main() {
+	path := flag.String("file", "", "the JSON or CSV export with the users")
+	format := flag.String("format", "", "the format of the export, json or csv. Detected from the file extension by default")
+	organization := flag.String("organization", "", "the slug of the organization the users become members of")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*path)), ".")
+	}
+
+	options := importer.Options{}
+
+	if *organization != "" {
+		organizationDao := dao.OrganizationDao{}
+		found, err := organizationDao.FindBySlug(*organization)
+
+		if err != nil {
+			log.Fatalf("Error while trying to find the organization %s: %v", *organization, err)
+		}
+
+		options.OrganizationID = found.ID
+	}
+
+	file, err := os.Open(*path)
+
+	if err != nil {
+		log.Fatalf("Error while trying to open the export: %v", err)
+	}
+
+	defer file.Close()
+
+	result, err := importer.ImportFrom(&dao.UserDao{}, file, *format, options)
+
+	if err != nil {
+		log.Fatalf("Error while trying to import the users: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("Error while trying to write the report: %v", err)
+	}
+}
@@ -9,6 +9,15 @@ import (
 
 // Claims represents the claims that can be passed on the creation of jsonwebtoken
 type Claims struct {
+	// Organization is the id of the organization (tenant) the token was issued for
+	Organization string `json:"org,omitempty"`
+
+	// Groups are the names of the groups the subject belongs to inside the organization, including the nested ones
+	Groups []string `json:"groups,omitempty"`
+
+	// Scope restricts what the token can be used for. Tokens without a scope aren't restricted
+	Scope string `json:"scope,omitempty"`
+
 	jwt.StandardClaims
 }
 
@@ -18,6 +27,21 @@ const (
 
 	// RefreshTokenLifetimeInMonths represents the lifetime, in months, of an refresh token
 	RefreshTokenLifetimeInMonths = 1
+
+	// PasswordChangeTokenLifetime represents the lifetime of a token that can only be used to change an expired password
+	PasswordChangeTokenLifetime = time.Minute * 10
+
+	// ScopePasswordChange is the scope of the tokens that can only be used to change an expired password
+	ScopePasswordChange = "password_change"
+
+	// RevokeSessionsTokenLifetime represents the lifetime of a token sent on the "this wasn't me" links
+	RevokeSessionsTokenLifetime = time.Hour * 24 * 7
+
+	// ScopeRevokeSessions is the scope of the tokens that can only be used to revoke all the sessions of the subject
+	ScopeRevokeSessions = "revoke_sessions"
+
+	// MaxGroupsClaim represents the maximum number of groups carried by a token, to keep its size bounded
+	MaxGroupsClaim = 50
 )
 
 // CreateDefaultClaims returns an default claims object for an subject
@@ -38,6 +62,48 @@ func createCommonClains(subject string) Claims {
 	}
 }
 
+// WithOrganization returns a copy of the claims scoped to the organization
+func (c Claims) WithOrganization(organizationID string) Claims {
+	c.Organization = organizationID
+
+	return c
+}
+
+// WithGroups returns a copy of the claims with the groups of the subject, limited to MaxGroupsClaim groups
+func (c Claims) WithGroups(groups []string) Claims {
+	if len(groups) > MaxGroupsClaim {
+		groups = groups[:MaxGroupsClaim]
+	}
+
+	c.Groups = groups
+
+	return c
+}
+
+// CreatePasswordChangeClaims returns a claims object for a subject whose password expired, which can only be used to change it
+func CreatePasswordChangeClaims(subject string) (claims Claims) {
+	claims = createCommonClains(subject)
+	claims.Scope = ScopePasswordChange
+	claims.ExpiresAt = time.Now().UTC().Add(PasswordChangeTokenLifetime).Unix()
+
+	return
+}
+
+// CreateRevokeSessionsClaims returns a claims object for a subject that reports a login it doesn't recognize,
+// which can only be used to revoke all of its sessions
+func CreateRevokeSessionsClaims(subject string) (claims Claims) {
+	claims = createCommonClains(subject)
+	claims.Scope = ScopeRevokeSessions
+	claims.ExpiresAt = time.Now().UTC().Add(RevokeSessionsTokenLifetime).Unix()
+
+	return
+}
+
+// Restricted returns true if the token can only be used for its scope
+func (c Claims) Restricted() bool {
+	return c.Scope != ""
+}
+
 // CreateRefreshTokenClaims returns a claims object for an subject for an refresh token
 func CreateRefreshTokenClaims(subject string) (claims Claims) {
 	claims = createCommonClains(subject)
@@ -1,6 +1,7 @@
 package jsonwebtoken_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -47,3 +48,70 @@ func TestCreateRefreshTokenClaims(t *testing.T) {
 		require.NotEmpty(t, claims.IssuedAt)
 	})
 }
+
+func TestWithOrganization(t *testing.T) {
+	t.Run("Should scope the claims to the organization passed as parameter", func(t *testing.T) {
+		claims := jsonwebtoken.CreateDefaultClaims("321").WithOrganization("5d6e9d1b1c9d440000a1b2c3")
+
+		require.Equal(t, "321", claims.Subject)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", claims.Organization)
+	})
+
+	t.Run("Should keep the organization claim after encoding the token", func(t *testing.T) {
+		token, err := jsonwebtoken.Encode(jsonwebtoken.CreateDefaultClaims("321").WithOrganization("5d6e9d1b1c9d440000a1b2c3"))
+
+		require.Empty(t, err)
+
+		claims, err := jsonwebtoken.Decode(token)
+
+		require.Empty(t, err)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", claims.Organization)
+	})
+}
+
+func TestWithGroups(t *testing.T) {
+	t.Run("Should add the groups passed as parameter", func(t *testing.T) {
+		claims := jsonwebtoken.CreateDefaultClaims("321").WithGroups([]string{"engineering", "on-call"})
+
+		require.Equal(t, []string{"engineering", "on-call"}, claims.Groups)
+	})
+
+	t.Run("Should limit the number of groups on the claims", func(t *testing.T) {
+		groups := make([]string, jsonwebtoken.MaxGroupsClaim+10)
+
+		for i := range groups {
+			groups[i] = fmt.Sprintf("group-%d", i)
+		}
+
+		claims := jsonwebtoken.CreateDefaultClaims("321").WithGroups(groups)
+
+		require.Len(t, claims.Groups, jsonwebtoken.MaxGroupsClaim)
+		require.Equal(t, "group-0", claims.Groups[0])
+	})
+}
+
+func TestCreatePasswordChangeClaims(t *testing.T) {
+	t.Run("Should create restricted claims to change the password", func(t *testing.T) {
+		claims := jsonwebtoken.CreatePasswordChangeClaims("321")
+
+		require.Equal(t, "321", claims.Subject)
+		require.Equal(t, jsonwebtoken.ScopePasswordChange, claims.Scope)
+		require.True(t, claims.Restricted())
+		require.Equal(t, time.Now().UTC().Add(10*time.Minute).Unix(), claims.ExpiresAt)
+	})
+
+	t.Run("Should not restrict the default claims", func(t *testing.T) {
+		require.False(t, jsonwebtoken.CreateDefaultClaims("321").Restricted())
+	})
+}
+
+func TestCreateRevokeSessionsClaims(t *testing.T) {
+	t.Run("Should create restricted claims to revoke the sessions", func(t *testing.T) {
+		claims := jsonwebtoken.CreateRevokeSessionsClaims("321")
+
+		require.Equal(t, "321", claims.Subject)
+		require.Equal(t, jsonwebtoken.ScopeRevokeSessions, claims.Scope)
+		require.True(t, claims.Restricted())
+		require.Equal(t, time.Now().UTC().Add(7*24*time.Hour).Unix(), claims.ExpiresAt)
+	})
+}
@@ -0,0 +1,191 @@
+// Package lockout protects the login against brute-force attacks, counting the failed attempts
+// per account and per IP and locking them out for an exponentially growing time after a threshold
+package lockout
+
+import (
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+// Store keeps the failed attempts. Implementations must be safe for concurrent use and must
+// ignore the attempts which ExpiresAt is before the time received
+type Store interface {
+	// Get returns the attempts of the key, or zero attempts if there isn't any
+	Get(key string, now time.Time) (models.LoginAttempts, error)
+
+	// Increment adds a failure to the key, keeping it until expiresAt, and returns the updated attempts
+	Increment(key string, now, expiresAt time.Time) (models.LoginAttempts, error)
+
+	// Lock locks the key until the time received, keeping it until expiresAt
+	Lock(key string, until, expiresAt time.Time) error
+
+	// Reset removes the attempts of the key
+	Reset(key string) error
+}
+
+// Config defines when the accounts and the IPs are locked out
+type Config struct {
+	// Threshold is the number of failures of an account before it is locked
+	Threshold int
+
+	// IPThreshold is the number of failures coming from an IP before it is locked
+	IPThreshold int
+
+	// BaseDelay is how long the first lockout lasts. It doubles on each failure after the threshold
+	BaseDelay time.Duration
+
+	// MaxDelay is the longest a lockout can last
+	MaxDelay time.Duration
+
+	// Window is how long the failures are remembered after the last one
+	Window time.Duration
+}
+
+// ConfigFromEnv returns the config defined by the LOCKOUT_* environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		Threshold:   env.Config.LockoutThreshold,
+		IPThreshold: env.Config.LockoutIPThreshold,
+		BaseDelay:   env.Config.LockoutDuration,
+		MaxDelay:    env.Config.LockoutMaxDuration,
+		Window:      env.Config.LockoutWindow,
+	}
+}
+
+// Guard tracks the login attempts and tells when they must be refused
+type Guard struct {
+	store  Store
+	config Config
+	now    func() time.Time
+}
+
+// New creates a Guard that keeps the attempts in the store
+func New(store Store, config Config) *Guard {
+	return &Guard{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Check returns for how long the login with the email from the ip is still locked, or zero if it's allowed
+func (g *Guard) Check(email, ip string) (time.Duration, error) {
+	now := g.now()
+	var remaining time.Duration
+
+	for _, key := range g.keys(email, ip) {
+		attempts, err := g.store.Get(key.name, now)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if wait := attempts.LockedUntil.Sub(now); wait > remaining {
+			remaining = wait
+		}
+	}
+
+	return remaining, nil
+}
+
+// Fail registers a failed login with the email from the ip, and returns for how long
+// the login is locked after it, or zero if the threshold wasn't reached yet
+func (g *Guard) Fail(email, ip string) (time.Duration, error) {
+	now := g.now()
+	var locked time.Duration
+
+	for _, key := range g.keys(email, ip) {
+		attempts, err := g.store.Increment(key.name, now, now.Add(g.config.Window))
+
+		if err != nil {
+			return 0, err
+		}
+
+		if attempts.Failures < key.threshold {
+			continue
+		}
+
+		delay := g.delay(attempts.Failures - key.threshold)
+		until := now.Add(delay)
+
+		if err := g.store.Lock(key.name, until, until.Add(g.config.Window)); err != nil {
+			return 0, err
+		}
+
+		if delay > locked {
+			locked = delay
+		}
+	}
+
+	return locked, nil
+}
+
+// Succeed clears the failures of the account after a successful login. The failures of the IP are kept,
+// so a valid account can't be used to reset the counter of an IP guessing the passwords of others
+func (g *Guard) Succeed(email string) error {
+	return g.store.Reset(accountKey(email))
+}
+
+// Unlock clears the failures and the lockout of the account and of the IPs received, which are meant to be the
+// IPs its logins failed from. The counters of the IPs are shared by every account, so the failures of the other
+// accounts from those IPs are cleared too
+func (g *Guard) Unlock(email string, ips ...string) error {
+	if err := g.store.Reset(accountKey(email)); err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		if err := g.store.Reset(ipKey(ip)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ActiveSince returns the time of the oldest failure that can still be counted: a key is kept for the window after
+// its last failure, or after its lockout, which lasts up to the max delay
+func (g *Guard) ActiveSince() time.Time {
+	return g.now().Add(-g.config.Window - g.config.MaxDelay)
+}
+
+// delay returns the lockout duration after the number of failures beyond the threshold
+func (g *Guard) delay(exceeded int) time.Duration {
+	delay := g.config.BaseDelay
+
+	for i := 0; i < exceeded && delay < g.config.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > g.config.MaxDelay {
+		return g.config.MaxDelay
+	}
+
+	return delay
+}
+
+type key struct {
+	name      string
+	threshold int
+}
+
+func (g *Guard) keys(email, ip string) []key {
+	keys := []key{{name: accountKey(email), threshold: g.config.Threshold}}
+
+	if ip != "" {
+		keys = append(keys, key{name: ipKey(ip), threshold: g.config.IPThreshold})
+	}
+
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,157 @@
+package lockout_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/lockout"
+	"github.com/stretchr/testify/require"
+)
+
+var config = lockout.Config{
+	Threshold:   3,
+	IPThreshold: 5,
+	BaseDelay:   time.Minute,
+	MaxDelay:    5 * time.Minute,
+	Window:      time.Hour,
+}
+
+func fail(t *testing.T, guard *lockout.Guard, email, ip string, times int) time.Duration {
+	var locked time.Duration
+	var err error
+
+	for i := 0; i < times; i++ {
+		locked, err = guard.Fail(email, ip)
+		require.NoError(t, err)
+	}
+
+	return locked
+}
+
+func TestGuard(t *testing.T) {
+	t.Run("Should allow the login before the threshold", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		require.Equal(t, time.Duration(0), fail(t, guard, "test@test.com", "10.0.0.1", 2))
+
+		remaining, err := guard.Check("test@test.com", "10.0.0.1")
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), remaining)
+	})
+
+	t.Run("Should lock the account after the threshold", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		require.Equal(t, time.Minute, fail(t, guard, "test@test.com", "10.0.0.1", 3))
+
+		remaining, err := guard.Check("TEST@test.com", "10.0.0.2")
+
+		require.NoError(t, err)
+		require.True(t, remaining > 0 && remaining <= time.Minute)
+	})
+
+	t.Run("Should double the lockout on each failure after the threshold, up to the max delay", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		fail(t, guard, "test@test.com", "", 3)
+
+		require.Equal(t, 2*time.Minute, fail(t, guard, "test@test.com", "", 1))
+		require.Equal(t, 4*time.Minute, fail(t, guard, "test@test.com", "", 1))
+		require.Equal(t, 5*time.Minute, fail(t, guard, "test@test.com", "", 1))
+	})
+
+	t.Run("Should lock the IP after its threshold, even for other accounts", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		for _, email := range []string{"a@test.com", "b@test.com", "c@test.com", "d@test.com"} {
+			fail(t, guard, email, "10.0.0.1", 1)
+		}
+
+		require.Equal(t, time.Minute, fail(t, guard, "e@test.com", "10.0.0.1", 1))
+
+		remaining, err := guard.Check("f@test.com", "10.0.0.1")
+
+		require.NoError(t, err)
+		require.True(t, remaining > 0)
+
+		remaining, err = guard.Check("f@test.com", "10.0.0.2")
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), remaining)
+	})
+
+	t.Run("Should clear the failures of the account after a successful login", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		fail(t, guard, "test@test.com", "10.0.0.1", 2)
+		require.NoError(t, guard.Succeed("test@test.com"))
+
+		require.Equal(t, time.Duration(0), fail(t, guard, "test@test.com", "10.0.0.1", 2))
+	})
+
+	t.Run("Should unlock a locked account", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		fail(t, guard, "test@test.com", "", 3)
+		require.NoError(t, guard.Unlock("test@test.com"))
+
+		remaining, err := guard.Check("test@test.com", "")
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), remaining)
+	})
+
+	t.Run("Should unlock the IPs received with the account", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		fail(t, guard, "test@test.com", "10.0.0.1", 5)
+		require.NoError(t, guard.Unlock("test@test.com", "10.0.0.1"))
+
+		remaining, err := guard.Check("test@test.com", "10.0.0.1")
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), remaining)
+	})
+
+	t.Run("Should keep the IP locked when only the account is unlocked", func(t *testing.T) {
+		guard := lockout.New(lockout.NewMemoryStore(), config)
+
+		fail(t, guard, "test@test.com", "10.0.0.1", 5)
+		require.NoError(t, guard.Unlock("test@test.com"))
+
+		remaining, err := guard.Check("test@test.com", "10.0.0.1")
+
+		require.NoError(t, err)
+		require.True(t, remaining > 0)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Should forget the attempts after they expire", func(t *testing.T) {
+		store := lockout.NewMemoryStore()
+
+		_, err := store.Increment("key", now, now.Add(time.Minute))
+		require.NoError(t, err)
+
+		attempts, err := store.Get("key", now.Add(30*time.Second))
+		require.NoError(t, err)
+		require.Equal(t, 1, attempts.Failures)
+
+		attempts, err = store.Get("key", now.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 0, attempts.Failures)
+	})
+
+	t.Run("Should start counting again after the attempts expire", func(t *testing.T) {
+		store := lockout.NewMemoryStore()
+
+		store.Increment("key", now, now.Add(time.Minute))
+		attempts, err := store.Increment("key", now.Add(2*time.Minute), now.Add(3*time.Minute))
+
+		require.NoError(t, err)
+		require.Equal(t, 1, attempts.Failures)
+	})
+}
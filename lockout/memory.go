@@ -0,0 +1,83 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+// MemoryStore keeps the attempts in memory. It's used on the tests, and it can be used
+// when there's only one instance of the server running
+type MemoryStore struct {
+	mutex    sync.Mutex
+	attempts map[string]models.LoginAttempts
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: map[string]models.LoginAttempts{}}
+}
+
+// Get returns the attempts of the key, or zero attempts if there isn't any
+func (s *MemoryStore) Get(key string, now time.Time) (models.LoginAttempts, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.get(key, now), nil
+}
+
+// Increment adds a failure to the key, keeping it until expiresAt, and returns the updated attempts
+func (s *MemoryStore) Increment(key string, now, expiresAt time.Time) (models.LoginAttempts, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	attempts := s.get(key, now)
+	attempts.Failures++
+
+	if expiresAt.After(attempts.ExpiresAt) {
+		attempts.ExpiresAt = expiresAt
+	}
+
+	s.attempts[key] = attempts
+
+	return attempts, nil
+}
+
+// Lock locks the key until the time received, keeping it until expiresAt
+func (s *MemoryStore) Lock(key string, until, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	attempts := s.attempts[key]
+	attempts.LockedUntil = until
+
+	if expiresAt.After(attempts.ExpiresAt) {
+		attempts.ExpiresAt = expiresAt
+	}
+
+	s.attempts[key] = attempts
+
+	return nil
+}
+
+// Reset removes the attempts of the key
+func (s *MemoryStore) Reset(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
+
+func (s *MemoryStore) get(key string, now time.Time) models.LoginAttempts {
+	attempts, ok := s.attempts[key]
+
+	if !ok || !attempts.ExpiresAt.After(now) {
+		delete(s.attempts, key)
+		return models.LoginAttempts{}
+	}
+
+	return attempts
+}
@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+)
+
+// Message represents an email to be delivered
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails. Implementations must be safe for concurrent use
+type Mailer interface {
+	Send(message Message) error
+}
+
+// New returns the mailer configured by the environment: an SMTP mailer when SMTP_HOST is defined,
+// or a mailer that only logs the messages otherwise
+func New() Mailer {
+	if env.Config.SMTPHost == "" {
+		return &LogMailer{}
+	}
+
+	return &SMTPMailer{
+		Host:     env.Config.SMTPHost,
+		Port:     env.Config.SMTPPort,
+		Username: env.Config.SMTPUsername,
+		Password: env.Config.SMTPPassword,
+		From:     env.Config.MailFrom,
+	}
+}
+
+// LogMailer is a mailer used on development that only writes the messages on the log
+type LogMailer struct{}
+
+// Send writes the message on the log
+func (m *LogMailer) Send(message Message) error {
+	log.Printf("Mail to <%s>: %s\n%s", message.To, message.Subject, message.Body)
+
+	return nil
+}
+
+// SMTPMailer delivers the messages through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the message through the SMTP server
+func (m *SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	// Removes line breaks from the header values, so they can't be used to inject headers
+	sanitize := strings.NewReplacer("\r", "", "\n", "").Replace
+
+	headers := []string{
+		"From: " + sanitize(m.From),
+		"To: " + sanitize(message.To),
+		"Subject: " + sanitize(message.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body
+
+	err := smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{message.To}, []byte(body))
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to send mail to <%s>: %v", message.To, err)
+	}
+
+	return nil
+}
+
+// MemoryMailer keeps the messages in memory instead of delivering them. Useful on tests
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Send stores the message
+func (m *MemoryMailer) Send(message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var loadPolicyOnce sync.Once
+var loadedPolicy *policy.Engine
+
+// accessPolicy returns the policy engine for the rules of the POLICY_FILE, loaded once
+func accessPolicy() *policy.Engine {
+	loadPolicyOnce.Do(func() {
+		var err error
+
+		loadedPolicy, err = policy.FromEnv()
+
+		if err != nil {
+			log.Panicf("Error while loading the access policy: %v", err)
+		}
+	})
+
+	return loadedPolicy
+}
+
+type authorizeRequest struct {
+	Action   string                 `json:"action"`
+	Resource string                 `json:"resource"`
+	Context  map[string]interface{} `json:"context"`
+}
+
+type authorizeResponse struct {
+	Allowed bool   `json:"allowed"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// MakeAuthorizeHandler returns the REST handler that evaluates the access policy for the authenticated user.
+// It answers 200 when the access is allowed and 403 when it's denied
+func MakeAuthorizeHandler() http.Handler {
+	return RequestLogger(RequestInfo(AuthHandler(http.HandlerFunc(authorize))))
+}
+
+func authorize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	claims, ok := r.Context().Value("claims").(jsonwebtoken.Claims)
+
+	if !ok || claims.Restricted() {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	body := authorizeRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Action == "" || body.Resource == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "The action and resource are required"})
+		return
+	}
+
+	userID, _ := primitive.ObjectIDFromHex(claims.Subject)
+	user, err := userDao.FindByID(userID)
+
+	if err != nil {
+		log.Printf("Error while trying to authorize: %v", err)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	decision := accessPolicy().Evaluate(policy.NewInput(body.Action, body.Resource, user, claims, policy.RequestAttributes(r.Context()), body.Context))
+
+	status := http.StatusOK
+
+	if !decision.Allowed {
+		status = http.StatusForbidden
+	}
+
+	writeJSON(w, status, authorizeResponse{
+		Allowed: decision.Allowed,
+		Rule:    decision.RuleID,
+		Reason:  decision.Reason,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Error while trying to write the response: %v", err)
+	}
+}
@@ -15,16 +15,30 @@ func AuthHandler(next http.Handler) http.Handler {
 		if authorization == "" {
 			next.ServeHTTP(w, r)
 		} else {
-			claims, err := jsonwebtoken.Decode(authorization)
-
-			_, err = credentials.ValidateCredentials(claims)
+			ctx, err := authenticate(r.Context(), authorization)
 
 			if err != nil {
 				next.ServeHTTP(w, r)
 			} else {
-				ctx := context.WithValue(r.Context(), "userID", claims.Subject)
 				next.ServeHTTP(w, r.WithContext(ctx))
 			}
 		}
 	})
 }
+
+// authenticate validates the token and returns the context with its claims, like the AuthHandler does
+func authenticate(ctx context.Context, authorization string) (context.Context, error) {
+	claims, err := jsonwebtoken.Decode(authorization)
+
+	_, err = credentials.ValidateCredentials(claims)
+
+	if err != nil {
+		return ctx, err
+	}
+
+	ctx = context.WithValue(ctx, "userID", claims.Subject)
+	ctx = context.WithValue(ctx, "organizationID", claims.Organization)
+	ctx = context.WithValue(ctx, "claims", claims)
+
+	return ctx, nil
+}
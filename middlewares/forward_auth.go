@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/credentials"
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+type forwardAuthRefusal struct {
+	Error    string `json:"error"`
+	LoginURL string `json:"loginUrl,omitempty"`
+}
+
+// MakeForwardAuthHandler returns the handler of /forward-auth, which lets the reverse proxies use go-auth as the gate
+// of other apps, with the auth_request of nginx or the ForwardAuth of Traefik. It answers 200 with the user on the
+// X-User-Id, X-User-Email and X-User-Roles headers, for the proxy to pass them to the app, or 401 with the login page
+// on the X-Login-URL header, which nginx can redirect to with auth_request_set and error_page
+func MakeForwardAuthHandler() http.Handler {
+	return RequestLogger(RequestInfo(http.HandlerFunc(forwardAuth)))
+}
+
+func forwardAuth(w http.ResponseWriter, r *http.Request) {
+	user, claims, err := forwardAuthUser(r)
+
+	if err != nil {
+		loginURL := forwardAuthLoginURL(r)
+
+		if loginURL != "" {
+			w.Header().Set("X-Login-URL", loginURL)
+		}
+
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, forwardAuthRefusal{Error: "Unauthorized", LoginURL: loginURL})
+		return
+	}
+
+	w.Header().Set("X-User-Id", user.ID.Hex())
+	w.Header().Set("X-User-Email", user.Email)
+	w.Header().Set("X-User-Roles", strings.Join(user.Roles, ","))
+
+	if claims.Organization != "" {
+		w.Header().Set("X-User-Organization", claims.Organization)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// forwardAuthUser returns the owner of the token of the request. The token is read from the Authorization header,
+// with or without the Bearer prefix, or from the FORWARD_AUTH_COOKIE cookie for the requests made by the browsers
+func forwardAuthUser(r *http.Request) (*models.User, jsonwebtoken.Claims, error) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+
+	if token == "" {
+		if cookie, err := r.Cookie(env.Config.ForwardAuthCookie); err == nil {
+			token = cookie.Value
+		}
+	}
+
+	claims, err := jsonwebtoken.Decode(token)
+
+	if err != nil {
+		return nil, claims, err
+	}
+
+	if claims.Restricted() {
+		return nil, claims, errors.New("The token is restricted")
+	}
+
+	user, err := credentials.ValidateCredentials(claims)
+
+	if err != nil {
+		return nil, claims, err
+	}
+
+	return user, claims, nil
+}
+
+// forwardAuthLoginURL returns the FORWARD_AUTH_LOGIN_URL with the address the user tried to open on the redirect
+// parameter, so the login page can send the user back. The address is sent by nginx on the X-Original-URL header
+// and by Traefik on the X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Uri headers
+func forwardAuthLoginURL(r *http.Request) string {
+	loginURL := env.Config.ForwardAuthLoginURL
+
+	if loginURL == "" {
+		return ""
+	}
+
+	original := r.Header.Get("X-Original-URL")
+
+	if original == "" && r.Header.Get("X-Forwarded-Host") != "" {
+		proto := r.Header.Get("X-Forwarded-Proto")
+
+		if proto == "" {
+			proto = "http"
+		}
+
+		original = proto + "://" + r.Header.Get("X-Forwarded-Host") + r.Header.Get("X-Forwarded-Uri")
+	}
+
+	parsed, err := url.Parse(loginURL)
+
+	if original == "" || err != nil {
+		return loginURL
+	}
+
+	query := parsed.Query()
+	query.Set("redirect", original)
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
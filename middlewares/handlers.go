@@ -2,34 +2,118 @@ package middlewares
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/handler"
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/deletion"
 	"github.com/LucasFrezarini/go-auth-manager/generated"
 	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/lockout"
+	"github.com/LucasFrezarini/go-auth-manager/mailer"
+	"github.com/LucasFrezarini/go-auth-manager/notifier"
+	"github.com/LucasFrezarini/go-auth-manager/password"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
+	"github.com/LucasFrezarini/go-auth-manager/ratelimit"
 	"github.com/LucasFrezarini/go-auth-manager/resolvers"
 	"github.com/vektah/gqlparser/gqlerror"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// MakeHandlers returns the handlers used by server
-func MakeHandlers() http.Handler {
-	return RequestLogger(AuthHandler(
-		handler.GraphQL(makeExecutableSchema(), makeErrorPresenter())))
+var userDao = dao.UserDao{}
+
+// Events feeds the GraphQL subscriptions. The server publishes the events of the outbox to it. Since it's kept in
+// memory, a server running many instances must replace it by a distributed PubSub before making the handlers
+var Events pubsub.PubSub = pubsub.NewMemory()
+
+// Services are shared by the GraphQL and the REST handlers, so both APIs count the requests of a client on the
+// same rate limits and call the same resolver
+type Services struct {
+	Resolver *resolvers.Resolver
+	Limiter  *ratelimit.Limiter
+}
+
+// NewServices creates the services defined by the environment. The server creates them once for all its handlers
+func NewServices() *Services {
+	return &Services{
+		Resolver: makeResolver(),
+		Limiter:  rateLimiter(),
+	}
+}
+
+// MakeHandlers returns the handlers used by server. The subscriptions are served over websockets
+func MakeHandlers(services *Services) http.Handler {
+	return RequestLogger(RequestInfo(AuthHandler(RateLimit(services.Limiter,
+		handler.GraphQL(makeExecutableSchema(services.Resolver), makeErrorPresenter(), handler.WebsocketInitFunc(websocketInit))))))
+}
+
+func makeExecutableSchema(resolver *resolvers.Resolver) graphql.ExecutableSchema {
+	c := generated.Config{}
+	c.Directives.IsAuthenticated = isAuthenticated
+	c.Directives.IsAdmin = isAdmin
+	c.Resolvers = &subscriptionRoot{Resolver: resolver, directives: c.Directives}
+
+	return generated.NewExecutableSchema(c)
 }
 
-func makeExecutableSchema() graphql.ExecutableSchema {
-	c := generated.Config{Resolvers: &resolvers.Resolver{}}
-	c.Directives.IsAuthenticated = func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
-		userID := ctx.Value("userID")
-		if userID != nil {
-			return next(ctx)
-		}
+// makeResolver returns the root resolver with the dependencies defined by the environment
+func makeResolver() *resolvers.Resolver {
+	mail := mailer.New()
 
+	return &resolvers.Resolver{
+		Mailer:   mail,
+		Notifier: notifier.New(mail),
+		Policy:   accessPolicy(),
+		Lockout:  lockout.New(&dao.LoginAttemptDao{}, lockout.ConfigFromEnv()),
+
+		PasswordPolicy: passwordPolicy(),
+
+		DeletionGracePeriod: deletion.GracePeriodFromEnv(),
+
+		Events: Events,
+	}
+}
+
+// isAuthenticated is the @isAuthenticated directive, which requires the request to be authenticated
+func isAuthenticated(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	userID := ctx.Value("userID")
+	if userID == nil {
 		return nil, gqlerrors.CreateAuthorizationError()
 	}
 
-	return generated.NewExecutableSchema(c)
+	if !restrictedTokenAllows(ctx) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	return next(ctx)
+}
+
+// isAdmin is the @isAdmin directive, which requires the user to be an admin of the organization of the token
+func isAdmin(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	userID, err := primitive.ObjectIDFromHex(fmt.Sprintf("%v", ctx.Value("userID")))
+	if err != nil {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	user, err := userDao.FindByID(userID)
+	if err != nil {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	if !restrictedTokenAllows(ctx) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	organizationID, _ := primitive.ObjectIDFromHex(fmt.Sprintf("%v", ctx.Value("organizationID")))
+	if user.IsOrganizationAdmin(organizationID) {
+		return next(ctx)
+	}
+
+	return nil, gqlerrors.CreateForbiddenError()
 }
 
 func makeErrorPresenter() handler.Option {
@@ -49,3 +133,29 @@ func makeErrorPresenter() handler.Option {
 		},
 	)
 }
+
+// restrictedTokenAllows checks if the token of the request can be used on the field being resolved.
+// The tokens issued to change an expired password can only be used by the updateUser mutation
+func restrictedTokenAllows(ctx context.Context) bool {
+	claims, _ := ctx.Value("claims").(jsonwebtoken.Claims)
+
+	if !claims.Restricted() {
+		return true
+	}
+
+	resolverContext := graphql.GetResolverContext(ctx)
+
+	return claims.Scope == jsonwebtoken.ScopePasswordChange && resolverContext != nil &&
+		resolverContext.Object == "Mutation" && resolverContext.Field.Name == "updateUser"
+}
+
+// passwordPolicy returns the password policy defined by the environment, panicking if the blocklist can't be read
+func passwordPolicy() *password.Policy {
+	policy, err := password.PolicyFromEnv()
+
+	if err != nil {
+		log.Panicf("Error while trying to load the password policy: %v", err)
+	}
+
+	return policy
+}
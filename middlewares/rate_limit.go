@@ -0,0 +1,168 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/ratelimit"
+	"github.com/vektah/gqlparser/ast"
+	"github.com/vektah/gqlparser/gqlerror"
+	"github.com/vektah/gqlparser/parser"
+)
+
+// RateLimit is a middleware that refuses the GraphQL requests of the clients that exceeded the limit of any of
+// the operations called. The limits are counted per ip and, on authenticated requests, per user, and a request
+// calling an operation several times, through aliases, costs a token per call
+func RateLimit(limiter *ratelimit.Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operations, err := graphQLOperations(r)
+
+		if err != nil {
+			log.Printf("Error while trying to read the request: %v", err)
+			http.Error(w, "Error while trying to read the request", http.StatusBadRequest)
+			return
+		}
+
+		wait, err := limiter.AllowAll(operations, rateLimitClients(r)...)
+
+		// Waiting doesn't help a request calling an operation more times than its limit, so it isn't told to retry
+		if err == ratelimit.ErrCostTooHigh {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []*gqlerror.Error{gqlerrors.CreateBadUserInputError("The request calls the operations more times than their rate limits allow")},
+			})
+
+			return
+		}
+
+		if err != nil {
+			log.Printf("Error while trying to check the rate limit: %v", err)
+		}
+
+		if wait > 0 {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []*gqlerror.Error{gqlerrors.CreateRateLimitedError(wait)},
+			})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitClients returns the keys the request is counted on: its ip and, when it's authenticated, its user
+func rateLimitClients(r *http.Request) []string {
+	clients := []string{"ip:" + clientIP(r)}
+
+	if userID := r.Context().Value("userID"); userID != nil {
+		clients = append(clients, fmt.Sprintf("user:%v", userID))
+	}
+
+	return clients
+}
+
+// rateLimiter returns the limiter with the limits defined by the RATE_LIMITS env variable
+func rateLimiter() *ratelimit.Limiter {
+	value := env.Config.RateLimits
+
+	if value == "" {
+		value = ratelimit.DefaultLimits
+	}
+
+	limits, err := ratelimit.ParseLimits(value)
+
+	if err != nil {
+		log.Panicf("Error while trying to parse the rate limits: %v", err)
+	}
+
+	return ratelimit.New(ratelimit.NewMemoryStore(), limits)
+}
+
+// graphQLOperations returns the names of the root fields of the operation executed by the request, like login,
+// once per call: a field is called once for each of its aliases, while the selections sharing the same alias are
+// merged into a single call, even when they come from different fragments. The body is restored, so it can still
+// be read by the GraphQL handler. Requests that can't be parsed are counted on the default limit, since they are
+// refused by the GraphQL handler anyway
+func graphQLOperations(r *http.Request) ([]string, error) {
+	var request struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+
+	if r.Method == http.MethodGet {
+		request.Query = r.URL.Query().Get("query")
+		request.OperationName = r.URL.Query().Get("operationName")
+	} else if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		json.Unmarshal(body, &request)
+	}
+
+	document, parseErr := parser.ParseQuery(&ast.Source{Input: request.Query})
+
+	if parseErr != nil {
+		return []string{ratelimit.DefaultOperation}, nil
+	}
+
+	operation := document.Operations.ForName(request.OperationName)
+
+	if operation == nil {
+		return []string{ratelimit.DefaultOperation}, nil
+	}
+
+	aliases := map[string]bool{}
+	operations := []string{}
+
+	collectRootFields(document, operation.SelectionSet, map[string]bool{}, func(field *ast.Field) {
+		if !aliases[field.Alias] {
+			aliases[field.Alias] = true
+			operations = append(operations, field.Name)
+		}
+	})
+
+	if len(operations) == 0 {
+		operations = append(operations, ratelimit.DefaultOperation)
+	}
+
+	return operations, nil
+}
+
+// collectRootFields calls collect with each field of the selection, including the fields of its fragments
+func collectRootFields(document *ast.QueryDocument, selections ast.SelectionSet, fragments map[string]bool, collect func(*ast.Field)) {
+	for _, selection := range selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			collect(selection)
+		case *ast.InlineFragment:
+			collectRootFields(document, selection.SelectionSet, fragments, collect)
+		case *ast.FragmentSpread:
+			fragment := document.Fragments.ForName(selection.Name)
+
+			// A fragment spread more than once, or spreading itself, is only visited once
+			if fragment != nil && !fragments[selection.Name] {
+				fragments[selection.Name] = true
+				collectRootFields(document, fragment.SelectionSet, fragments, collect)
+			}
+		}
+	}
+}
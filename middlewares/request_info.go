@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+)
+
+// RequestInfo is a middleware to inject the client ip, the user agent and the method of the request on the context
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "ip", clientIP(r))
+		ctx = context.WithValue(ctx, "userAgent", r.UserAgent())
+		ctx = context.WithValue(ctx, "method", r.Method)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientIP returns the ip of the client. The X-Forwarded-For header is only used when the server is configured to
+// trust it, otherwise any client could fake its ip. Since the proxies append to the header, it's read from the right,
+// skipping the entries of the trusted proxies, and the entries on the left, sent by the client, are ignored
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header["X-Forwarded-For"]; len(forwarded) > 0 && env.Config.TrustProxyHeaders {
+		entries := strings.Split(strings.Join(forwarded, ","), ",")
+		index := len(entries) - env.Config.TrustedProxyHops
+
+		if index < 0 {
+			index = 0
+		}
+
+		return strings.TrimSpace(entries[index])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,255 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/ratelimit"
+	"github.com/vektah/gqlparser/gqlerror"
+)
+
+// restMembership is the representation of a membership on the REST API
+type restMembership struct {
+	OrganizationID string `json:"organizationId"`
+	Role           string `json:"role"`
+}
+
+// restUser is the representation of an user on the REST API. It has the same fields as the User of the GraphQL
+// schema, so the password and the refresh tokens are never sent
+type restUser struct {
+	ID                  string           `json:"id"`
+	Email               string           `json:"email"`
+	Roles               []string         `json:"roles"`
+	Active              bool             `json:"active"`
+	Memberships         []restMembership `json:"memberships"`
+	DeletionScheduledAt *time.Time       `json:"deletionScheduledAt,omitempty"`
+	CreatedAt           time.Time        `json:"createdAt"`
+	UpdatedAt           time.Time        `json:"updatedAt"`
+}
+
+type restAuthPayload struct {
+	User         *restUser `json:"user"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refreshToken"`
+}
+
+type restValidateTokenPayload struct {
+	Valid  bool                 `json:"valid"`
+	Claims *jsonwebtoken.Claims `json:"claims"`
+	User   *restUser            `json:"user"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type validateTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// restEndpoint handles a request of the REST API, returning the status and the body of the response
+type restEndpoint func(r *http.Request) (int, interface{}, error)
+
+// MakeRESTHandlers returns the handlers of the REST API, served under /v1 for the clients that can't speak GraphQL.
+// The endpoints call the same resolver as the GraphQL API, and are counted on the rate limits of its operations
+func MakeRESTHandlers(services *Services) http.Handler {
+	resolver := services.Resolver
+	limiter := services.Limiter
+	mux := http.NewServeMux()
+
+	mux.Handle("/v1/login", restHandler(limiter, http.MethodPost, "login", func(r *http.Request) (int, interface{}, error) {
+		input := gqlmodels.LoginUserInput{}
+
+		if err := decodeBody(r, &input); err != nil {
+			return 0, nil, err
+		}
+
+		payload, err := resolver.Mutation().Login(r.Context(), input)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return http.StatusOK, newRESTAuthPayload(payload), nil
+	}))
+
+	mux.Handle("/v1/users", restHandler(limiter, http.MethodPost, "createUser", func(r *http.Request) (int, interface{}, error) {
+		input := gqlmodels.CreateUserInput{}
+
+		if err := decodeBody(r, &input); err != nil {
+			return 0, nil, err
+		}
+
+		payload, err := resolver.Mutation().CreateUser(r.Context(), input)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return http.StatusCreated, newRESTAuthPayload(payload), nil
+	}))
+
+	mux.Handle("/v1/token/refresh", restHandler(limiter, http.MethodPost, "refreshToken", func(r *http.Request) (int, interface{}, error) {
+		input := refreshTokenRequest{}
+
+		if err := decodeBody(r, &input); err != nil {
+			return 0, nil, err
+		}
+
+		payload, err := resolver.Mutation().RefreshToken(r.Context(), input.RefreshToken)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return http.StatusOK, newRESTAuthPayload(payload), nil
+	}))
+
+	mux.Handle("/v1/token/validate", restHandler(limiter, http.MethodPost, "validateToken", func(r *http.Request) (int, interface{}, error) {
+		input := validateTokenRequest{}
+
+		if err := decodeBody(r, &input); err != nil {
+			return 0, nil, err
+		}
+
+		payload, err := resolver.Mutation().ValidateToken(r.Context(), input.Token)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return http.StatusOK, restValidateTokenPayload{
+			Valid:  payload.Valid,
+			Claims: payload.Claims,
+			User:   newRESTUser(payload.User),
+		}, nil
+	}))
+
+	mux.Handle("/v1/me", restHandler(limiter, http.MethodGet, "me", func(r *http.Request) (int, interface{}, error) {
+		user, err := isAuthenticated(r.Context(), nil, func(ctx context.Context) (interface{}, error) {
+			return resolver.Query().Me(ctx)
+		})
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return http.StatusOK, newRESTUser(user.(*models.User)), nil
+	}))
+
+	return RequestLogger(RequestInfo(AuthHandler(mux)))
+}
+
+// restHandler checks the method and the rate limit of the request before calling the endpoint, and writes its
+// response or error as JSON
+func restHandler(limiter *ratelimit.Limiter, method, operation string, endpoint restEndpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{
+				"error": map[string]interface{}{"code": "METHOD_NOT_ALLOWED", "message": "Method not allowed"},
+			})
+			return
+		}
+
+		wait, err := limiter.Allow(operation, rateLimitClients(r)...)
+
+		if err != nil {
+			log.Printf("Error while trying to check the rate limit: %v", err)
+		} else if wait > 0 {
+			writeRESTError(w, gqlerrors.CreateRateLimitedError(wait))
+			return
+		}
+
+		status, body, err := endpoint(r)
+
+		if err != nil {
+			writeRESTError(w, err)
+			return
+		}
+
+		writeJSON(w, status, body)
+	})
+}
+
+// writeRESTError writes the error as {"error": {"code": ..., "message": ...}}, along with the other extensions of
+// the GraphQL error, like retryAfter. The status code matches the code of the error
+func writeRESTError(w http.ResponseWriter, err error) {
+	code := gqlerrors.Code(err)
+	body := map[string]interface{}{}
+
+	if e, ok := err.(*gqlerror.Error); ok {
+		for key, value := range e.Extensions {
+			body[key] = value
+		}
+
+		body["message"] = e.Message
+	} else {
+		log.Printf("Error while handling a REST request: %v", err)
+		body["message"] = "Internal server error"
+	}
+
+	body["code"] = code
+
+	if retryAfter, ok := body["retryAfter"].(int); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+
+	writeJSON(w, gqlerrors.HTTPStatus(code), map[string]interface{}{"error": body})
+}
+
+// decodeBody decodes the JSON body of the request into the input
+func decodeBody(r *http.Request, input interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		return gqlerrors.CreateBadUserInputError("The body must be a valid JSON")
+	}
+
+	return nil
+}
+
+func newRESTAuthPayload(payload *gqlmodels.AuthUserPayload) restAuthPayload {
+	return restAuthPayload{
+		User:         newRESTUser(payload.User),
+		Token:        payload.Token,
+		RefreshToken: payload.RefreshToken,
+	}
+}
+
+func newRESTUser(user *models.User) *restUser {
+	if user == nil {
+		return nil
+	}
+
+	memberships := []restMembership{}
+
+	for _, membership := range user.Memberships {
+		memberships = append(memberships, restMembership{
+			OrganizationID: membership.OrganizationID.Hex(),
+			Role:           membership.Role,
+		})
+	}
+
+	result := &restUser{
+		ID:          user.ID.Hex(),
+		Email:       user.Email,
+		Roles:       user.Roles,
+		Active:      user.Active,
+		Memberships: memberships,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+
+	if !user.DeletionScheduledAt.IsZero() {
+		result.DeletionScheduledAt = &user.DeletionScheduledAt
+	}
+
+	return result
+}
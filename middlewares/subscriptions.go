@@ -0,0 +1,144 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/handler"
+	"github.com/LucasFrezarini/go-auth-manager/credentials"
+	"github.com/LucasFrezarini/go-auth-manager/generated"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/resolvers"
+)
+
+// subscriptionRecheckInterval is how often the credentials of the open subscriptions are checked again
+const subscriptionRecheckInterval = 30 * time.Second
+
+type directive func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error)
+
+// subscriptionRoot is the root resolver that applies the directives to the subscriptions, since gqlgen doesn't
+// run them on the fields of the Subscription type
+type subscriptionRoot struct {
+	*resolvers.Resolver
+	directives generated.DirectiveRoot
+}
+
+// Subscription returns the root subscription resolver, guarded by the directives of the schema
+func (r *subscriptionRoot) Subscription() generated.SubscriptionResolver {
+	return &directiveSubscriptions{next: r.Resolver.Subscription(), directives: r.directives}
+}
+
+type directiveSubscriptions struct {
+	next       generated.SubscriptionResolver
+	directives generated.DirectiveRoot
+}
+
+func (s *directiveSubscriptions) UserEvents(ctx context.Context) (<-chan *models.AuditEvent, error) {
+	return subscribe(ctx, s.directives.IsAdmin, s.next.UserEvents)
+}
+
+func (s *directiveSubscriptions) MySessionEvents(ctx context.Context) (<-chan *models.AuditEvent, error) {
+	return subscribe(ctx, s.directives.IsAuthenticated, s.next.MySessionEvents)
+}
+
+// subscribe starts the subscription only if the directive allows it. The browsers can't send headers on the
+// websockets, so the token may also come on the Authorization of the connection init payload
+func subscribe(ctx context.Context, guard directive, resolve func(ctx context.Context) (<-chan *models.AuditEvent, error)) (<-chan *models.AuditEvent, error) {
+	if authorization := handler.GetInitPayload(ctx).Authorization(); authorization != "" && ctx.Value("userID") == nil {
+		ctx, _ = authenticate(ctx, authorization)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	events, err := guard(ctx, nil, func(ctx context.Context) (interface{}, error) {
+		return resolve(ctx)
+	})
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return watchCredentials(ctx, cancel, guard, events.(<-chan *models.AuditEvent)), nil
+}
+
+// watchCredentials forwards the events while the token of the subscription is still accepted. The subscription ends
+// when the token expires, or when the credentials or the directive refuse it on the checks made every
+// subscriptionRecheckInterval, so a deactivated user or a demoted admin stops receiving the events
+func watchCredentials(ctx context.Context, cancel context.CancelFunc, guard directive, events <-chan *models.AuditEvent) <-chan *models.AuditEvent {
+	claims, _ := ctx.Value("claims").(jsonwebtoken.Claims)
+	results := make(chan *models.AuditEvent)
+
+	go func() {
+		defer close(results)
+		defer cancel()
+
+		var expired <-chan time.Time
+
+		if claims.ExpiresAt != 0 {
+			timer := time.NewTimer(time.Until(time.Unix(claims.ExpiresAt, 0)))
+			defer timer.Stop()
+
+			expired = timer.C
+		}
+
+		ticker := time.NewTicker(subscriptionRecheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				select {
+				case results <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ticker.C:
+				if !credentialsAccepted(ctx, claims, guard) {
+					return
+				}
+			case <-expired:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// credentialsAccepted validates the claims of the subscription again, and runs the directive that guards it
+func credentialsAccepted(ctx context.Context, claims jsonwebtoken.Claims, guard directive) bool {
+	if _, err := credentials.ValidateCredentials(claims); err != nil {
+		return false
+	}
+
+	_, err := guard(ctx, nil, func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+
+	return err == nil
+}
+
+// websocketInit refuses the websockets opened with an invalid token on the connection init payload
+func websocketInit(ctx context.Context, initPayload handler.InitPayload) error {
+	authorization := initPayload.Authorization()
+
+	if authorization == "" {
+		return nil
+	}
+
+	if _, err := authenticate(ctx, authorization); err != nil {
+		return errors.New("Invalid token")
+	}
+
+	return nil
+}
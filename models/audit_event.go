@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// AuditUserCreated is recorded when an user is created
+	AuditUserCreated = "user.created"
+
+	// AuditUserUpdated is recorded when the data of an user is changed
+	AuditUserUpdated = "user.updated"
+
+	// AuditUserActivated is recorded when an user is activated
+	AuditUserActivated = "user.activated"
+
+	// AuditUserDeactivated is recorded when an user is deactivated
+	AuditUserDeactivated = "user.deactivated"
+
+	// AuditUserDeleted is recorded when an user is removed
+	AuditUserDeleted = "user.deleted"
+
+	// AuditPasswordReset is recorded when an admin sets the password of an user
+	AuditPasswordReset = "user.password_reset"
+
+	// AuditRolesChanged is recorded when the roles of an user are changed
+	AuditRolesChanged = "user.roles_changed"
+
+	// AuditSessionsRevoked is recorded when all the sessions of an user are revoked
+	AuditSessionsRevoked = "user.sessions_revoked"
+
+	// AuditUserUnlocked is recorded when an admin clears the lockout of an user after failed logins
+	AuditUserUnlocked = "user.unlocked"
+
+	// AuditUsersImported is recorded when an admin imports the users exported by another identity provider
+	AuditUsersImported = "users.imported"
+
+	// AuditUserSignedUp is recorded when an user creates its own account
+	AuditUserSignedUp = "user.signed_up"
+
+	// AuditLoginSucceeded is recorded when an user logs in
+	AuditLoginSucceeded = "user.login"
+
+	// AuditLoginFailed is recorded when a login is refused. The target is empty when the email isn't registered
+	AuditLoginFailed = "user.login_failed"
+
+	// AuditTokenRefreshed is recorded when an user gets a new access token with its refresh token
+	AuditTokenRefreshed = "user.token_refreshed"
+
+	// AuditPasswordChanged is recorded when an user changes its own password
+	AuditPasswordChanged = "user.password_changed"
+
+	// AuditOrganizationRoleChanged is recorded when an user joins, leaves or gets a new role on an organization
+	AuditOrganizationRoleChanged = "user.organization_role_changed"
+
+	// AuditDataExported is recorded when the data of an user is exported
+	AuditDataExported = "user.data_exported"
+
+	// AuditDeletionScheduled is recorded when an user requests the deletion of its account
+	AuditDeletionScheduled = "user.deletion_scheduled"
+
+	// AuditDeletionCancelled is recorded when an user cancels the deletion of its account during the grace period
+	AuditDeletionCancelled = "user.deletion_cancelled"
+
+	// AuditUserPurged is the anonymized record left behind when the account of an user is purged
+	AuditUserPurged = "user.purged"
+)
+
+// AuditEvent represents the data structure of a security relevant event in the MongoDB database
+type AuditEvent struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Action string             `json:"action" bson:"action,omitempty"`
+
+	// ActorID is the user that did the action. It's empty for anonymous actions
+	ActorID primitive.ObjectID `json:"actor_id" bson:"actor_id,omitempty"`
+
+	// TargetID is the user affected by the action
+	TargetID       primitive.ObjectID     `json:"target_id" bson:"target_id,omitempty"`
+	OrganizationID primitive.ObjectID     `json:"organization_id" bson:"organization_id,omitempty"`
+	IP             string                 `json:"ip" bson:"ip,omitempty"`
+	UserAgent      string                 `json:"user_agent" bson:"user_agent,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata" bson:"metadata,omitempty"`
+	CreatedAt      time.Time              `json:"created_at" bson:"created_at,omitempty"`
+}
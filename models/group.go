@@ -0,0 +1,100 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Group represents the data structure of a group of users inside an organization in the MongoDB database
+type Group struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id,omitempty"`
+	Name           string             `json:"name" bson:"name,omitempty"`
+
+	// UserIDs are the users that are direct members of the group
+	UserIDs []primitive.ObjectID `json:"user_ids" bson:"user_ids,omitempty"`
+
+	// GroupIDs are the nested groups. The members of a nested group are members of this group as well
+	GroupIDs  []primitive.ObjectID `json:"group_ids" bson:"group_ids,omitempty"`
+	CreatedAt time.Time            `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt time.Time            `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// ResolveGroups returns the sorted names of every group the user belongs to, directly or through nested groups
+func ResolveGroups(groups []*Group, userID primitive.ObjectID) []string {
+	parents := map[primitive.ObjectID][]*Group{}
+	var queue []*Group
+
+	for _, group := range groups {
+		for _, child := range group.GroupIDs {
+			parents[child] = append(parents[child], group)
+		}
+
+		if containsObjectID(group.UserIDs, userID) {
+			queue = append(queue, group)
+		}
+	}
+
+	visited := map[primitive.ObjectID]bool{}
+	var names []string
+
+	for len(queue) > 0 {
+		group := queue[0]
+		queue = queue[1:]
+
+		if visited[group.ID] {
+			continue
+		}
+
+		visited[group.ID] = true
+		names = append(names, group.Name)
+		queue = append(queue, parents[group.ID]...)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ContainsGroup returns true if the descendant group is reachable from the ancestor through the nested groups.
+// It is used to avoid cycles when nesting groups
+func ContainsGroup(groups []*Group, ancestorID, descendantID primitive.ObjectID) bool {
+	byID := map[primitive.ObjectID]*Group{}
+
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+
+	visited := map[primitive.ObjectID]bool{}
+	queue := []primitive.ObjectID{ancestorID}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if id == descendantID {
+			return true
+		}
+
+		if visited[id] || byID[id] == nil {
+			continue
+		}
+
+		visited[id] = true
+		queue = append(queue, byID[id].GroupIDs...)
+	}
+
+	return false
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, value := range ids {
+		if value == id {
+			return true
+		}
+	}
+
+	return false
+}
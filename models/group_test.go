@@ -0,0 +1,55 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolveGroups(t *testing.T) {
+	user := primitive.NewObjectID()
+	another := primitive.NewObjectID()
+
+	onCall := &models.Group{ID: primitive.NewObjectID(), Name: "on-call", UserIDs: []primitive.ObjectID{user}}
+	backend := &models.Group{ID: primitive.NewObjectID(), Name: "backend", GroupIDs: []primitive.ObjectID{onCall.ID}}
+	engineering := &models.Group{ID: primitive.NewObjectID(), Name: "engineering", GroupIDs: []primitive.ObjectID{backend.ID}}
+	sales := &models.Group{ID: primitive.NewObjectID(), Name: "sales", UserIDs: []primitive.ObjectID{another}}
+
+	groups := []*models.Group{engineering, sales, backend, onCall}
+
+	t.Run("Should return the direct and the transitive groups of the user", func(t *testing.T) {
+		require.Equal(t, []string{"backend", "engineering", "on-call"}, models.ResolveGroups(groups, user))
+	})
+
+	t.Run("Should not return groups the user doesn't belong to", func(t *testing.T) {
+		require.Equal(t, []string{"sales"}, models.ResolveGroups(groups, another))
+		require.Empty(t, models.ResolveGroups(groups, primitive.NewObjectID()))
+	})
+
+	t.Run("Should not loop forever if the groups have a cycle", func(t *testing.T) {
+		first := &models.Group{ID: primitive.NewObjectID(), Name: "first", UserIDs: []primitive.ObjectID{user}}
+		second := &models.Group{ID: primitive.NewObjectID(), Name: "second", GroupIDs: []primitive.ObjectID{first.ID}}
+		first.GroupIDs = []primitive.ObjectID{second.ID}
+
+		require.Equal(t, []string{"first", "second"}, models.ResolveGroups([]*models.Group{first, second}, user))
+	})
+}
+
+func TestContainsGroup(t *testing.T) {
+	child := &models.Group{ID: primitive.NewObjectID(), Name: "child"}
+	parent := &models.Group{ID: primitive.NewObjectID(), Name: "parent", GroupIDs: []primitive.ObjectID{child.ID}}
+	root := &models.Group{ID: primitive.NewObjectID(), Name: "root", GroupIDs: []primitive.ObjectID{parent.ID}}
+
+	groups := []*models.Group{root, parent, child}
+
+	t.Run("Should find groups nested at any level", func(t *testing.T) {
+		require.True(t, models.ContainsGroup(groups, root.ID, child.ID))
+		require.True(t, models.ContainsGroup(groups, parent.ID, child.ID))
+	})
+
+	t.Run("Should not find the ancestors of a group", func(t *testing.T) {
+		require.False(t, models.ContainsGroup(groups, child.ID, root.ID))
+	})
+}
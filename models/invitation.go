@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// InvitationLifetime represents how long an invitation can be accepted after being sent
+	InvitationLifetime = time.Hour * 24 * 7
+
+	// InvitationPending is the status of an invitation waiting to be accepted
+	InvitationPending = "pending"
+
+	// InvitationAccepted is the status of an invitation already used to join the organization
+	InvitationAccepted = "accepted"
+
+	// InvitationRevoked is the status of an invitation cancelled by an admin
+	InvitationRevoked = "revoked"
+)
+
+// Invitation represents the data structure of an invitation to join an organization in the MongoDB database
+type Invitation struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id,omitempty"`
+	Email          string             `json:"email" bson:"email,omitempty"`
+	Role           string             `json:"role" bson:"role,omitempty"`
+
+	// TokenHash is the SHA-256 of the token sent to the invited email. The token itself is never stored
+	TokenHash string             `json:"-" bson:"token_hash,omitempty"`
+	InvitedBy primitive.ObjectID `json:"invited_by" bson:"invited_by,omitempty"`
+	Status    string             `json:"status" bson:"status,omitempty"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// IsExpired returns true if the invitation can't be accepted anymore because of its age
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// LoginAttempts represents the failed login attempts registered for a key, like an account or an IP
+type LoginAttempts struct {
+	Failures    int
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,32 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	// RoleOwner is the organization role of who created the organization
+	RoleOwner = "owner"
+
+	// RoleAdmin is the organization role allowed to manage the organization users
+	RoleAdmin = "admin"
+
+	// RoleMember is the default organization role
+	RoleMember = "member"
+
+	// RoleSysadmin is the global role allowed to manage every organization
+	RoleSysadmin = "sysadmin"
+)
+
+// OrganizationRoles lists the roles that can be given to an user inside an organization
+var OrganizationRoles = []string{RoleOwner, RoleAdmin, RoleMember}
+
+// Membership represents the link between an user and an organization
+type Membership struct {
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id,omitempty"`
+
+	// Role is the role the user plays inside the organization. Ex: owner, admin, member
+	Role string `json:"role" bson:"role,omitempty"`
+
+	// Managed is true when the account was created, imported or invited by the organization, so its admins are allowed
+	// to manage it. The existing accounts that joined the organization by accepting an invitation aren't managed
+	Managed bool `json:"managed" bson:"managed,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// OutboxPending is the status of a message not yet published to all the sinks
+	OutboxPending = "pending"
+
+	// OutboxProcessed is the status of a message published to all the sinks
+	OutboxProcessed = "processed"
+)
+
+// OutboxMessage represents the data structure of an event waiting to be published in the MongoDB database.
+// It's written along with the change it describes, so the event isn't lost when the process stops before publishing it
+type OutboxMessage struct {
+	ID    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Event AuditEvent         `json:"event" bson:"event"`
+
+	Status   string `json:"status" bson:"status,omitempty"`
+	Attempts int    `json:"attempts" bson:"attempts"`
+
+	// NextAttemptAt is when the message is published again. While a relay is publishing it, it's pushed forward
+	// so the other relays don't publish it at the same time
+	NextAttemptAt time.Time `json:"next_attempt_at" bson:"next_attempt_at,omitempty"`
+
+	// Sinks are the names of the sinks the message was already published to, so a retry skips them
+	Sinks       []string  `json:"sinks" bson:"sinks,omitempty"`
+	LastError   string    `json:"last_error" bson:"last_error,omitempty"`
+	ProcessedAt time.Time `json:"processed_at" bson:"processed_at,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at,omitempty"`
+}
+
+// PublishedTo returns true if the message was already published to the sink
+func (m *OutboxMessage) PublishedTo(sink string) bool {
+	for _, name := range m.Sinks {
+		if name == sink {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,11 +1,15 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrDuplicateEmail is returned when the email of a new user is already registered
+var ErrDuplicateEmail = errors.New("The email is already registered")
+
 // User represents the data structure of a user in the MongoDB database
 type User struct {
 	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -14,6 +18,65 @@ type User struct {
 	Roles         []string           `json:"roles" bson:"roles,omitempty"`
 	Active        bool               `json:"active" bson:"active,omitempty"`
 	RefreshTokens []RefreshToken     `json:"refresh_tokens" bson:"refresh_tokens,omitempty"`
+	Memberships   []Membership       `json:"memberships" bson:"memberships,omitempty"`
 	CreatedAt     time.Time          `json:"created_at" bson:"created_at,omitempty"`
 	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at,omitempty"`
+
+	// PasswordHistory are the hashes of the previous passwords, from the newest to the oldest
+	PasswordHistory []string `json:"-" bson:"password_history,omitempty"`
+
+	// PasswordChangedAt is when the password was defined. It's empty for the users created before it was tracked
+	PasswordChangedAt time.Time `json:"password_changed_at" bson:"password_changed_at,omitempty"`
+
+	// DeletionScheduledAt is when the account will be purged. It's empty when the deletion wasn't requested
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" bson:"deletion_scheduled_at,omitempty"`
+}
+
+// HasRole returns true if the user has the global role passed as parameter
+func (u *User) HasRole(role string) bool {
+	for _, value := range u.Roles {
+		if value == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PasswordExpired returns true if the password is older than maxAge. The passwords of the users created
+// before the change date was tracked are as old as the user. A maxAge of zero means they never expire
+func (u *User) PasswordExpired(maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 {
+		return false
+	}
+
+	changedAt := u.PasswordChangedAt
+
+	if changedAt.IsZero() {
+		changedAt = u.CreatedAt
+	}
+
+	return now.Sub(changedAt) > maxAge
+}
+
+// MembershipOf returns the membership of the user on the organization, or nil if the user isn't a member of it
+func (u *User) MembershipOf(organizationID primitive.ObjectID) *Membership {
+	for i := range u.Memberships {
+		if u.Memberships[i].OrganizationID == organizationID {
+			return &u.Memberships[i]
+		}
+	}
+
+	return nil
+}
+
+// IsOrganizationAdmin returns true if the user is allowed to manage the users of the organization
+func (u *User) IsOrganizationAdmin(organizationID primitive.ObjectID) bool {
+	if u.HasRole(RoleSysadmin) {
+		return true
+	}
+
+	membership := u.MembershipOf(organizationID)
+
+	return membership != nil && (membership.Role == RoleAdmin || membership.Role == RoleOwner)
 }
@@ -0,0 +1,38 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordExpired(t *testing.T) {
+	now := time.Now()
+	maxAge := 90 * 24 * time.Hour
+
+	t.Run("Should not expire the password when there's no max age", func(t *testing.T) {
+		user := models.User{PasswordChangedAt: now.AddDate(-10, 0, 0)}
+
+		require.False(t, user.PasswordExpired(0, now))
+	})
+
+	t.Run("Should expire the password older than the max age", func(t *testing.T) {
+		user := models.User{PasswordChangedAt: now.AddDate(0, 0, -91)}
+
+		require.True(t, user.PasswordExpired(maxAge, now))
+	})
+
+	t.Run("Should not expire the password newer than the max age", func(t *testing.T) {
+		user := models.User{PasswordChangedAt: now.AddDate(0, 0, -89)}
+
+		require.False(t, user.PasswordExpired(maxAge, now))
+	})
+
+	t.Run("Should use the creation date when the password change wasn't tracked", func(t *testing.T) {
+		user := models.User{CreatedAt: now.AddDate(0, 0, -91)}
+
+		require.True(t, user.PasswordExpired(maxAge, now))
+	})
+}
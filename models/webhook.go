@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// WebhookDeliveryPending is the status of a delivery waiting to be sent, or to be retried
+	WebhookDeliveryPending = "pending"
+
+	// WebhookDeliveryDelivered is the status of a delivery accepted by the receiver
+	WebhookDeliveryDelivered = "delivered"
+
+	// WebhookDeliveryFailed is the status of a delivery that won't be retried anymore
+	WebhookDeliveryFailed = "failed"
+)
+
+// ErrWebhookSubscriptionNotFound is returned when the subscription of a delivery was removed
+var ErrWebhookSubscriptionNotFound = errors.New("The webhook subscription was not found")
+
+// WebhookSubscription represents the data structure of an URL subscribed to the events in the MongoDB database
+type WebhookSubscription struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+
+	// OrganizationID restricts the subscription to the events of the organization. When it's empty,
+	// the subscription receives the events of every organization
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id,omitempty"`
+	URL            string             `json:"url" bson:"url,omitempty"`
+
+	// Secret is the key of the HMAC signatures of the payloads. It's never returned to the clients
+	Secret     string             `json:"-" bson:"secret,omitempty"`
+	EventTypes []string           `json:"event_types" bson:"event_types,omitempty"`
+	Active     bool               `json:"active" bson:"active"`
+	CreatedBy  primitive.ObjectID `json:"created_by" bson:"created_by,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// WebhookDelivery represents the data structure of an event sent to a subscription in the MongoDB database.
+// The pending deliveries are the outbox of the webhooks, and all of them together are the delivery log
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SubscriptionID primitive.ObjectID `json:"subscription_id" bson:"subscription_id,omitempty"`
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id,omitempty"`
+	EventID        primitive.ObjectID `json:"event_id" bson:"event_id,omitempty"`
+	EventType      string             `json:"event_type" bson:"event_type,omitempty"`
+
+	// UserID is the user the event is about, so its deliveries are removed along with the account
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id,omitempty"`
+
+	// Payload is the JSON sent to the subscription. It's built when the event happens, so the retries send the same data
+	Payload  string `json:"payload" bson:"payload,omitempty"`
+	Status   string `json:"status" bson:"status,omitempty"`
+	Attempts int    `json:"attempts" bson:"attempts"`
+
+	// NextAttemptAt is when the delivery is sent again. While a worker is sending it, it's pushed forward
+	// so the other workers don't send it at the same time
+	NextAttemptAt  time.Time `json:"next_attempt_at" bson:"next_attempt_at,omitempty"`
+	LastAttemptAt  time.Time `json:"last_attempt_at" bson:"last_attempt_at,omitempty"`
+	ResponseStatus int       `json:"response_status" bson:"response_status,omitempty"`
+	LastError      string    `json:"last_error" bson:"last_error,omitempty"`
+	DeliveredAt    time.Time `json:"delivered_at" bson:"delivered_at,omitempty"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+}
@@ -0,0 +1,117 @@
+// Package notifier warns the users about the security relevant activity on their accounts, like logins from new devices
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/mailer"
+)
+
+// NewDevice is the notification of a login from a device or IP the user never used before
+type NewDevice struct {
+	Email     string
+	IP        string
+	UserAgent string
+	At        time.Time
+
+	// RevokeURL is the "this wasn't me" link, which revokes all the sessions of the user
+	RevokeURL string
+}
+
+// Notifier delivers the notifications. Implementations must be safe for concurrent use
+type Notifier interface {
+	NotifyNewDevice(notification NewDevice) error
+}
+
+// New returns the notifier configured by the environment: a notifier that only logs the notifications when
+// NOTIFIER is log, or a notifier that sends them by email through the mailer otherwise
+func New(m mailer.Mailer) Notifier {
+	if env.Config.Notifier == "log" {
+		return &LogNotifier{}
+	}
+
+	return &MailNotifier{Mailer: m}
+}
+
+// Origins are the IPs and user agents the user already logged in from
+type Origins struct {
+	IPs        []string
+	UserAgents []string
+}
+
+// IsNew returns true if the ip or the user agent were never used by the user. When there isn't any known origin,
+// like on the first login of an imported user, nothing is considered new, since there's nothing to compare with.
+// An unknown ip or user agent, received empty, isn't considered new either
+func (o Origins) IsNew(ip, userAgent string) bool {
+	if len(o.IPs) == 0 && len(o.UserAgents) == 0 {
+		return false
+	}
+
+	return (ip != "" && !contains(o.IPs, ip)) || (userAgent != "" && !contains(o.UserAgents, userAgent))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LogNotifier is a notifier used on development that only writes the notifications on the log
+type LogNotifier struct{}
+
+// NotifyNewDevice writes the notification on the log
+func (n *LogNotifier) NotifyNewDevice(notification NewDevice) error {
+	log.Printf("New device login of <%s> from %s (%s). Revoke the sessions: %s",
+		notification.Email, notification.IP, notification.UserAgent, notification.RevokeURL)
+
+	return nil
+}
+
+// MailNotifier sends the notifications by email
+type MailNotifier struct {
+	Mailer mailer.Mailer
+}
+
+// NotifyNewDevice sends the notification to the email of the user
+func (n *MailNotifier) NotifyNewDevice(notification NewDevice) error {
+	return n.Mailer.Send(mailer.Message{
+		To:      notification.Email,
+		Subject: "New login to your account",
+		Body: fmt.Sprintf(
+			"Your account was accessed from a new device.\n\nWhen: %s\nIP: %s\nDevice: %s\n\nIf this wasn't you, revoke all your sessions and change your password: %s",
+			notification.At.Format("2006-01-02 15:04:05"), notification.IP, notification.UserAgent, notification.RevokeURL,
+		),
+	})
+}
+
+// MemoryNotifier keeps the notifications in memory instead of delivering them. Useful on tests
+type MemoryNotifier struct {
+	mu         sync.Mutex
+	newDevices []NewDevice
+}
+
+// NotifyNewDevice stores the notification
+func (n *MemoryNotifier) NotifyNewDevice(notification NewDevice) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.newDevices = append(n.newDevices, notification)
+
+	return nil
+}
+
+// NewDevices returns the new device notifications sent so far
+func (n *MemoryNotifier) NewDevices() []NewDevice {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]NewDevice(nil), n.newDevices...)
+}
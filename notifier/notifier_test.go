@@ -0,0 +1,61 @@
+package notifier_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/mailer"
+	"github.com/LucasFrezarini/go-auth-manager/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrigins(t *testing.T) {
+	origins := notifier.Origins{
+		IPs:        []string{"10.0.0.1", "10.0.0.2"},
+		UserAgents: []string{"firefox"},
+	}
+
+	t.Run("Should not consider a known ip and user agent as new", func(t *testing.T) {
+		require.False(t, origins.IsNew("10.0.0.2", "firefox"))
+	})
+
+	t.Run("Should consider a new ip as new", func(t *testing.T) {
+		require.True(t, origins.IsNew("10.0.0.3", "firefox"))
+	})
+
+	t.Run("Should consider a new user agent as new", func(t *testing.T) {
+		require.True(t, origins.IsNew("10.0.0.1", "chrome"))
+	})
+
+	t.Run("Should not consider an empty user agent as new", func(t *testing.T) {
+		require.False(t, origins.IsNew("10.0.0.1", ""))
+	})
+
+	t.Run("Should not consider anything new when there isn't any known origin", func(t *testing.T) {
+		require.False(t, notifier.Origins{}.IsNew("10.0.0.1", "firefox"))
+	})
+}
+
+func TestMailNotifier(t *testing.T) {
+	t.Run("Should send the new device notification with the revoke link", func(t *testing.T) {
+		m := &mailer.MemoryMailer{}
+		n := &notifier.MailNotifier{Mailer: m}
+
+		err := n.NotifyNewDevice(notifier.NewDevice{
+			Email:     "test@test.com",
+			IP:        "10.0.0.1",
+			UserAgent: "firefox",
+			At:        time.Now(),
+			RevokeURL: "http://app.test/sessions/revoke?token=abc",
+		})
+
+		require.NoError(t, err)
+
+		messages := m.Messages()
+
+		require.Equal(t, 1, len(messages))
+		require.Equal(t, "test@test.com", messages[0].To)
+		require.Contains(t, messages[0].Body, "10.0.0.1")
+		require.Contains(t, messages[0].Body, "http://app.test/sessions/revoke?token=abc")
+	})
+}
@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryStore keeps the outbox in memory. It's used on the tests
+type MemoryStore struct {
+	mutex    sync.Mutex
+	messages []models.OutboxMessage
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Add writes a pending message of the event to the outbox
+func (s *MemoryStore) Add(event models.AuditEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, models.OutboxMessage{
+		ID:            primitive.NewObjectID(),
+		Event:         event,
+		Status:        models.OutboxPending,
+		NextAttemptAt: event.CreatedAt,
+		CreatedAt:     event.CreatedAt,
+	})
+}
+
+// Messages returns a copy of all the messages, in the order they were added
+func (s *MemoryStore) Messages() []models.OutboxMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]models.OutboxMessage{}, s.messages...)
+}
+
+// Claim returns the oldest pending message due before now, pushing its next attempt to leaseUntil
+func (s *MemoryStore) Claim(now, leaseUntil time.Time) (*models.OutboxMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, message := range s.messages {
+		if message.Status == models.OutboxPending && !message.NextAttemptAt.After(now) {
+			s.messages[i].NextAttemptAt = leaseUntil
+			claimed := s.messages[i]
+			claimed.Sinks = append([]string{}, message.Sinks...)
+
+			return &claimed, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Save stores the outcome of an attempt to publish the message
+func (s *MemoryStore) Save(message *models.OutboxMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.messages {
+		if s.messages[i].ID == message.ID {
+			s.messages[i] = *message
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+// Package outbox publishes the events written to the transactional outbox. The events are stored in the same
+// transaction as the changes they describe, and a relay publishes them to the sinks, like the webhooks or a
+// message broker, until all of them accept it. A message may be published more than once, but it's never lost
+package outbox
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/worker"
+)
+
+// BatchSize is the maximum number of messages published on each run
+const BatchSize = 100
+
+// Store keeps the outbox. Implementations must be safe for concurrent use
+type Store interface {
+	// Claim returns the oldest pending message due before now, pushing its next attempt to leaseUntil so
+	// the other relays don't publish it at the same time. It returns nil when there isn't any due message
+	Claim(now, leaseUntil time.Time) (*models.OutboxMessage, error)
+
+	// Save stores the outcome of an attempt to publish the message
+	Save(message *models.OutboxMessage) error
+}
+
+// Sink is a destination of the events. Since a message is published again when any sink fails, or when the
+// relay stops halfway, the sinks may get the same event more than once. The id of the event identifies the duplicates
+type Sink interface {
+	// Name identifies the sink on the messages, so the retries skip the sinks that already got the event
+	Name() string
+
+	Publish(event models.AuditEvent) error
+}
+
+// Config defines how the failed messages are retried
+type Config struct {
+	// RetryDelay is how long the first retry waits. It doubles on each new failure
+	RetryDelay time.Duration
+
+	// MaxRetryDelay is the longest a retry can wait
+	MaxRetryDelay time.Duration
+
+	// Lease is how long a claimed message is hidden from the other relays. When the relay stops before
+	// saving the outcome, the message is published again after it
+	Lease time.Duration
+}
+
+// ConfigFromEnv returns the config defined by the OUTBOX_* environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		RetryDelay:    env.Config.OutboxRetryDelay,
+		MaxRetryDelay: env.Config.OutboxMaxRetryDelay,
+		Lease:         env.Config.OutboxLease,
+	}
+}
+
+// Relay publishes the pending messages of the outbox to the sinks
+type Relay struct {
+	store  Store
+	config Config
+	sinks  []Sink
+	now    func() time.Time
+}
+
+// New creates a Relay that publishes the messages of the store to the sinks
+func New(store Store, config Config, sinks ...Sink) *Relay {
+	return &Relay{
+		store:  store,
+		config: config,
+		sinks:  sinks,
+		now:    time.Now,
+	}
+}
+
+// Run publishes a batch of the due messages and returns how many were published to all the sinks
+func (r *Relay) Run() (int, error) {
+	processed := 0
+
+	for i := 0; i < BatchSize; i++ {
+		now := r.now()
+		message, err := r.store.Claim(now, now.Add(r.config.Lease))
+
+		if err != nil {
+			return processed, err
+		}
+
+		if message == nil {
+			break
+		}
+
+		if r.publish(message, now) {
+			processed++
+		}
+
+		// When the outcome isn't saved, the message is published again once the lease is over
+		if err := r.store.Save(message); err != nil {
+			log.Printf("Error while trying to save the outbox message %s: %v", message.ID.Hex(), err)
+		}
+	}
+
+	return processed, nil
+}
+
+// publish sends the event of the message to the sinks it wasn't sent yet, returning true if all of them accepted it
+func (r *Relay) publish(message *models.OutboxMessage, now time.Time) bool {
+	var failures []string
+
+	for _, sink := range r.sinks {
+		if message.PublishedTo(sink.Name()) {
+			continue
+		}
+
+		if err := sink.Publish(message.Event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+			continue
+		}
+
+		message.Sinks = append(message.Sinks, sink.Name())
+	}
+
+	message.Attempts++
+
+	if len(failures) > 0 {
+		message.LastError = strings.Join(failures, "; ")
+		message.NextAttemptAt = now.Add(r.Backoff(message.Attempts))
+		return false
+	}
+
+	message.Status = models.OutboxProcessed
+	message.ProcessedAt = now
+	message.LastError = ""
+
+	return true
+}
+
+// Backoff returns how long the message waits after the failed attempt: the retry delay, doubled after
+// each attempt, up to the max retry delay. The messages are retried for as long as they fail
+func (r *Relay) Backoff(attempts int) time.Duration {
+	return worker.Backoff(r.config.RetryDelay, r.config.MaxRetryDelay, attempts)
+}
+
+// Start publishes the due messages on every interval, until the returned function is called
+func (r *Relay) Start(interval time.Duration) (stop func()) {
+	return worker.Start(interval, "publish the outbox messages", r.Run)
+}
@@ -0,0 +1,165 @@
+package outbox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/outbox"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func config() outbox.Config {
+	return outbox.Config{RetryDelay: time.Millisecond, MaxRetryDelay: 4 * time.Millisecond, Lease: time.Minute}
+}
+
+func event(action string) models.AuditEvent {
+	return models.AuditEvent{
+		ID:        primitive.NewObjectID(),
+		Action:    action,
+		TargetID:  primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestRelay(t *testing.T) {
+	t.Run("Should publish the pending messages to all the sinks", func(t *testing.T) {
+		store := outbox.NewMemoryStore()
+		broker := &outbox.MemoryBroker{}
+		var output bytes.Buffer
+		e := event(models.AuditUserCreated)
+		store.Add(e)
+
+		processed, err := outbox.New(store, config(), outbox.NewBrokerSink("nats", broker, "auth."), outbox.NewWriterSink(&output)).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Equal(t, models.OutboxProcessed, store.Messages()[0].Status)
+		require.Equal(t, []string{"nats", "stdout"}, store.Messages()[0].Sinks)
+
+		messages := broker.Messages()
+		require.Equal(t, 1, len(messages))
+		require.Equal(t, "auth.user.created", messages[0].Topic)
+		require.Equal(t, e.TargetID.Hex(), messages[0].Key)
+
+		var payload webhook.Payload
+		require.NoError(t, json.Unmarshal(output.Bytes(), &payload))
+		require.Equal(t, e.ID.Hex(), payload.ID)
+		require.Equal(t, models.AuditUserCreated, payload.Type)
+	})
+
+	t.Run("Should not publish the processed messages again", func(t *testing.T) {
+		store := outbox.NewMemoryStore()
+		broker := &outbox.MemoryBroker{}
+		store.Add(event(models.AuditUserCreated))
+		relay := outbox.New(store, config(), outbox.NewBrokerSink("kafka", broker, ""))
+
+		_, err := relay.Run()
+		require.NoError(t, err)
+
+		processed, err := relay.Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, processed)
+		require.Equal(t, 1, len(broker.Messages()))
+	})
+
+	t.Run("Should retry only the sinks that failed", func(t *testing.T) {
+		store := outbox.NewMemoryStore()
+		healthy := &outbox.MemoryBroker{}
+		down := &outbox.MemoryBroker{Err: errors.New("connection refused")}
+		store.Add(event(models.AuditUserDeactivated))
+		relay := outbox.New(store, config(), outbox.NewBrokerSink("nats", healthy, ""), outbox.NewBrokerSink("kafka", down, ""))
+
+		processed, err := relay.Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, processed)
+
+		message := store.Messages()[0]
+		require.Equal(t, models.OutboxPending, message.Status)
+		require.Equal(t, []string{"nats"}, message.Sinks)
+		require.Contains(t, message.LastError, "connection refused")
+		require.Equal(t, 1, message.Attempts)
+
+		down.Err = nil
+		time.Sleep(5 * time.Millisecond)
+
+		processed, err = relay.Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Equal(t, 1, len(healthy.Messages()))
+		require.Equal(t, 1, len(down.Messages()))
+		require.Equal(t, models.OutboxProcessed, store.Messages()[0].Status)
+	})
+
+	t.Run("Should publish a claimed message again after the lease when the relay stops halfway", func(t *testing.T) {
+		store := outbox.NewMemoryStore()
+		store.Add(event(models.AuditPasswordChanged))
+
+		claimed, err := store.Claim(time.Now(), time.Now().Add(time.Millisecond))
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+
+		broker := &outbox.MemoryBroker{}
+		relay := outbox.New(store, config(), outbox.NewBrokerSink("nats", broker, ""))
+
+		processed, err := relay.Run()
+		require.NoError(t, err)
+		require.Equal(t, 0, processed)
+
+		time.Sleep(5 * time.Millisecond)
+
+		processed, err = relay.Run()
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Equal(t, 1, len(broker.Messages()))
+	})
+
+	t.Run("Should enqueue the webhook deliveries of the events", func(t *testing.T) {
+		store := outbox.NewMemoryStore()
+		webhooks := webhook.NewMemoryStore()
+		webhooks.AddSubscription(models.WebhookSubscription{URL: "http://hooks.test", EventTypes: []string{models.AuditUserCreated}, Active: true})
+		e := event(models.AuditUserCreated)
+		store.Add(e)
+
+		processed, err := outbox.New(store, config(), &outbox.WebhookSink{Store: webhooks}).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Equal(t, 1, len(webhooks.Deliveries()))
+		require.Equal(t, e.ID, webhooks.Deliveries()[0].EventID)
+	})
+
+	t.Run("Should deliver the events to the live subscriptions", func(t *testing.T) {
+		store := outbox.NewMemoryStore()
+		p := pubsub.NewMemory()
+		events, cancel := p.Subscribe(nil)
+		defer cancel()
+
+		e := event(models.AuditSessionsRevoked)
+		store.Add(e)
+
+		processed, err := outbox.New(store, config(), &outbox.PubSubSink{PubSub: p}).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+		require.Equal(t, e.ID, (<-events).ID)
+	})
+
+	t.Run("Should double the backoff up to the max retry delay", func(t *testing.T) {
+		relay := outbox.New(outbox.NewMemoryStore(), outbox.Config{RetryDelay: time.Second, MaxRetryDelay: 3 * time.Second})
+
+		require.Equal(t, time.Second, relay.Backoff(1))
+		require.Equal(t, 2*time.Second, relay.Backoff(2))
+		require.Equal(t, 3*time.Second, relay.Backoff(3))
+		require.Equal(t, 3*time.Second, relay.Backoff(30))
+	})
+}
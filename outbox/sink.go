@@ -0,0 +1,175 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
+)
+
+// WebhookSink enqueues the deliveries of the events to the webhooks subscribed to them
+type WebhookSink struct {
+	Store webhook.Store
+}
+
+// Name identifies the sink on the outbox messages
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish enqueues a delivery of the event for each subscription. The webhook dispatcher sends and retries them
+func (s *WebhookSink) Publish(event models.AuditEvent) error {
+	return webhook.Publish(s.Store, event)
+}
+
+// PubSubSink delivers the events to the live subscriptions of the clients, like the GraphQL subscriptions
+type PubSubSink struct {
+	PubSub pubsub.PubSub
+}
+
+// Name identifies the sink on the outbox messages
+func (s *PubSubSink) Name() string {
+	return "pubsub"
+}
+
+// Publish delivers the event to the current subscribers
+func (s *PubSubSink) Publish(event models.AuditEvent) error {
+	return s.PubSub.Publish(event)
+}
+
+// WriterSink writes the events to the writer, one JSON per line, with the same format as the webhook payloads
+type WriterSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewWriterSink creates a WriterSink that writes the events to the writer
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{writer: writer}
+}
+
+// NewStdoutSink creates a WriterSink that writes the events to the standard output, to be collected with the logs
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// Name identifies the sink on the outbox messages
+func (s *WriterSink) Name() string {
+	return "stdout"
+}
+
+// Publish writes the event as a line of JSON
+func (s *WriterSink) Publish(event models.AuditEvent) error {
+	line, err := json.Marshal(webhook.NewPayload(event))
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to encode the event: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.writer.Write(append(line, '\n'))
+
+	return err
+}
+
+// Broker is the client of a message broker, like NATS or Kafka. Implementations must be safe for concurrent use
+type Broker interface {
+	// Publish sends the message to the topic, which is the subject on NATS. The key is the id of the user,
+	// so the brokers that partition the topics, like Kafka, keep the events of each user in order
+	Publish(topic, key string, message []byte) error
+}
+
+// BrokerSink publishes the events to a message broker, on a topic per event type, like auth.user.created
+type BrokerSink struct {
+	name   string
+	broker Broker
+	prefix string
+}
+
+// NewBrokerSink creates a BrokerSink that publishes the events to the topics starting with the prefix
+func NewBrokerSink(name string, broker Broker, prefix string) *BrokerSink {
+	return &BrokerSink{name: name, broker: broker, prefix: prefix}
+}
+
+// Name identifies the sink on the outbox messages
+func (s *BrokerSink) Name() string {
+	return s.name
+}
+
+// Publish sends the event, with the same format as the webhook payloads, to the topic of its type
+func (s *BrokerSink) Publish(event models.AuditEvent) error {
+	message, err := json.Marshal(webhook.NewPayload(event))
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to encode the event: %v", err)
+	}
+
+	return s.broker.Publish(s.prefix+event.Action, event.TargetID.Hex(), message)
+}
+
+// BrokerMessage is a message published to the MemoryBroker
+type BrokerMessage struct {
+	Topic   string
+	Key     string
+	Message []byte
+}
+
+// MemoryBroker keeps the published messages in memory. It's a fake of the NATS and Kafka clients, used on the tests
+type MemoryBroker struct {
+	mutex    sync.Mutex
+	messages []BrokerMessage
+
+	// Err, when set, is returned by all the publications, simulating a broker that is down
+	Err error
+}
+
+// Publish keeps the message, or returns Err when it's set
+func (b *MemoryBroker) Publish(topic, key string, message []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.Err != nil {
+		return b.Err
+	}
+
+	b.messages = append(b.messages, BrokerMessage{Topic: topic, Key: key, Message: message})
+
+	return nil
+}
+
+// Messages returns a copy of the published messages, in the order they were published
+func (b *MemoryBroker) Messages() []BrokerMessage {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]BrokerMessage{}, b.messages...)
+}
+
+// SinksFromEnv returns the sinks listed on OUTBOX_SINKS, separated by commas: webhook, stdout, or both.
+// The broker sinks need a client, so they're plugged on the code with NewBrokerSink
+func SinksFromEnv(webhookStore webhook.Store) ([]Sink, error) {
+	var sinks []Sink
+
+	for _, name := range strings.Split(env.Config.OutboxSinks, ",") {
+		switch strings.TrimSpace(name) {
+		case "webhook":
+			sinks = append(sinks, &WebhookSink{Store: webhookStore})
+		case "stdout":
+			sinks = append(sinks, NewStdoutSink())
+		case "":
+		default:
+			return nil, fmt.Errorf("Unknown outbox sink %s", name)
+		}
+	}
+
+	return sinks, nil
+}
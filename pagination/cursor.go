@@ -0,0 +1,60 @@
+// Package pagination implements the opaque cursors used by the Relay style connections
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultPageSize is the number of items returned when the client doesn't ask for a size
+	DefaultPageSize = 20
+
+	// MaxPageSize is the maximum number of items returned in a single page
+	MaxPageSize = 100
+)
+
+// Cursor points to an item of a sorted list: the value of the sorted field and the id used to break ties
+type Cursor struct {
+	Value string             `json:"v"`
+	ID    primitive.ObjectID `json:"id"`
+}
+
+// Encode returns the opaque representation of the cursor sent to the clients
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a cursor received from a client
+func Decode(cursor string) (Cursor, error) {
+	result := Cursor{}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return result, errors.New("Invalid cursor")
+	}
+
+	if err = json.Unmarshal(b, &result); err != nil || result.ID.IsZero() {
+		return result, errors.New("Invalid cursor")
+	}
+
+	return result, nil
+}
+
+// PageSize returns the size of the page requested by the client, bounded by MaxPageSize
+func PageSize(first *int) int {
+	if first == nil || *first <= 0 {
+		return DefaultPageSize
+	}
+
+	if *first > MaxPageSize {
+		return MaxPageSize
+	}
+
+	return *first
+}
@@ -0,0 +1,51 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/pagination"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("Should decode an encoded cursor", func(t *testing.T) {
+		cursor := pagination.Cursor{Value: "test1@test.com", ID: primitive.NewObjectID()}
+
+		decoded, err := pagination.Decode(cursor.Encode())
+
+		require.Empty(t, err)
+		require.Equal(t, cursor, decoded)
+	})
+
+	t.Run("Should return error if the cursor is invalid", func(t *testing.T) {
+		_, err := pagination.Decode("not a cursor")
+		require.NotEmpty(t, err)
+
+		_, err = pagination.Decode("e30")
+		require.NotEmpty(t, err)
+	})
+}
+
+func TestPageSize(t *testing.T) {
+	size := func(value int) *int {
+		return &value
+	}
+
+	testCases := []struct {
+		name     string
+		first    *int
+		expected int
+	}{
+		{name: "Should use the default size when no size is requested", first: nil, expected: pagination.DefaultPageSize},
+		{name: "Should use the default size when the requested size isn't positive", first: size(0), expected: pagination.DefaultPageSize},
+		{name: "Should use the requested size", first: size(5), expected: 5},
+		{name: "Should limit the requested size", first: size(1000), expected: pagination.MaxPageSize},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, pagination.PageSize(tc.first))
+		})
+	}
+}
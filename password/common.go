@@ -0,0 +1,16 @@
+package password
+
+// commonPasswords are some of the most used passwords on leaked databases, always rejected by the policy
+var commonPasswords = []string{
+	"123456", "123456789", "12345678", "12345", "1234567", "1234567890", "123123", "111111", "000000",
+	"654321", "666666", "121212", "112233", "123321", "987654321", "1q2w3e4r", "1q2w3e4r5t", "1qaz2wsx",
+	"qwerty", "qwerty123", "qwertyuiop", "asdfghjkl", "zxcvbnm", "qazwsx", "abc123", "abcd1234",
+	"password", "password1", "password123", "p@ssw0rd", "passw0rd", "p@ssword", "pa$$word",
+	"iloveyou", "letmein", "welcome", "welcome1", "welcome123", "admin", "admin123", "administrator",
+	"root", "toor", "login", "master", "monkey", "dragon", "football", "baseball", "superman", "batman",
+	"sunshine", "shadow", "princess", "trustno1", "starwars", "whatever", "freedom", "secret", "hello123",
+	"changeme", "default", "guest", "test", "test123", "qwe123", "zaq12wsx", "!qaz2wsx", "q1w2e3r4",
+	"q1w2e3r4t5", "aa123456", "1234qwer", "qwer1234", "summer2019", "winter2019", "spring2019", "autumn2019",
+	"summer2020", "winter2020", "passw0rd!", "password!", "password1!", "p@ssw0rd1", "p@ssw0rd!",
+	"welcome@123", "admin@123", "qwerty!", "qwerty1!", "letmein1", "iloveyou1", "michael", "jennifer",
+}
@@ -0,0 +1,194 @@
+// Package password validates the passwords chosen by the users against the password policy
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+)
+
+// MaxBytes is the longest password accepted. bcrypt ignores everything after the 72nd byte,
+// so longer passwords would be silently truncated
+const MaxBytes = 72
+
+// The rules of the password policy
+const (
+	RuleMinLength        = "min_length"
+	RuleMaxLength        = "max_length"
+	RuleCharacterClasses = "character_classes"
+	RuleEmail            = "email"
+	RuleCommonPassword   = "common_password"
+	RuleHistory          = "history"
+)
+
+// Violation is a rule of the password policy that a password failed
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy defines the rules a password must follow
+type Policy struct {
+	// MinLength is the minimum number of characters of the password
+	MinLength int
+
+	// MinCharacterClasses is how many of the classes lowercase, uppercase, digit and symbol the password must have
+	MinCharacterClasses int
+
+	// CommonPasswords are the lowercased passwords that can't be used
+	CommonPasswords map[string]bool
+
+	// HistorySize is how many previous passwords can't be reused
+	HistorySize int
+
+	// MaxAge is how long a password can be used before it must be changed. Zero means it never expires
+	MaxAge time.Duration
+}
+
+// PolicyFromEnv returns the policy defined by the PASSWORD_* environment variables. The passwords of
+// the PASSWORD_BLOCKLIST_FILE are rejected together with a built-in list of the most common passwords
+func PolicyFromEnv() (*Policy, error) {
+	policy := &Policy{
+		MinLength:           env.Config.PasswordMinLength,
+		MinCharacterClasses: env.Config.PasswordMinCharacterClasses,
+		CommonPasswords:     map[string]bool{},
+		HistorySize:         env.Config.PasswordHistorySize,
+		MaxAge:              env.Config.PasswordMaxAge,
+	}
+
+	for _, password := range commonPasswords {
+		policy.CommonPasswords[password] = true
+	}
+
+	if env.Config.PasswordBlocklistFile != "" {
+		passwords, err := LoadBlocklist(env.Config.PasswordBlocklistFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for password := range passwords {
+			policy.CommonPasswords[password] = true
+		}
+	}
+
+	return policy, nil
+}
+
+// LoadBlocklist reads a file with one password per line. Empty lines and lines starting with # are ignored
+func LoadBlocklist(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to open the password blocklist: %v", err)
+	}
+
+	defer file.Close()
+
+	passwords := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line != "" && !strings.HasPrefix(line, "#") {
+			passwords[strings.ToLower(line)] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error while trying to read the password blocklist: %v", err)
+	}
+
+	return passwords, nil
+}
+
+// Validate returns every rule of the policy the password of the account with the email fails, or nil if it's valid
+func (p *Policy) Validate(password, email string) []Violation {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("The password must have at least %d characters", p.MinLength),
+		})
+	}
+
+	if len(password) > MaxBytes {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("The password must have at most %d bytes", MaxBytes),
+		})
+	}
+
+	if characterClasses(password) < p.MinCharacterClasses {
+		violations = append(violations, Violation{
+			Rule:    RuleCharacterClasses,
+			Message: fmt.Sprintf("The password must have at least %d of: lowercase letters, uppercase letters, digits and symbols", p.MinCharacterClasses),
+		})
+	}
+
+	if containsEmail(password, email) {
+		violations = append(violations, Violation{
+			Rule:    RuleEmail,
+			Message: "The password can't contain the email",
+		})
+	}
+
+	if p.CommonPasswords[strings.ToLower(password)] {
+		violations = append(violations, Violation{
+			Rule:    RuleCommonPassword,
+			Message: "The password is too common",
+		})
+	}
+
+	return violations
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
+
+// containsEmail checks if the password contains the email or the part of the email before the @
+func containsEmail(password, email string) bool {
+	password = strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if email == "" {
+		return false
+	}
+
+	local := email
+
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		local = email[:at]
+	}
+
+	// Short local parts, like "jo", are too likely to show up by chance
+	if len(local) < 3 {
+		return strings.Contains(password, email)
+	}
+
+	return strings.Contains(password, local)
+}
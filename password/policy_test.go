@@ -0,0 +1,74 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/password"
+	"github.com/stretchr/testify/require"
+)
+
+func rules(violations []password.Violation) []string {
+	rules := []string{}
+
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule)
+	}
+
+	return rules
+}
+
+func TestValidate(t *testing.T) {
+	policy := &password.Policy{
+		MinLength:           8,
+		MinCharacterClasses: 3,
+		CommonPasswords:     map[string]bool{"p@ssw0rd1": true},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		expected []string
+	}{
+		{"Should accept a strong password", "Correct-Horse-9", "john@test.com", []string{}},
+		{"Should count the characters instead of the bytes", "Sen#a9çã", "john@test.com", []string{}},
+		{"Should reject an empty password", "", "john@test.com", []string{password.RuleMinLength, password.RuleCharacterClasses}},
+		{"Should reject a short password", "Ab1!", "john@test.com", []string{password.RuleMinLength}},
+		{"Should reject a password longer than bcrypt supports", "Aa1!" + strings.Repeat("a", 69), "john@test.com", []string{password.RuleMaxLength}},
+		{"Should reject a password without enough character classes", "onlylowercase", "john@test.com", []string{password.RuleCharacterClasses}},
+		{"Should reject a password containing the email", "John@Test.com1", "john@test.com", []string{password.RuleEmail}},
+		{"Should reject a password containing the local part of the email", "Johnny#2019", "johnny@test.com", []string{password.RuleEmail}},
+		{"Should reject a common password ignoring the case", "P@ssw0rd1", "john@test.com", []string{password.RuleCommonPassword}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, rules(policy.Validate(test.password, test.email)))
+		})
+	}
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	t.Run("Should load the passwords lowercased, ignoring comments and empty lines", func(t *testing.T) {
+		passwords, err := password.LoadBlocklist("testdata/blocklist.txt")
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]bool{"acme-corp-2019": true, "company!secret1": true}, passwords)
+	})
+
+	t.Run("Should return error if the file doesn't exist", func(t *testing.T) {
+		_, err := password.LoadBlocklist("testdata/missing.txt")
+
+		require.Error(t, err)
+	})
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Run("Should reject the built-in common passwords", func(t *testing.T) {
+		policy, err := password.PolicyFromEnv()
+
+		require.NoError(t, err)
+		require.Contains(t, rules(policy.Validate("Password123", "john@test.com")), password.RuleCommonPassword)
+	})
+}
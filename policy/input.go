@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Input is what is being evaluated: the action over the resource and the attributes available to the conditions,
+// grouped by namespace: "user", "token", "request" and "context"
+type Input struct {
+	Action     string
+	Resource   string
+	Attributes map[string]map[string]interface{}
+}
+
+// NewInput creates the input of an evaluation with the attributes of the user, its token claims, the request
+// and the context sent by the caller
+func NewInput(action, resource string, user *models.User, claims jsonwebtoken.Claims, request, context map[string]interface{}) Input {
+	return Input{
+		Action:   action,
+		Resource: resource,
+		Attributes: map[string]map[string]interface{}{
+			"user":    userAttributes(user, claims.Organization),
+			"token":   claimsAttributes(claims),
+			"request": request,
+			"context": context,
+		},
+	}
+}
+
+// Lookup returns the attribute on the path, ex: "user.roles". "action" and "resource" are available as well
+func (i Input) Lookup(path string) (interface{}, bool) {
+	switch path {
+	case "action":
+		return i.Action, true
+	case "resource":
+		return i.Resource, true
+	}
+
+	parts := strings.SplitN(path, ".", 2)
+
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	value, ok := i.Attributes[parts[0]][parts[1]]
+
+	return value, ok && value != nil
+}
+
+// RequestAttributes returns the "request" attributes: the ip, the user agent and the method injected on the context
+// by the RequestInfo middleware. Both the REST and the GraphQL authorization use it, so the rules see the same attributes
+func RequestAttributes(ctx context.Context) map[string]interface{} {
+	attributes := map[string]interface{}{}
+
+	for _, key := range []string{"ip", "userAgent", "method"} {
+		value := ""
+
+		if v := ctx.Value(key); v != nil {
+			value = fmt.Sprintf("%v", v)
+		}
+
+		attributes[key] = value
+	}
+
+	return attributes
+}
+
+func userAttributes(user *models.User, organizationID string) map[string]interface{} {
+	if user == nil {
+		return map[string]interface{}{}
+	}
+
+	organizations := make([]string, len(user.Memberships))
+
+	for i, membership := range user.Memberships {
+		organizations[i] = membership.OrganizationID.Hex()
+	}
+
+	attributes := map[string]interface{}{
+		"id":            user.ID.Hex(),
+		"email":         user.Email,
+		"roles":         user.Roles,
+		"active":        user.Active,
+		"organizations": organizations,
+	}
+
+	if objectID, err := primitive.ObjectIDFromHex(organizationID); err == nil {
+		if membership := user.MembershipOf(objectID); membership != nil {
+			attributes["role"] = membership.Role
+		}
+	}
+
+	return attributes
+}
+
+func claimsAttributes(claims jsonwebtoken.Claims) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"sub":    claims.Subject,
+		"iss":    claims.Issuer,
+		"groups": claims.Groups,
+		"exp":    claims.ExpiresAt,
+		"iat":    claims.IssuedAt,
+	}
+
+	if claims.Organization != "" {
+		attributes["org"] = claims.Organization
+	}
+
+	return attributes
+}
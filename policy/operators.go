@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+type operator func(actual interface{}, found bool, expected interface{}) bool
+
+// operators are the comparisons of the conditions. Every comparison is false when the attribute is missing, so a
+// rule never matches on an attribute the input doesn't have. Only exists checks the absence
+var operators = map[string]operator{
+	"equals": func(actual interface{}, found bool, expected interface{}) bool {
+		return found && equals(actual, expected)
+	},
+	"not_equals": func(actual interface{}, found bool, expected interface{}) bool {
+		return found && !equals(actual, expected)
+	},
+	// in checks if the attribute is one of the values of the list
+	"in": func(actual interface{}, found bool, expected interface{}) bool {
+		return found && containsValue(expected, actual)
+	},
+	// contains checks if the attribute, a list, has the value
+	"contains": func(actual interface{}, found bool, expected interface{}) bool {
+		return found && containsValue(actual, expected)
+	},
+	// intersects checks if the attribute, a list, has at least one of the values of the list
+	"intersects": func(actual interface{}, found bool, expected interface{}) bool {
+		if !found {
+			return false
+		}
+
+		for _, value := range toList(expected) {
+			if containsValue(actual, value) {
+				return true
+			}
+		}
+
+		return false
+	},
+	"starts_with": func(actual interface{}, found bool, expected interface{}) bool {
+		return found && strings.HasPrefix(fmt.Sprint(actual), fmt.Sprint(expected))
+	},
+	"exists": func(actual interface{}, found bool, expected interface{}) bool {
+		if exists, ok := expected.(bool); ok && !exists {
+			return !found
+		}
+
+		return found
+	},
+}
+
+// equals compares the values by their string representation, so numbers decoded
+// from JSON, YAML or GraphQL can be compared regardless of their go type
+func equals(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func containsValue(list, value interface{}) bool {
+	for _, item := range toList(list) {
+		if equals(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+
+		for i := range v {
+			list[i] = v[i]
+		}
+
+		return list
+	case nil:
+		return nil
+	default:
+		return []interface{}{v}
+	}
+}
@@ -0,0 +1,220 @@
+// Package policy evaluates declarative access rules over the attributes of the user, the token claims and the request
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Allow is the effect of a rule that grants the access
+	Allow = "allow"
+
+	// Deny is the effect of a rule that refuses the access. Deny rules always take precedence over allow rules
+	Deny = "deny"
+)
+
+// Policy represents a set of rules, as declared on the policy file
+type Policy struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule grants or refuses the actions on the resources when all of its conditions are met.
+// Actions and resources accept "*" as a wildcard, ex: "users:*", "documents/*"
+type Rule struct {
+	ID         string      `json:"id" yaml:"id"`
+	Effect     string      `json:"effect" yaml:"effect"`
+	Actions    []string    `json:"actions" yaml:"actions"`
+	Resources  []string    `json:"resources" yaml:"resources"`
+	Conditions []Condition `json:"conditions" yaml:"conditions"`
+
+	actions   []*regexp.Regexp
+	resources []*regexp.Regexp
+}
+
+// Condition compares an attribute of the input, ex: "user.roles", against a literal value or another attribute
+type Condition struct {
+	Attribute string      `json:"attribute" yaml:"attribute"`
+	Operator  string      `json:"operator" yaml:"operator"`
+	Value     interface{} `json:"value" yaml:"value"`
+
+	// ValueFrom is the path of an attribute used as the value, ex: "user.id"
+	ValueFrom string `json:"valueFrom" yaml:"valueFrom"`
+}
+
+// Decision is the result of an evaluation
+type Decision struct {
+	Allowed bool
+	RuleID  string
+	Reason  string
+}
+
+// Engine evaluates the inputs against a policy. An engine is safe for concurrent use
+type Engine struct {
+	rules []Rule
+}
+
+// Load reads a policy file, in JSON or YAML according to its extension, and returns an engine for it
+func Load(path string) (*Engine, error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to read the policy file: %v", err)
+	}
+
+	policy := Policy{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		err = yaml.UnmarshalStrict(content, &policy)
+	default:
+		err = json.Unmarshal(content, &policy)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error while trying to parse the policy file: %v", err)
+	}
+
+	return New(policy)
+}
+
+// FromEnv returns an engine for the rules of the POLICY_FILE. Without a policy file, every access is denied
+func FromEnv() (*Engine, error) {
+	if env.Config.PolicyFile == "" {
+		return New(Policy{})
+	}
+
+	return Load(env.Config.PolicyFile)
+}
+
+// New validates the policy and returns an engine for it
+func New(policy Policy) (*Engine, error) {
+	rules := make([]Rule, len(policy.Rules))
+
+	for i, rule := range policy.Rules {
+		if rule.Effect != Allow && rule.Effect != Deny {
+			return nil, fmt.Errorf("Invalid effect <%s> on rule %d", rule.Effect, i)
+		}
+
+		if len(rule.Actions) == 0 || len(rule.Resources) == 0 {
+			return nil, fmt.Errorf("The rule %d must have at least one action and one resource", i)
+		}
+
+		for _, condition := range rule.Conditions {
+			if _, ok := operators[condition.Operator]; !ok {
+				return nil, fmt.Errorf("Invalid operator <%s> on rule %d", condition.Operator, i)
+			}
+		}
+
+		rule.actions = compilePatterns(rule.Actions)
+		rule.resources = compilePatterns(rule.Resources)
+
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rule-%d", i)
+		}
+
+		rules[i] = rule
+	}
+
+	return &Engine{rules: rules}, nil
+}
+
+// Evaluate returns the decision for the input. Access is denied when no rule allows it,
+// or when any deny rule matches
+func (e *Engine) Evaluate(input Input) Decision {
+	var allowedBy *Rule
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+
+		if !rule.matches(input) {
+			continue
+		}
+
+		if rule.Effect == Deny {
+			return Decision{
+				Allowed: false,
+				RuleID:  rule.ID,
+				Reason:  fmt.Sprintf("Denied by rule %s", rule.ID),
+			}
+		}
+
+		if allowedBy == nil {
+			allowedBy = rule
+		}
+	}
+
+	if allowedBy == nil {
+		return Decision{
+			Allowed: false,
+			Reason:  "No rule allows the action on the resource",
+		}
+	}
+
+	return Decision{
+		Allowed: true,
+		RuleID:  allowedBy.ID,
+		Reason:  fmt.Sprintf("Allowed by rule %s", allowedBy.ID),
+	}
+}
+
+func (r *Rule) matches(input Input) bool {
+	if !matchAny(r.actions, input.Action) || !matchAny(r.resources, input.Resource) {
+		return false
+	}
+
+	for _, condition := range r.Conditions {
+		if !condition.holds(input) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Condition) holds(input Input) bool {
+	actual, found := input.Lookup(c.Attribute)
+	expected := c.Value
+
+	if c.ValueFrom != "" {
+		var ok bool
+		if expected, ok = input.Lookup(c.ValueFrom); !ok {
+			return false
+		}
+	}
+
+	return operators[c.Operator](actual, found, expected)
+}
+
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+
+	for i, pattern := range patterns {
+		parts := strings.Split(pattern, "*")
+
+		for j := range parts {
+			parts[j] = regexp.QuoteMeta(parts[j])
+		}
+
+		compiled[i] = regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+	}
+
+	return compiled
+}
+
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,284 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	organizationID, _ = primitive.ObjectIDFromHex("5d6e9d1b1c9d440000a1b2c3")
+	userID, _         = primitive.ObjectIDFromHex("5d6e9d1b1c9d440000a1b2d1")
+)
+
+func testPolicy(t *testing.T) *policy.Engine {
+	engine, err := policy.New(policy.Policy{
+		Rules: []policy.Rule{
+			{
+				ID:        "read-own-documents",
+				Effect:    policy.Allow,
+				Actions:   []string{"documents:read"},
+				Resources: []string{"documents/*"},
+				Conditions: []policy.Condition{
+					{Attribute: "context.owner", Operator: "equals", ValueFrom: "user.id"},
+				},
+			},
+			{
+				ID:        "on-call-reads-incidents",
+				Effect:    policy.Allow,
+				Actions:   []string{"incidents:*"},
+				Resources: []string{"incidents/*"},
+				Conditions: []policy.Condition{
+					{Attribute: "token.groups", Operator: "contains", Value: "on-call"},
+				},
+			},
+			{
+				ID:        "admins-manage-users",
+				Effect:    policy.Allow,
+				Actions:   []string{"users:*"},
+				Resources: []string{"*"},
+				Conditions: []policy.Condition{
+					{Attribute: "user.role", Operator: "in", Value: []interface{}{"owner", "admin"}},
+				},
+			},
+			{
+				ID:        "reports-outside-of-restricted-regions",
+				Effect:    policy.Allow,
+				Actions:   []string{"reports:read"},
+				Resources: []string{"reports/*"},
+				Conditions: []policy.Condition{
+					{Attribute: "context.region", Operator: "not_equals", Value: "restricted"},
+				},
+			},
+			{
+				ID:        "no-deletes-from-outside",
+				Effect:    policy.Deny,
+				Actions:   []string{"*:delete"},
+				Resources: []string{"*"},
+				Conditions: []policy.Condition{
+					{Attribute: "request.ip", Operator: "starts_with", Value: "10."},
+				},
+			},
+		},
+	})
+
+	require.Empty(t, err)
+
+	return engine
+}
+
+func testUser(role string) *models.User {
+	return &models.User{
+		ID:          userID,
+		Email:       "test5@test.com",
+		Roles:       []string{"user"},
+		Active:      true,
+		Memberships: []models.Membership{{OrganizationID: organizationID, Role: role}},
+	}
+}
+
+func testClaims(groups ...string) jsonwebtoken.Claims {
+	return jsonwebtoken.Claims{
+		Organization:   organizationID.Hex(),
+		Groups:         groups,
+		StandardClaims: jwt.StandardClaims{Subject: userID.Hex(), Issuer: "http://test.io"},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	engine := testPolicy(t)
+
+	testCases := []struct {
+		name     string
+		action   string
+		resource string
+		user     *models.User
+		claims   jsonwebtoken.Claims
+		request  map[string]interface{}
+		context  map[string]interface{}
+		allowed  bool
+		rule     string
+	}{
+		{
+			name:     "Should allow the owner of a document to read it",
+			action:   "documents:read",
+			resource: "documents/42",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims(),
+			context:  map[string]interface{}{"owner": userID.Hex()},
+			allowed:  true,
+			rule:     "read-own-documents",
+		},
+		{
+			name:     "Should not allow to read documents from another owner",
+			action:   "documents:read",
+			resource: "documents/42",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims(),
+			context:  map[string]interface{}{"owner": "someone-else"},
+			allowed:  false,
+		},
+		{
+			name:     "Should not allow when the attribute used by the condition is missing",
+			action:   "documents:read",
+			resource: "documents/42",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims(),
+			allowed:  false,
+		},
+		{
+			name:     "Should allow when the attribute is different from the value",
+			action:   "reports:read",
+			resource: "reports/1",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims(),
+			context:  map[string]interface{}{"region": "eu"},
+			allowed:  true,
+			rule:     "reports-outside-of-restricted-regions",
+		},
+		{
+			name:     "Should not allow when the attribute compared with not_equals is missing",
+			action:   "reports:read",
+			resource: "reports/1",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims(),
+			allowed:  false,
+		},
+		{
+			name:     "Should allow based on the groups of the token",
+			action:   "incidents:acknowledge",
+			resource: "incidents/7",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims("engineering", "on-call"),
+			allowed:  true,
+			rule:     "on-call-reads-incidents",
+		},
+		{
+			name:     "Should not allow users outside of the group",
+			action:   "incidents:acknowledge",
+			resource: "incidents/7",
+			user:     testUser(models.RoleMember),
+			claims:   testClaims("engineering"),
+			allowed:  false,
+		},
+		{
+			name:     "Should allow based on the organization role of the user",
+			action:   "users:delete",
+			resource: "users/5d470b3e98b0116d7d8ca48c",
+			user:     testUser(models.RoleAdmin),
+			claims:   testClaims(),
+			request:  map[string]interface{}{"ip": "192.168.0.1"},
+			allowed:  true,
+			rule:     "admins-manage-users",
+		},
+		{
+			name:     "Should let deny rules take precedence over allow rules",
+			action:   "users:delete",
+			resource: "users/5d470b3e98b0116d7d8ca48c",
+			user:     testUser(models.RoleAdmin),
+			claims:   testClaims(),
+			request:  map[string]interface{}{"ip": "10.0.0.1"},
+			allowed:  false,
+			rule:     "no-deletes-from-outside",
+		},
+		{
+			name:     "Should deny when no rule matches the action",
+			action:   "billing:read",
+			resource: "invoices/1",
+			user:     testUser(models.RoleOwner),
+			claims:   testClaims("on-call"),
+			allowed:  false,
+		},
+		{
+			name:     "Should deny anonymous inputs",
+			action:   "users:read",
+			resource: "users/1",
+			allowed:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision := engine.Evaluate(policy.NewInput(tc.action, tc.resource, tc.user, tc.claims, tc.request, tc.context))
+
+			require.Equal(t, tc.allowed, decision.Allowed)
+			require.Equal(t, tc.rule, decision.RuleID)
+			require.NotEmpty(t, decision.Reason)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule policy.Rule
+	}{
+		{
+			name: "Should not accept an unknown effect",
+			rule: policy.Rule{Effect: "maybe", Actions: []string{"*"}, Resources: []string{"*"}},
+		},
+		{
+			name: "Should not accept a rule without actions",
+			rule: policy.Rule{Effect: policy.Allow, Resources: []string{"*"}},
+		},
+		{
+			name: "Should not accept an unknown operator",
+			rule: policy.Rule{
+				Effect:     policy.Allow,
+				Actions:    []string{"*"},
+				Resources:  []string{"*"},
+				Conditions: []policy.Condition{{Attribute: "user.id", Operator: "like"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := policy.New(policy.Policy{Rules: []policy.Rule{tc.rule}})
+
+			require.NotEmpty(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{name: "Should load a policy written in YAML", path: "testdata/policy.yml"},
+		{name: "Should load a policy written in JSON", path: "testdata/policy.json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine, err := policy.Load(tc.path)
+
+			require.Empty(t, err)
+
+			decision := engine.Evaluate(policy.NewInput("users:update", "users/1", testUser(models.RoleAdmin), testClaims(), nil, nil))
+
+			require.True(t, decision.Allowed)
+			require.Equal(t, "admins-manage-users", decision.RuleID)
+		})
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	t.Run("Should read the attributes of the request from the context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "ip", "10.0.0.1")
+		ctx = context.WithValue(ctx, "method", "POST")
+
+		require.Equal(t, map[string]interface{}{
+			"ip":        "10.0.0.1",
+			"userAgent": "",
+			"method":    "POST",
+		}, policy.RequestAttributes(ctx))
+	})
+}
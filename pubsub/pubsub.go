@@ -0,0 +1,97 @@
+// Package pubsub delivers the events to the live subscriptions of the clients, like the GraphQL subscriptions
+// of the admin dashboard. The subscribers only get the events published while they're subscribed
+package pubsub
+
+import (
+	"log"
+	"sync"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+// BufferSize is how many events are kept for a subscriber that is still handling the previous ones. The
+// events published to a full subscriber are dropped, so a slow client never holds the publisher
+const BufferSize = 64
+
+// Filter selects the events delivered to a subscriber
+type Filter func(event models.AuditEvent) bool
+
+// PubSub delivers the published events to the subscribers. Implementations must be safe for concurrent use
+type PubSub interface {
+	// Publish delivers the event to all the current subscribers accepting it
+	Publish(event models.AuditEvent) error
+
+	// Subscribe returns the channel of the events accepted by the filter. The channel is closed by cancel,
+	// which must be called once the subscriber is done
+	Subscribe(filter Filter) (events <-chan models.AuditEvent, cancel func())
+}
+
+type subscriber struct {
+	filter Filter
+	events chan models.AuditEvent
+}
+
+// Memory is a PubSub kept in memory. It only reaches the subscribers of the same process, so a server with many
+// instances must use a distributed implementation, backed by a message broker
+type Memory struct {
+	mutex       sync.RWMutex
+	subscribers map[int]*subscriber
+	next        int
+}
+
+// NewMemory creates an empty Memory
+func NewMemory() *Memory {
+	return &Memory{subscribers: map[int]*subscriber{}}
+}
+
+// Publish sends the event to the subscribers accepting it, without waiting for them to receive it
+func (p *Memory) Publish(event models.AuditEvent) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, s := range p.subscribers {
+		if s.filter != nil && !s.filter(event) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			log.Printf("WARN: dropping the event %s of a slow subscriber", event.ID.Hex())
+		}
+	}
+
+	return nil
+}
+
+// Subscribe adds a subscriber for the events accepted by the filter. A nil filter accepts all the events
+func (p *Memory) Subscribe(filter Filter) (<-chan models.AuditEvent, func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	id := p.next
+	p.next++
+
+	s := &subscriber{filter: filter, events: make(chan models.AuditEvent, BufferSize)}
+	p.subscribers[id] = s
+
+	var once sync.Once
+
+	return s.events, func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+
+			delete(p.subscribers, id)
+			close(s.events)
+		})
+	}
+}
+
+// Subscribers returns how many subscribers are listening to the events
+func (p *Memory) Subscribers() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.subscribers)
+}
@@ -0,0 +1,59 @@
+package pubsub_test
+
+import (
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemory(t *testing.T) {
+	t.Run("Should deliver the events accepted by the filter of each subscriber", func(t *testing.T) {
+		p := pubsub.NewMemory()
+		userID := primitive.NewObjectID()
+
+		all, cancelAll := p.Subscribe(nil)
+		defer cancelAll()
+
+		mine, cancelMine := p.Subscribe(func(event models.AuditEvent) bool {
+			return event.TargetID == userID
+		})
+		defer cancelMine()
+
+		require.NoError(t, p.Publish(models.AuditEvent{Action: models.AuditUserCreated, TargetID: primitive.NewObjectID()}))
+		require.NoError(t, p.Publish(models.AuditEvent{Action: models.AuditSessionsRevoked, TargetID: userID}))
+
+		require.Equal(t, models.AuditUserCreated, (<-all).Action)
+		require.Equal(t, models.AuditSessionsRevoked, (<-all).Action)
+		require.Equal(t, models.AuditSessionsRevoked, (<-mine).Action)
+		require.Equal(t, 0, len(mine))
+	})
+
+	t.Run("Should close the channel and stop the deliveries when the subscription is cancelled", func(t *testing.T) {
+		p := pubsub.NewMemory()
+		events, cancel := p.Subscribe(nil)
+
+		cancel()
+		cancel()
+
+		require.Equal(t, 0, p.Subscribers())
+		require.NoError(t, p.Publish(models.AuditEvent{Action: models.AuditUserCreated}))
+
+		_, open := <-events
+		require.False(t, open)
+	})
+
+	t.Run("Should drop the events of a subscriber that isn't reading them", func(t *testing.T) {
+		p := pubsub.NewMemory()
+		events, cancel := p.Subscribe(nil)
+		defer cancel()
+
+		for i := 0; i < pubsub.BufferSize+10; i++ {
+			require.NoError(t, p.Publish(models.AuditEvent{Action: models.AuditUserUpdated}))
+		}
+
+		require.Equal(t, pubsub.BufferSize, len(events))
+	})
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize is the number of buckets that triggers the removal of the full ones
+const sweepSize = 10000
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+// MemoryStore keeps the token buckets in memory, so each instance of the server has its own buckets
+type MemoryStore struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+// Take takes the tokens of every cost, only if all the buckets have enough of them, and returns how long
+// the client must wait before they are available, or zero if the tokens were taken. It returns ErrCostTooHigh
+// when a cost is larger than the size of its bucket
+func (s *MemoryStore) Take(costs []Cost, now time.Time) (time.Duration, error) {
+	for _, cost := range costs {
+		if cost.Tokens > cost.Limit.Requests {
+			return 0, ErrCostTooHigh
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	buckets := make([]*bucket, len(costs))
+	var wait time.Duration
+
+	for i, cost := range costs {
+		buckets[i] = s.bucket(cost.Key, cost.Limit, now)
+
+		if missing := float64(cost.Tokens) - buckets[i].tokens; missing > 0 {
+			if retryAfter := time.Duration(missing / buckets[i].rate()); retryAfter > wait {
+				wait = retryAfter
+			}
+		}
+	}
+
+	if wait > 0 {
+		return wait, nil
+	}
+
+	for i, cost := range costs {
+		buckets[i].tokens -= float64(cost.Tokens)
+	}
+
+	return 0, nil
+}
+
+// bucket returns the bucket of the key, refilled up to now, creating a full one when it doesn't exist
+func (s *MemoryStore) bucket(key string, limit Limit, now time.Time) *bucket {
+	b, ok := s.buckets[key]
+
+	if !ok {
+		if len(s.buckets) >= sweepSize {
+			s.sweep(now)
+		}
+
+		b = &bucket{tokens: float64(limit.Requests), updatedAt: now, limit: limit}
+		s.buckets[key] = b
+	}
+
+	b.limit = limit
+	b.refill(now)
+
+	return b
+}
+
+// sweep removes the buckets that are full, since they behave like a bucket that was never used
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// rate returns how many tokens are added to the bucket per nanosecond
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / float64(b.limit.Period)
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens += float64(elapsed) * b.rate()
+		b.updatedAt = now
+	}
+
+	if capacity := float64(b.limit.Requests); b.tokens > capacity {
+		b.tokens = capacity
+	}
+}
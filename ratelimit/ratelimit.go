@@ -0,0 +1,150 @@
+// Package ratelimit limits how often each client can call the GraphQL operations, using token buckets
+// keyed by the client and the operation
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOperation is the operation which limit is used by all the operations without their own limit
+const DefaultOperation = "*"
+
+// DefaultLimits are the limits used when the RATE_LIMITS environment variable isn't defined
+const DefaultLimits = "*=300/1m,login=20/1m,createUser=10/1m,refreshToken=60/1m,acceptInvitation=20/1m"
+
+// ErrCostTooHigh is returned when a request costs more tokens than a bucket holds, so it can never be allowed,
+// however long the client waits
+var ErrCostTooHigh = errors.New("The request costs more tokens than the rate limit allows")
+
+// Limit is the size of a token bucket: it holds up to Requests tokens, refilled at Requests per Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Cost is the number of tokens a request takes from the bucket of a key
+type Cost struct {
+	Key    string
+	Limit  Limit
+	Tokens int
+}
+
+// Store keeps the token buckets. A Store shared between the instances of the server, like one backed
+// by Redis, must take the tokens atomically
+type Store interface {
+	// Take takes the tokens of every cost, only if all the buckets have enough of them, and returns how long
+	// the client must wait before they are available, or zero if the tokens were taken. It returns ErrCostTooHigh,
+	// without taking any token, when a cost is larger than the size of its bucket
+	Take(costs []Cost, now time.Time) (time.Duration, error)
+}
+
+// Limiter decides if a client can call an operation
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+	now    func() time.Time
+}
+
+// New creates a Limiter with the limits per operation. The operations without a limit share the
+// limit of the DefaultOperation, and aren't limited if it isn't defined
+func New(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{
+		store:  store,
+		limits: limits,
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the operation for each of the clients, like the ip and the
+// user of the request, and returns how long they must wait when any of the buckets is empty
+func (l *Limiter) Allow(operation string, clients ...string) (time.Duration, error) {
+	return l.AllowAll([]string{operation}, clients...)
+}
+
+// AllowAll takes a token for each of the operations, repeated once per call, from the buckets of each of the
+// clients. No token is taken when any of the buckets doesn't have enough of them, so a request is either
+// counted whole or refused. A request costing more tokens than a bucket holds is refused with ErrCostTooHigh
+func (l *Limiter) AllowAll(operations []string, clients ...string) (time.Duration, error) {
+	tokens := map[string]int{}
+	names := []string{}
+
+	for _, operation := range operations {
+		if _, ok := l.limits[operation]; !ok {
+			operation = DefaultOperation
+		}
+
+		if _, ok := l.limits[operation]; !ok {
+			continue
+		}
+
+		if tokens[operation] == 0 {
+			names = append(names, operation)
+		}
+
+		tokens[operation]++
+	}
+
+	costs := []Cost{}
+
+	for _, client := range clients {
+		for _, operation := range names {
+			costs = append(costs, Cost{
+				Key:    client + ":" + operation,
+				Limit:  l.limits[operation],
+				Tokens: tokens[operation],
+			})
+		}
+	}
+
+	if len(costs) == 0 {
+		return 0, nil
+	}
+
+	return l.store.Take(costs, l.now())
+}
+
+// ParseLimits parses limits written as operation=requests/period separated by commas,
+// like "*=300/1m,login=20/1m"
+func ParseLimits(value string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid rate limit %q: expected operation=requests/period", entry)
+		}
+
+		rate := strings.SplitN(parts[1], "/", 2)
+
+		if len(rate) != 2 {
+			return nil, fmt.Errorf("Invalid rate limit %q: expected operation=requests/period", entry)
+		}
+
+		requests, err := strconv.Atoi(rate[0])
+
+		if err != nil || requests <= 0 {
+			return nil, fmt.Errorf("Invalid rate limit %q: the requests must be a positive number", entry)
+		}
+
+		period, err := time.ParseDuration(rate[1])
+
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("Invalid rate limit %q: the period must be a positive duration", entry)
+		}
+
+		limits[strings.TrimSpace(parts[0])] = Limit{Requests: requests, Period: period}
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,196 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+	now := time.Now()
+
+	take := func(store *ratelimit.MemoryStore, key string, now time.Time) (time.Duration, error) {
+		return store.Take([]ratelimit.Cost{{Key: key, Limit: limit, Tokens: 1}}, now)
+	}
+
+	t.Run("Should allow the requests until the bucket is empty", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		for i := 0; i < 2; i++ {
+			wait, err := take(store, "key", now)
+
+			require.NoError(t, err)
+			require.Equal(t, time.Duration(0), wait)
+		}
+
+		wait, err := take(store, "key", now)
+
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, wait)
+	})
+
+	t.Run("Should refill the bucket over time", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		take(store, "key", now)
+		take(store, "key", now)
+
+		wait, err := take(store, "key", now.Add(30*time.Second))
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), wait)
+
+		wait, err = take(store, "key", now.Add(40*time.Second))
+
+		require.NoError(t, err)
+		require.Equal(t, 20*time.Second, wait)
+	})
+
+	t.Run("Should keep a bucket per key", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		take(store, "a", now)
+		take(store, "a", now)
+
+		wait, err := take(store, "b", now)
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), wait)
+	})
+
+	t.Run("Should not take any token when a bucket doesn't have enough of them", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		take(store, "b", now)
+
+		wait, err := store.Take([]ratelimit.Cost{
+			{Key: "a", Limit: limit, Tokens: 2},
+			{Key: "b", Limit: limit, Tokens: 2},
+		}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, wait)
+
+		wait, err = store.Take([]ratelimit.Cost{{Key: "a", Limit: limit, Tokens: 2}}, now)
+
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), wait)
+	})
+
+	t.Run("Should refuse a cost larger than the bucket", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		_, err := store.Take([]ratelimit.Cost{{Key: "a", Limit: limit, Tokens: limit.Requests + 1}}, now)
+
+		require.Equal(t, ratelimit.ErrCostTooHigh, err)
+	})
+}
+
+func TestLimiter(t *testing.T) {
+	limits := map[string]ratelimit.Limit{
+		ratelimit.DefaultOperation: {Requests: 3, Period: time.Minute},
+		"login":                    {Requests: 1, Period: time.Minute},
+	}
+
+	t.Run("Should use the limit of the operation", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+		wait, _ := limiter.Allow("login", "ip:10.0.0.1")
+		require.Equal(t, time.Duration(0), wait)
+
+		wait, _ = limiter.Allow("login", "ip:10.0.0.1")
+		require.True(t, wait > 0)
+	})
+
+	t.Run("Should share the default limit between the operations without their own limit", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+		for _, operation := range []string{"users", "me", "groups"} {
+			wait, _ := limiter.Allow(operation, "ip:10.0.0.1")
+			require.Equal(t, time.Duration(0), wait)
+		}
+
+		wait, _ := limiter.Allow("organization", "ip:10.0.0.1")
+		require.True(t, wait > 0)
+	})
+
+	t.Run("Should refuse the request when the bucket of any client is empty", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+		limiter.Allow("login", "ip:10.0.0.1", "user:1")
+
+		wait, _ := limiter.Allow("login", "ip:10.0.0.2", "user:1")
+		require.True(t, wait > 0)
+	})
+
+	t.Run("Should count each call of an operation", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+		wait, _ := limiter.AllowAll([]string{"users", "users"}, "ip:10.0.0.1")
+		require.Equal(t, time.Duration(0), wait)
+
+		wait, _ = limiter.AllowAll([]string{"users", "users"}, "ip:10.0.0.1")
+		require.True(t, wait > 0)
+	})
+
+	t.Run("Should not take any token when the request is refused", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+		limiter.Allow("login", "ip:10.0.0.1")
+
+		wait, _ := limiter.AllowAll([]string{"users", "me", "login"}, "ip:10.0.0.1")
+		require.True(t, wait > 0)
+
+		wait, _ = limiter.AllowAll([]string{"users", "me", "groups"}, "ip:10.0.0.1")
+		require.Equal(t, time.Duration(0), wait)
+	})
+
+	t.Run("Should refuse a request calling an operation more times than its limit", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+		_, err := limiter.AllowAll([]string{"login", "login"}, "ip:10.0.0.1")
+		require.Equal(t, ratelimit.ErrCostTooHigh, err)
+
+		wait, err := limiter.Allow("login", "ip:10.0.0.1")
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), wait)
+	})
+
+	t.Run("Should not limit the operations when there's no default limit", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{})
+
+		for i := 0; i < 10; i++ {
+			wait, _ := limiter.Allow("users", "ip:10.0.0.1")
+			require.Equal(t, time.Duration(0), wait)
+		}
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	t.Run("Should parse the limits of each operation", func(t *testing.T) {
+		limits, err := ratelimit.ParseLimits("*=300/1m, login=20/30s")
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]ratelimit.Limit{
+			"*":     {Requests: 300, Period: time.Minute},
+			"login": {Requests: 20, Period: 30 * time.Second},
+		}, limits)
+	})
+
+	t.Run("Should parse the default limits", func(t *testing.T) {
+		_, err := ratelimit.ParseLimits(ratelimit.DefaultLimits)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Should return error for invalid limits", func(t *testing.T) {
+		for _, value := range []string{"login", "login=20", "login=0/1m", "login=20/soon"} {
+			_, err := ratelimit.ParseLimits(value)
+
+			require.Error(t, err, value)
+		}
+	})
+}
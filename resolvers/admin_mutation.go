@@ -0,0 +1,443 @@
+package resolvers
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
+	"github.com/LucasFrezarini/go-auth-manager/importer"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *mutationResolver) AdminCreateUser(ctx context.Context, data gqlmodels.CreateUserInput) (*models.User, error) {
+	caller, err := findCaller(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(data.Email)
+
+	if email == "" {
+		return nil, gqlerrors.CreateBadUserInputError("The email cannot be empty")
+	}
+
+	if err := r.validatePassword(data.Password, email); err != nil {
+		return nil, err
+	}
+
+	roles := data.Roles
+
+	if len(roles) == 0 {
+		roles = []string{"user"}
+	}
+
+	if !callerCanChangeRoles(caller, nil, roles) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	var organization *models.Organization
+	organizationID, scoped := organizationIDFromContext(ctx)
+
+	if scoped {
+		organization, err = organizationDao.FindByID(organizationID)
+
+		if err != nil {
+			log.Printf("Error while trying to create user: %v\n", err)
+			return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
+		}
+	}
+
+	if emailAlreadyExists(email, organization) {
+		return nil, gqlerrors.CreateConflictError("User already exists")
+	}
+
+	hash, err := crypt.HashPassword(data.Password)
+
+	if err != nil {
+		log.Printf("Error while trying to create user: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
+	}
+
+	active := true
+
+	if data.Active != nil {
+		active = *data.Active
+	}
+
+	user := models.User{
+		// The id is defined before the insert, since the event recorded along with the user points to it
+		ID:                primitive.NewObjectID(),
+		Email:             email,
+		Password:          hash,
+		PasswordChangedAt: time.Now(),
+		Roles:             roles,
+		Active:            active,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if scoped {
+		user.Memberships = []models.Membership{{
+			OrganizationID: organizationID,
+			Role:           models.RoleMember,
+			Managed:        true,
+		}}
+	}
+
+	event := auditEvent(ctx, models.AuditUserCreated, user.ID, map[string]interface{}{
+		"email":  user.Email,
+		"roles":  user.Roles,
+		"active": user.Active,
+	})
+
+	if _, err = userDao.CreateOne(user, event); err != nil {
+		log.Printf("Error while trying to create user: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
+	}
+
+	return &user, nil
+}
+
+func (r *mutationResolver) AdminUpdateUser(ctx context.Context, id string, data gqlmodels.AdminUpdateUserInput) (*models.User, error) {
+	caller, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.User{}
+
+	if data.Email != nil && strings.TrimSpace(*data.Email) != user.Email {
+		changes.Email = strings.TrimSpace(*data.Email)
+
+		if changes.Email == "" {
+			return nil, gqlerrors.CreateBadUserInputError("The email cannot be empty")
+		}
+
+		var organization *models.Organization
+
+		if organizationID, ok := organizationIDFromContext(ctx); ok {
+			organization = &models.Organization{ID: organizationID}
+		}
+
+		if emailAlreadyExists(changes.Email, organization) {
+			return nil, gqlerrors.CreateConflictError("User already exists")
+		}
+	}
+
+	if data.Roles != nil {
+		if !callerCanChangeRoles(caller, user.Roles, data.Roles) {
+			return nil, gqlerrors.CreateForbiddenError()
+		}
+
+		changes.Roles = data.Roles
+	}
+
+	events := []models.AuditEvent{}
+
+	if changes.Email != "" {
+		events = append(events, auditEvent(ctx, models.AuditUserUpdated, user.ID, map[string]interface{}{
+			"from": user.Email,
+			"to":   changes.Email,
+		}))
+	}
+
+	if changes.Roles != nil {
+		events = append(events, auditEvent(ctx, models.AuditRolesChanged, user.ID, map[string]interface{}{
+			"from": user.Roles,
+			"to":   changes.Roles,
+		}))
+	}
+
+	updatedUser, err := userDao.UpdateByID(user.ID, changes, events...)
+
+	if err != nil {
+		log.Printf("Error while trying to update user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to update user")
+	}
+
+	return updatedUser, nil
+}
+
+func (r *mutationResolver) AdminSetActive(ctx context.Context, id string, active bool) (*models.User, error) {
+	_, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	action := models.AuditUserActivated
+
+	if !active {
+		action = models.AuditUserDeactivated
+	}
+
+	updatedUser, err := userDao.SetActive(user.ID, active, auditEvent(ctx, action, user.ID, nil))
+
+	if err != nil {
+		log.Printf("Error while trying to change the user status: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to change the user status")
+	}
+
+	// A deactivated user must not be able to refresh the sessions it already has
+	if !active {
+		if _, err = refreshTokenDao.DeleteAll(user.ID); err != nil {
+			log.Printf("Error while trying to revoke the sessions of the deactivated user: %v", err)
+			return nil, gqlerrors.CreateInternalServerError("Error while trying to change the user status")
+		}
+	}
+
+	return updatedUser, nil
+}
+
+func (r *mutationResolver) AdminResetPassword(ctx context.Context, id string, password string) (*models.User, error) {
+	_, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.validatePassword(password, user.Email); err != nil {
+		return nil, err
+	}
+
+	hash, err := crypt.HashPassword(password)
+
+	if err != nil {
+		log.Printf("Error while trying to crypt password: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to reset the password")
+	}
+
+	event := auditEvent(ctx, models.AuditPasswordReset, user.ID, nil)
+
+	if _, err = userDao.ChangePassword(user, hash, r.PasswordPolicy.HistorySize, event); err != nil {
+		log.Printf("Error while trying to reset the password: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to reset the password")
+	}
+
+	updatedUser, err := refreshTokenDao.DeleteAll(user.ID)
+
+	if err != nil {
+		log.Printf("Error while trying to revoke the sessions after resetting the password: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to reset the password")
+	}
+
+	return &updatedUser, nil
+}
+
+func (r *mutationResolver) AdminDeleteUser(ctx context.Context, id string) (*models.User, error) {
+	_, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = userDao.DeleteByID(user.ID); err != nil {
+		log.Printf("Error while trying to delete user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to delete user")
+	}
+
+	for _, membership := range user.Memberships {
+		if err = groupDao.RemoveUserFromOrganization(membership.OrganizationID, user.ID); err != nil {
+			log.Printf("Error while trying to remove the deleted user from its groups: %v", err)
+		}
+	}
+
+	recordAuditEvent(ctx, models.AuditUserDeleted, user.ID, map[string]interface{}{
+		"email": user.Email,
+	})
+
+	return user, nil
+}
+
+func (r *mutationResolver) AdminRevokeSessions(ctx context.Context, id string) (*models.User, error) {
+	_, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := refreshTokenDao.DeleteAll(user.ID)
+
+	if err != nil {
+		log.Printf("Error while trying to revoke the sessions: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to revoke the sessions")
+	}
+
+	recordAuditEvent(ctx, models.AuditSessionsRevoked, user.ID, map[string]interface{}{
+		"sessions": len(user.RefreshTokens),
+	})
+
+	return &updatedUser, nil
+}
+
+// AdminUnlockUser clears the failed logins of an user, so it can login again before its lockout expires. The IPs
+// its logins failed from are unlocked as well, which clears the failures of the other accounts from those IPs
+func (r *mutationResolver) AdminUnlockUser(ctx context.Context, id string) (*models.User, error) {
+	_, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := auditEventDao.FailedLoginIPs(user.ID, r.Lockout.ActiveSince())
+
+	if err != nil {
+		log.Printf("Error while trying to unlock the user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to unlock the user")
+	}
+
+	if err := r.Lockout.Unlock(user.Email, ips...); err != nil {
+		log.Printf("Error while trying to unlock the user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to unlock the user")
+	}
+
+	recordAuditEvent(ctx, models.AuditUserUnlocked, user.ID, nil)
+
+	return user, nil
+}
+
+func (r *mutationResolver) AdminImportUsers(ctx context.Context, format gqlmodels.ImportFormat, data string) (*importer.Result, error) {
+	caller, err := findCaller(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options := importer.Options{
+		// The imported roles follow the same rules of the roles given through adminCreateUser
+		AllowRoles: func(roles []string) bool {
+			return callerCanChangeRoles(caller, nil, roles)
+		},
+		// Each imported user is created like through adminCreateUser, so its creation is published the same way
+		Event: func(user models.User) models.AuditEvent {
+			return auditEvent(ctx, models.AuditUserCreated, user.ID, map[string]interface{}{
+				"email":    user.Email,
+				"roles":    user.Roles,
+				"active":   user.Active,
+				"imported": true,
+			})
+		},
+	}
+
+	if organizationID, scoped := organizationIDFromContext(ctx); scoped {
+		options.OrganizationID = organizationID
+	}
+
+	records, failures, err := importer.Parse(strings.NewReader(data), strings.ToLower(string(format)))
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError(err.Error())
+	}
+
+	result, err := importer.Import(&userDao, records, options)
+
+	if err != nil {
+		log.Printf("Error while trying to import users: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to import users")
+	}
+
+	result.Failures = append(failures, result.Failures...)
+
+	recordAuditEvent(ctx, models.AuditUsersImported, primitive.NilObjectID, map[string]interface{}{
+		"format":     strings.ToLower(string(format)),
+		"imported":   result.Imported,
+		"duplicates": len(result.Duplicates),
+		"failures":   len(result.Failures),
+	})
+
+	return result, nil
+}
+
+func (r *mutationResolver) AdminExportUser(ctx context.Context, id string) (string, error) {
+	_, user, err := findManagedUser(ctx, id)
+
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := exportUser(ctx, user)
+
+	if err != nil {
+		log.Printf("Error while trying to export the data of the user: %v", err)
+		return "", gqlerrors.CreateInternalServerError("Error while trying to export the data of the user")
+	}
+
+	return archive, nil
+}
+
+// findCaller returns the authenticated user
+func findCaller(ctx context.Context) (*models.User, error) {
+	callerID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	caller, err := userDao.FindByID(callerID)
+
+	if err != nil {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	return caller, nil
+}
+
+// findManagedUser returns the caller and the user with the id, if the caller is allowed to manage its account.
+// Sysadmins manage every account, while organization admins only manage the accounts that belong exclusively
+// to their organization and were created, imported or invited by it, so they can't affect the users of other
+// tenants, nor the existing accounts that joined the organization
+func findManagedUser(ctx context.Context, id string) (*models.User, *models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, nil, gqlerrors.CreateBadUserInputError("Invalid user id")
+	}
+
+	caller, err := findCaller(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := userDao.FindByID(objectID)
+
+	if err != nil {
+		return nil, nil, gqlerrors.CreateNotFoundError("User not found")
+	}
+
+	if caller.HasRole(models.RoleSysadmin) {
+		return caller, user, nil
+	}
+
+	organizationID, _ := organizationIDFromContext(ctx)
+	membership := user.MembershipOf(organizationID)
+
+	if membership == nil {
+		return nil, nil, gqlerrors.CreateNotFoundError("User not found")
+	}
+
+	if !membership.Managed || len(user.Memberships) > 1 || user.HasRole(models.RoleSysadmin) || !callerCanManageRole(ctx, organizationID, membership.Role) {
+		return nil, nil, gqlerrors.CreateForbiddenError()
+	}
+
+	return caller, user, nil
+}
+
+// callerCanChangeRoles checks if the caller can change the global roles of an user. Only sysadmins can give or take the sysadmin role
+func callerCanChangeRoles(caller *models.User, from, to []string) bool {
+	if caller.HasRole(models.RoleSysadmin) {
+		return true
+	}
+
+	before := &models.User{Roles: from}
+	after := &models.User{Roles: to}
+
+	return before.HasRole(models.RoleSysadmin) == after.HasRole(models.RoleSysadmin)
+}
@@ -0,0 +1,96 @@
+package resolvers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reasons of the failed logins, recorded on the metadata of their audit events
+const (
+	loginFailureLocked              = "locked"
+	loginFailureUnknownOrganization = "unknown_organization"
+	loginFailureUnknownUser         = "unknown_user"
+	loginFailureInvalidPassword     = "invalid_password"
+)
+
+// recordAuditEvent stores the action done by the caller over the target user. A failure to record the
+// event is only logged, so it doesn't prevent the action from completing
+func recordAuditEvent(ctx context.Context, action string, targetID primitive.ObjectID, metadata map[string]interface{}) {
+	recordEvent(auditEvent(ctx, action, targetID, metadata))
+}
+
+// auditEvent returns the action done by the caller over the target user, with the attributes of the request.
+// It's used when the event is recorded by the dao, along with the change it describes
+func auditEvent(ctx context.Context, action string, targetID primitive.ObjectID, metadata map[string]interface{}) models.AuditEvent {
+	actorID, _ := userIDFromContext(ctx)
+	organizationID, _ := organizationIDFromContext(ctx)
+
+	return requestEvent(ctx, models.AuditEvent{
+		Action:         action,
+		ActorID:        actorID,
+		TargetID:       targetID,
+		OrganizationID: organizationID,
+		Metadata:       metadata,
+	})
+}
+
+// recordUserAuditEvent stores an action the user does over its own account without being authenticated yet,
+// like the signup and the login, on the organization the user is entering, if any
+func recordUserAuditEvent(ctx context.Context, action string, user *models.User, organizationID string, metadata map[string]interface{}) {
+	recordEvent(userAuditEvent(ctx, action, user, organizationID, metadata))
+}
+
+// userAuditEvent returns an action the user does over its own account, with the attributes of the request
+func userAuditEvent(ctx context.Context, action string, user *models.User, organizationID string, metadata map[string]interface{}) models.AuditEvent {
+	event := models.AuditEvent{
+		Action:   action,
+		ActorID:  user.ID,
+		TargetID: user.ID,
+		Metadata: metadata,
+	}
+
+	event.OrganizationID, _ = primitive.ObjectIDFromHex(organizationID)
+
+	return requestEvent(ctx, event)
+}
+
+// recordLoginFailure stores a failed login. The user and the organization are nil when they weren't found
+func recordLoginFailure(ctx context.Context, email string, user *models.User, organization *models.Organization, reason string) {
+	event := models.AuditEvent{
+		Action: models.AuditLoginFailed,
+		Metadata: map[string]interface{}{
+			"email":  email,
+			"reason": reason,
+		},
+	}
+
+	if user != nil {
+		event.TargetID = user.ID
+	}
+
+	if organization != nil {
+		event.OrganizationID = organization.ID
+	}
+
+	recordEvent(requestEvent(ctx, event))
+}
+
+// requestEvent adds the attributes of the request and the current time to the event
+func requestEvent(ctx context.Context, event models.AuditEvent) models.AuditEvent {
+	event.IP = stringFromContext(ctx, "ip")
+	event.UserAgent = stringFromContext(ctx, "userAgent")
+	event.CreatedAt = time.Now()
+
+	return event
+}
+
+// recordEvent stores the event and adds it to the outbox, which publishes it to the sinks, like the webhooks
+func recordEvent(event models.AuditEvent) {
+	if err := auditEventDao.Record(event); err != nil {
+		log.Printf("Error while trying to record the audit event %s of %s: %v", event.Action, event.TargetID.Hex(), err)
+	}
+}
@@ -0,0 +1,41 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type auditEventResolver struct{ *Resolver }
+
+func (r *auditEventResolver) ID(ctx context.Context, obj *models.AuditEvent) (string, error) {
+	return obj.ID.Hex(), nil
+}
+
+func (r *auditEventResolver) ActorID(ctx context.Context, obj *models.AuditEvent) (*string, error) {
+	return optionalObjectID(obj.ActorID), nil
+}
+
+func (r *auditEventResolver) TargetID(ctx context.Context, obj *models.AuditEvent) (*string, error) {
+	return optionalObjectID(obj.TargetID), nil
+}
+
+func (r *auditEventResolver) OrganizationID(ctx context.Context, obj *models.AuditEvent) (*string, error) {
+	return optionalObjectID(obj.OrganizationID), nil
+}
+
+func (r *auditEventResolver) CreatedAt(ctx context.Context, obj *models.AuditEvent) (string, error) {
+	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+// optionalObjectID returns the hex of the id, or nil when the id is empty
+func optionalObjectID(id primitive.ObjectID) *string {
+	if id.IsZero() {
+		return nil
+	}
+
+	hex := id.Hex()
+
+	return &hex
+}
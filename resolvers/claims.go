@@ -16,6 +16,14 @@ func (r *claimsResolver) Sub(ctx context.Context, obj *jsonwebtoken.Claims) (str
 	return obj.Subject, nil
 }
 
+func (r *claimsResolver) Org(ctx context.Context, obj *jsonwebtoken.Claims) (*string, error) {
+	if obj.Organization == "" {
+		return nil, nil
+	}
+
+	return &obj.Organization, nil
+}
+
 func (r *claimsResolver) Exp(ctx context.Context, obj *jsonwebtoken.Claims) (int, error) {
 	return int(obj.ExpiresAt), nil
 }
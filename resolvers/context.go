@@ -0,0 +1,44 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// userIDFromContext returns the id of the authenticated user, injected on the context by the AuthHandler
+func userIDFromContext(ctx context.Context) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(fmt.Sprintf("%v", ctx.Value("userID")))
+}
+
+// organizationIDFromContext returns the id of the organization the token of the authenticated user was issued for.
+// The second value is false when the token isn't scoped to any organization
+func organizationIDFromContext(ctx context.Context) (primitive.ObjectID, bool) {
+	organizationID, ok := ctx.Value("organizationID").(string)
+
+	if !ok || organizationID == "" {
+		return primitive.NilObjectID, false
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(organizationID)
+
+	return objectID, err == nil
+}
+
+// claimsFromContext returns the claims of the token used on the request, injected on the context by the AuthHandler
+func claimsFromContext(ctx context.Context) jsonwebtoken.Claims {
+	claims, _ := ctx.Value("claims").(jsonwebtoken.Claims)
+
+	return claims
+}
+
+// stringFromContext returns the value of the key on the context as a string, or an empty string if it isn't set
+func stringFromContext(ctx context.Context, key string) string {
+	if value := ctx.Value(key); value != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return ""
+}
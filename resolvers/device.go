@@ -0,0 +1,55 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/notifier"
+)
+
+// notifyNewDevice warns the user when the login comes from an IP or a device it never used before, sending
+// the "this wasn't me" link that revokes all of its sessions. It must be called before the login is recorded.
+// The notification is sent in the background, so the login doesn't wait for the mail server, and its failures
+// are only logged
+func (r *mutationResolver) notifyNewDevice(ctx context.Context, user *models.User) {
+	ip := stringFromContext(ctx, "ip")
+	userAgent := stringFromContext(ctx, "userAgent")
+
+	ips, userAgents, err := auditEventDao.LoginOrigins(user.ID)
+
+	if err != nil {
+		log.Printf("Error while trying to check the device of the login: %v", err)
+		return
+	}
+
+	if !(notifier.Origins{IPs: ips, UserAgents: userAgents}).IsNew(ip, userAgent) {
+		return
+	}
+
+	token, err := jsonwebtoken.Encode(jsonwebtoken.CreateRevokeSessionsClaims(user.ID.Hex()))
+
+	if err != nil {
+		log.Printf("Error while trying to notify the new device: %v", err)
+		return
+	}
+
+	notification := notifier.NewDevice{
+		Email:     user.Email,
+		IP:        ip,
+		UserAgent: userAgent,
+		At:        time.Now(),
+		RevokeURL: fmt.Sprintf("%s/sessions/revoke?token=%s", env.Config.AppURL, url.QueryEscape(token)),
+	}
+
+	go func() {
+		if err := r.Notifier.NotifyNewDevice(notification); err != nil {
+			log.Printf("Error while trying to notify the new device: %v", err)
+		}
+	}()
+}
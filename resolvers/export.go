@@ -0,0 +1,58 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/dataexport"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// exportUser builds the JSON archive with the data of the user and the related collections
+func exportUser(ctx context.Context, user *models.User) (string, error) {
+	related := dataexport.Related{}
+
+	if len(user.Memberships) > 0 {
+		ids := make([]primitive.ObjectID, len(user.Memberships))
+
+		for i, membership := range user.Memberships {
+			ids[i] = membership.OrganizationID
+		}
+
+		organizations, err := organizationDao.GetAllByIDs(ids)
+
+		if err != nil {
+			return "", err
+		}
+
+		related.Organizations = organizations
+	}
+
+	groups, err := groupDao.GetAllByUser(user.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	events, err := auditEventDao.GetAllByTarget(user.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	related.Groups = groups
+	related.Events = events
+
+	archive, err := json.MarshalIndent(dataexport.New(user, related, time.Now().UTC()), "", "  ")
+
+	if err != nil {
+		return "", fmt.Errorf("Error while trying to encode the archive: %v", err)
+	}
+
+	recordAuditEvent(ctx, models.AuditDataExported, user.ID, nil)
+
+	return string(archive), nil
+}
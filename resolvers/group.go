@@ -0,0 +1,37 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+type groupResolver struct{ *Resolver }
+
+func (r *groupResolver) ID(ctx context.Context, obj *models.Group) (string, error) {
+	return obj.ID.Hex(), nil
+}
+
+func (r *groupResolver) Users(ctx context.Context, obj *models.Group) ([]*models.User, error) {
+	if len(obj.UserIDs) == 0 {
+		return []*models.User{}, nil
+	}
+
+	return userDao.GetAllByIDs(obj.UserIDs)
+}
+
+func (r *groupResolver) Groups(ctx context.Context, obj *models.Group) ([]*models.Group, error) {
+	if len(obj.GroupIDs) == 0 {
+		return []*models.Group{}, nil
+	}
+
+	return groupDao.GetAllByIDs(obj.GroupIDs)
+}
+
+func (r *groupResolver) CreatedAt(ctx context.Context, obj *models.Group) (string, error) {
+	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+func (r *groupResolver) UpdatedAt(ctx context.Context, obj *models.Group) (string, error) {
+	return obj.UpdatedAt.Format("2006-01-02 15:04:05"), nil
+}
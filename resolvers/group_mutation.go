@@ -0,0 +1,195 @@
+package resolvers
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *mutationResolver) CreateGroup(ctx context.Context, name string) (*models.Group, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return nil, gqlerrors.CreateBadUserInputError("The group name cannot be empty")
+	}
+
+	if registered, _ := groupDao.FindByName(organizationID, name); registered != nil {
+		return nil, gqlerrors.CreateConflictError("Group already exists")
+	}
+
+	group := models.Group{
+		OrganizationID: organizationID,
+		Name:           name,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	insertedID, err := groupDao.CreateOne(group)
+
+	if err != nil {
+		log.Printf("Error while trying to create group: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create group")
+	}
+
+	group.ID = insertedID
+
+	return &group, nil
+}
+
+func (r *mutationResolver) DeleteGroup(ctx context.Context, id string) (*models.Group, error) {
+	group, err := findOrganizationGroup(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = groupDao.DeleteByID(group.ID); err != nil {
+		log.Printf("Error while trying to delete group: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to delete group")
+	}
+
+	return group, nil
+}
+
+func (r *mutationResolver) AddGroupMember(ctx context.Context, groupID string, userID string) (*models.Group, error) {
+	group, err := findOrganizationGroup(ctx, groupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid user id")
+	}
+
+	user, err := userDao.FindByID(objectID)
+
+	if err != nil || user.MembershipOf(group.OrganizationID) == nil {
+		return nil, gqlerrors.CreateNotFoundError("User not found")
+	}
+
+	updatedGroup, err := groupDao.AddUser(group.ID, user.ID)
+
+	if err != nil {
+		log.Printf("Error while trying to add group member: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to add group member")
+	}
+
+	return updatedGroup, nil
+}
+
+func (r *mutationResolver) RemoveGroupMember(ctx context.Context, groupID string, userID string) (*models.Group, error) {
+	group, err := findOrganizationGroup(ctx, groupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid user id")
+	}
+
+	updatedGroup, err := groupDao.RemoveUser(group.ID, objectID)
+
+	if err != nil {
+		log.Printf("Error while trying to remove group member: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to remove group member")
+	}
+
+	return updatedGroup, nil
+}
+
+func (r *mutationResolver) AddSubgroup(ctx context.Context, groupID string, subgroupID string) (*models.Group, error) {
+	group, err := findOrganizationGroup(ctx, groupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	subgroup, err := findOrganizationGroup(ctx, subgroupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := groupDao.GetAllByOrganization(group.OrganizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to add subgroup: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to add subgroup")
+	}
+
+	if group.ID == subgroup.ID || models.ContainsGroup(groups, subgroup.ID, group.ID) {
+		return nil, gqlerrors.CreateBadUserInputError("A group cannot be nested inside itself")
+	}
+
+	updatedGroup, err := groupDao.AddSubgroup(group.ID, subgroup.ID)
+
+	if err != nil {
+		log.Printf("Error while trying to add subgroup: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to add subgroup")
+	}
+
+	return updatedGroup, nil
+}
+
+func (r *mutationResolver) RemoveSubgroup(ctx context.Context, groupID string, subgroupID string) (*models.Group, error) {
+	group, err := findOrganizationGroup(ctx, groupID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(subgroupID)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid group id")
+	}
+
+	updatedGroup, err := groupDao.RemoveSubgroup(group.ID, objectID)
+
+	if err != nil {
+		log.Printf("Error while trying to remove subgroup: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to remove subgroup")
+	}
+
+	return updatedGroup, nil
+}
+
+// findOrganizationGroup returns the group with the id, if it belongs to the organization of the caller
+func findOrganizationGroup(ctx context.Context, id string) (*models.Group, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid group id")
+	}
+
+	group, err := groupDao.FindByID(objectID)
+
+	if err != nil || group.OrganizationID != organizationID {
+		return nil, gqlerrors.CreateNotFoundError("Group not found")
+	}
+
+	return group, nil
+}
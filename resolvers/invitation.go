@@ -0,0 +1,25 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+type invitationResolver struct{ *Resolver }
+
+func (r *invitationResolver) ID(ctx context.Context, obj *models.Invitation) (string, error) {
+	return obj.ID.Hex(), nil
+}
+
+func (r *invitationResolver) Organization(ctx context.Context, obj *models.Invitation) (*models.Organization, error) {
+	return organizationDao.FindByID(obj.OrganizationID)
+}
+
+func (r *invitationResolver) ExpiresAt(ctx context.Context, obj *models.Invitation) (string, error) {
+	return obj.ExpiresAt.Format("2006-01-02 15:04:05"), nil
+}
+
+func (r *invitationResolver) CreatedAt(ctx context.Context, obj *models.Invitation) (string, error) {
+	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
+}
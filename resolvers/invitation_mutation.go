@@ -0,0 +1,249 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
+	"github.com/LucasFrezarini/go-auth-manager/mailer"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *mutationResolver) InviteUser(ctx context.Context, email string, role string) (*models.Invitation, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	email = strings.TrimSpace(email)
+
+	if email == "" {
+		return nil, gqlerrors.CreateBadUserInputError("The email cannot be empty")
+	}
+
+	if !isOrganizationRole(role) {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid organization role")
+	}
+
+	if !callerCanManageRole(ctx, organizationID, role) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to invite user")
+	}
+
+	organization, err := organizationDao.FindByID(organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to invite user: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to invite user")
+	}
+
+	if member, _ := userDao.FindOneInOrganization(models.User{Email: email}, organizationID); member != nil {
+		return nil, gqlerrors.CreateConflictError("User is already a member of the organization")
+	}
+
+	// Only the last invitation sent to an email can be accepted
+	if err = invitationDao.RevokePending(organizationID, email); err != nil {
+		log.Printf("Error while trying to invite user: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to invite user")
+	}
+
+	token, err := crypt.GenerateToken()
+
+	if err != nil {
+		log.Printf("Error while trying to invite user: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to invite user")
+	}
+
+	invitation := models.Invitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Role:           role,
+		TokenHash:      crypt.HashToken(token),
+		InvitedBy:      userID,
+		Status:         models.InvitationPending,
+		ExpiresAt:      time.Now().Add(models.InvitationLifetime),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	invitation.ID, err = invitationDao.CreateOne(invitation)
+
+	if err != nil {
+		log.Printf("Error while trying to invite user: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to invite user")
+	}
+
+	err = r.Mailer.Send(mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organization.Name),
+		Body: fmt.Sprintf(
+			"You have been invited to join %s as %s.\n\nAccept the invitation: %s/invitations/accept?token=%s\n\nThis invitation expires at %s.",
+			organization.Name, role, env.Config.AppURL, url.QueryEscape(token), invitation.ExpiresAt.Format("2006-01-02 15:04:05"),
+		),
+	})
+
+	if err != nil {
+		log.Printf("Error while trying to send the invitation: %v\n", err)
+		invitationDao.UpdateStatus(invitation.ID, models.InvitationRevoked)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to send the invitation")
+	}
+
+	return &invitation, nil
+}
+
+func (r *mutationResolver) AcceptInvitation(ctx context.Context, token string, password string) (*gqlmodels.AuthUserPayload, error) {
+	invitation, err := invitationDao.FindByTokenHash(crypt.HashToken(token))
+
+	if err != nil || invitation.Status != models.InvitationPending || invitation.IsExpired() {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	organization, err := organizationDao.FindByID(invitation.OrganizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to accept the invitation: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to accept the invitation")
+	}
+
+	var user *models.User
+
+	// When the email is unique per tenant, each organization has its own accounts, so a new one is always created
+	if env.Config.EmailUniqueness == env.EmailUniquenessGlobal {
+		user, _ = userDao.FindOne(models.User{Email: invitation.Email})
+	}
+
+	// The password proves the account belongs to who is accepting the invitation
+	if user != nil && (!user.Active || !crypt.ComparePassword(user.Password, password)) {
+		log.Printf("Error while trying to accept the invitation %s: Invalid Password", invitation.ID.Hex())
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	if user == nil {
+		if err := r.validatePassword(password, invitation.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	// The invitation is only consumed along with the write that uses it, so a failed sign up doesn't burn it
+	if user != nil {
+		event := userAuditEvent(ctx, models.AuditOrganizationRoleChanged, user, organization.ID.Hex(), map[string]interface{}{
+			"to":            invitation.Role,
+			"invitation_id": invitation.ID.Hex(),
+		})
+
+		user, err = invitationDao.AcceptAsMember(invitation.ID, user.ID, models.Membership{
+			OrganizationID: invitation.OrganizationID,
+			Role:           invitation.Role,
+		}, event)
+	} else {
+		user, err = acceptInvitationAsNewUser(ctx, invitation, password)
+	}
+
+	if err == dao.ErrInvitationUsed {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	if err == dao.ErrDuplicateKey {
+		return nil, gqlerrors.CreateConflictError("User already exists")
+	}
+
+	if err != nil {
+		log.Printf("Error while trying to accept the invitation: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to accept the invitation")
+	}
+
+	payload, err := createAuthUserPayload(user, organization.ID.Hex())
+
+	if err != nil {
+		log.Printf("Error while trying to accept the invitation: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to accept the invitation")
+	}
+
+	return payload, nil
+}
+
+// acceptInvitationAsNewUser creates the account of the invited user, consuming the invitation in the same transaction
+func acceptInvitationAsNewUser(ctx context.Context, invitation *models.Invitation, password string) (*models.User, error) {
+	hash, err := crypt.HashPassword(password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		// The id is defined before the insert, since the event recorded along with the user points to it
+		ID:                primitive.NewObjectID(),
+		Email:             invitation.Email,
+		Password:          hash,
+		PasswordChangedAt: time.Now(),
+		Roles:             []string{"user"},
+		Active:            true,
+		Memberships: []models.Membership{{
+			OrganizationID: invitation.OrganizationID,
+			Role:           invitation.Role,
+			Managed:        true,
+		}},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	event := userAuditEvent(ctx, models.AuditUserSignedUp, &user, invitation.OrganizationID.Hex(), map[string]interface{}{
+		"email":         user.Email,
+		"invitation_id": invitation.ID.Hex(),
+	})
+
+	return invitationDao.AcceptAsNewUser(invitation.ID, user, event)
+}
+
+func (r *mutationResolver) RevokeInvitation(ctx context.Context, id string) (*models.Invitation, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid invitation id")
+	}
+
+	invitation, err := invitationDao.FindByID(objectID)
+
+	if err != nil || invitation.OrganizationID != organizationID {
+		return nil, gqlerrors.CreateNotFoundError("Invitation not found")
+	}
+
+	if invitation.Status != models.InvitationPending {
+		return nil, gqlerrors.CreateConflictError("Only pending invitations can be revoked")
+	}
+
+	if !callerCanManageRole(ctx, organizationID, invitation.Role) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	revoked, err := invitationDao.UpdateStatus(objectID, models.InvitationRevoked)
+
+	if err != nil {
+		log.Printf("Error while trying to revoke the invitation: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to revoke the invitation")
+	}
+
+	return revoked, nil
+}
@@ -2,12 +2,13 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/LucasFrezarini/go-auth-manager/credentials"
 	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/LucasFrezarini/go-auth-manager/env"
 	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
 	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
 	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
@@ -18,10 +19,19 @@ import (
 type mutationResolver struct{ *Resolver }
 
 func (r *mutationResolver) CreateUser(ctx context.Context, data gqlmodels.CreateUserInput) (*gqlmodels.AuthUserPayload, error) {
-	if emailAlreadyExists(data.Email) {
+	// Anyone can sign up, so an organization is only joined with an invitation or when one of its admins adds the user
+	if data.Organization != nil {
+		return nil, gqlerrors.CreateBadUserInputError("An organization can only be joined with an invitation")
+	}
+
+	if emailAlreadyExists(data.Email, nil) {
 		return nil, gqlerrors.CreateConflictError("User already exists")
 	}
 
+	if err := r.validatePassword(data.Password, data.Email); err != nil {
+		return nil, err
+	}
+
 	hash, err := crypt.HashPassword(data.Password)
 
 	if err != nil {
@@ -36,34 +46,100 @@ func (r *mutationResolver) CreateUser(ctx context.Context, data gqlmodels.Create
 	}
 
 	user := models.User{
-		Email:     data.Email,
-		Password:  hash,
-		Roles:     []string{"user"},
-		Active:    active,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Email:             data.Email,
+		Password:          hash,
+		PasswordChangedAt: time.Now(),
+		Roles:             []string{"user"},
+		Active:            active,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
 	}
 
-	insertedID, err := userDao.CreateOne(user)
+	// The id is defined before the insert, since the event recorded along with the user points to it
+	user.ID = primitive.NewObjectID()
+	event := userAuditEvent(ctx, models.AuditUserSignedUp, &user, "", map[string]interface{}{
+		"email": user.Email,
+	})
 
-	if err != nil {
+	if _, err = userDao.CreateOne(user, event); err != nil {
 		log.Printf("Error while trying to create user: %v\n", err)
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
 	}
 
-	user.ID = insertedID
-	token, err := jsonwebtoken.Encode(jsonwebtoken.CreateDefaultClaims(user.ID.Hex()))
+	payload, err := createAuthUserPayload(&user, "")
 
 	if err != nil {
 		log.Printf("Error while trying to create user: %v\n", err)
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
 	}
 
-	refreshToken, err := jsonwebtoken.Encode(jsonwebtoken.CreateRefreshTokenClaims(user.ID.Hex()))
+	return payload, nil
+}
+
+// emailAlreadyExists checks if the email is already taken. When the email uniqueness is per tenant,
+// only the users of the organization are considered
+func emailAlreadyExists(email string, organization *models.Organization) bool {
+	if env.Config.EmailUniqueness == env.EmailUniquenessTenant && organization != nil {
+		registered, _ := userDao.FindOneInOrganization(models.User{
+			Email: email,
+		}, organization.ID)
+
+		return registered != nil
+	}
+
+	registered, _ := userDao.FindOne(models.User{
+		Email: email,
+	})
+
+	return registered != nil
+}
+
+// errAmbiguousEmail is returned when the email belongs to the accounts of several organizations
+var errAmbiguousEmail = errors.New("The email belongs to the accounts of several organizations")
+
+// findUserByEmail returns the user matching the filter, which has the email, across the organizations. When the email
+// uniqueness is per tenant, each organization can have an account with the email, so none is picked when there are many
+func findUserByEmail(filter models.User) (*models.User, error) {
+	if env.Config.EmailUniqueness != env.EmailUniquenessTenant {
+		return userDao.FindOne(filter)
+	}
+
+	users, err := userDao.FindAll(filter)
 
 	if err != nil {
-		log.Printf("Error while trying to create user: %v\n", err)
-		return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
+		return nil, err
+	}
+
+	if len(users) > 1 {
+		return nil, errAmbiguousEmail
+	}
+
+	if len(users) == 0 {
+		return nil, errors.New("Error while trying to fetch user from the database: User not found")
+	}
+
+	return users[0], nil
+}
+
+// createAuthUserPayload issues a new pair of access and refresh tokens for the user,
+// scoped to the organization when organizationID isn't empty
+func createAuthUserPayload(user *models.User, organizationID string) (*gqlmodels.AuthUserPayload, error) {
+	claims, err := createAccessClaims(user, organizationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jsonwebtoken.Encode(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jsonwebtoken.Encode(jsonwebtoken.CreateRefreshTokenClaims(user.ID.Hex()).WithOrganization(organizationID))
+
+	if err != nil {
+		return nil, err
 	}
 
 	updatedUser, err := refreshTokenDao.CreateOne(user.ID, models.RefreshToken{
@@ -72,71 +148,147 @@ func (r *mutationResolver) CreateUser(ctx context.Context, data gqlmodels.Create
 	})
 
 	if err != nil {
-		log.Printf("Error while trying to create user: %v\n", err)
-		return nil, gqlerrors.CreateInternalServerError("Error while trying to create user")
+		return nil, err
 	}
 
 	return &gqlmodels.AuthUserPayload{
-		RefreshToken: refreshToken,
-		Token:        token,
 		User:         &updatedUser,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
-func emailAlreadyExists(email string) bool {
-	registered, _ := userDao.FindOne(models.User{
-		Email: email,
-	})
+// createAccessClaims returns the claims of an access token for the user. When the token is scoped to
+// an organization, the groups of the user on it are added to the claims
+func createAccessClaims(user *models.User, organizationID string) (jsonwebtoken.Claims, error) {
+	claims := jsonwebtoken.CreateDefaultClaims(user.ID.Hex()).WithOrganization(organizationID)
 
-	return registered != nil
+	if organizationID == "" {
+		return claims, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(organizationID)
+
+	if err != nil {
+		return claims, err
+	}
+
+	groups, err := groupDao.GetAllByOrganization(objectID)
+
+	if err != nil {
+		return claims, err
+	}
+
+	return claims.WithGroups(models.ResolveGroups(groups, user.ID)), nil
 }
 
 func (r *mutationResolver) Login(ctx context.Context, data gqlmodels.LoginUserInput) (*gqlmodels.AuthUserPayload, error) {
-	user, err := userDao.FindOne(models.User{
+	var user *models.User
+	var organization *models.Organization
+	var err error
+
+	ip := stringFromContext(ctx, "ip")
+	locked, err := r.Lockout.Check(data.Email, ip)
+
+	if err != nil {
+		log.Printf("Error while trying to login: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to login")
+	}
+
+	if locked > 0 {
+		recordLoginFailure(ctx, data.Email, nil, nil, loginFailureLocked)
+		return nil, gqlerrors.CreateAccountLockedError(locked)
+	}
+
+	filter := models.User{
 		Email:  data.Email,
 		Active: true,
-	})
+	}
+
+	if data.Organization != nil {
+		organization, err = organizationDao.FindBySlug(*data.Organization)
+
+		if err != nil {
+			log.Printf("Error while trying to login %s from %s: %v\n", data.Email, ip, err)
+			return nil, r.loginFailed(ctx, data.Email, nil, nil, loginFailureUnknownOrganization)
+		}
+
+		user, err = userDao.FindOneInOrganization(filter, organization.ID)
+	} else {
+		user, err = findUserByEmail(filter)
+	}
+
+	if err == errAmbiguousEmail {
+		return nil, gqlerrors.CreateBadUserInputError("The organization is required to login with this email")
+	}
 
 	if err != nil {
-		log.Printf("Error while trying to login: %v\n", err)
-		return nil, gqlerrors.CreateAuthorizationError()
+		log.Printf("Error while trying to login %s from %s: %v\n", data.Email, ip, err)
+		return nil, r.loginFailed(ctx, data.Email, nil, organization, loginFailureUnknownUser)
 	}
 
 	if !crypt.ComparePassword(user.Password, data.Password) {
-		log.Println("Error while trying to login: Invalid Password")
-		return nil, gqlerrors.CreateAuthorizationError()
+		log.Printf("Error while trying to login %s from %s: Invalid Password\n", data.Email, ip)
+		return nil, r.loginFailed(ctx, data.Email, user, organization, loginFailureInvalidPassword)
+	}
+
+	if err := r.Lockout.Succeed(data.Email); err != nil {
+		log.Printf("Error while trying to clear the failed logins: %v\n", err)
+	}
+
+	// The password is only known on the login, so it's when the hashes of older algorithms or weaker parameters are upgraded
+	if crypt.NeedsRehash(user.Password) {
+		rehashPassword(user, data.Password)
+	}
+
+	if user.PasswordExpired(r.PasswordPolicy.MaxAge, time.Now()) {
+		return nil, passwordExpiredError(user)
+	}
+
+	organizationID := ""
+
+	if organization != nil {
+		organizationID = organization.ID.Hex()
+	} else if len(user.Memberships) == 1 {
+		organizationID = user.Memberships[0].OrganizationID.Hex()
 	}
 
-	token, err := jsonwebtoken.Encode(jsonwebtoken.CreateDefaultClaims(user.ID.Hex()))
+	payload, err := createAuthUserPayload(user, organizationID)
 
 	if err != nil {
 		log.Printf("Error while trying to login: %v\n", err)
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to login")
 	}
 
-	refreshToken, err := jsonwebtoken.Encode(jsonwebtoken.CreateRefreshTokenClaims(user.ID.Hex()))
+	r.notifyNewDevice(ctx, user)
+	recordUserAuditEvent(ctx, models.AuditLoginSucceeded, user, organizationID, nil)
+
+	return payload, nil
+}
+
+// loginFailed registers the failed login and returns the error to be sent to the user,
+// which tells when the account or the IP was locked by this attempt
+func (r *mutationResolver) loginFailed(ctx context.Context, email string, user *models.User, organization *models.Organization, reason string) error {
+	recordLoginFailure(ctx, email, user, organization, reason)
+
+	locked, err := r.Lockout.Fail(email, stringFromContext(ctx, "ip"))
 
 	if err != nil {
-		log.Printf("Error while trying to login: %v\n", err)
-		return nil, gqlerrors.CreateInternalServerError("Error while trying to login")
+		log.Printf("Error while trying to register the failed login: %v\n", err)
 	}
 
-	updatedUser, err := refreshTokenDao.CreateOne(user.ID, models.RefreshToken{
-		Token:      refreshToken,
-		Identifier: "unknown",
-	})
+	if locked > 0 {
+		return gqlerrors.CreateAccountLockedError(locked)
+	}
 
-	return &gqlmodels.AuthUserPayload{
-		User:         &updatedUser,
-		Token:        token,
-		RefreshToken: refreshToken,
-	}, nil
+	return gqlerrors.CreateAuthorizationError()
 }
 
 func (r *mutationResolver) ValidateToken(ctx context.Context, token string) (*gqlmodels.ValidateTokenPayload, error) {
 	claims, err := jsonwebtoken.Decode(token)
 
-	if err != nil {
+	// The restricted tokens can't be used to authenticate on other services
+	if err != nil || claims.Restricted() {
 		return &gqlmodels.ValidateTokenPayload{
 			Claims: nil,
 			User:   nil,
@@ -162,15 +314,24 @@ func (r *mutationResolver) ValidateToken(ctx context.Context, token string) (*gq
 }
 
 func (r *mutationResolver) UpdateUser(ctx context.Context, data gqlmodels.UpdateUserInput) (*models.User, error) {
-	userID := ctx.Value("userID")
-
-	objectID, err := primitive.ObjectIDFromHex(fmt.Sprintf("%v", userID))
+	objectID, err := userIDFromContext(ctx)
 
 	if err != nil {
 		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to update user")
 	}
 
+	user, err := userDao.FindByID(objectID)
+
+	if err != nil {
+		log.Printf("Error while trying to update user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to update user")
+	}
+
+	if err := r.validatePasswordChange(user, data.Password); err != nil {
+		return nil, err
+	}
+
 	hash, err := crypt.HashPassword(data.Password)
 
 	if err != nil {
@@ -178,9 +339,8 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, data gqlmodels.Update
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to update user")
 	}
 
-	user, err := userDao.UpdateByID(objectID, models.User{
-		Password: hash,
-	})
+	event := auditEvent(ctx, models.AuditPasswordChanged, user.ID, nil)
+	user, err = userDao.ChangePassword(user, hash, r.PasswordPolicy.HistorySize, event)
 
 	if err != nil {
 		log.Printf("Error while trying to update user: %v", err)
@@ -191,18 +351,14 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, data gqlmodels.Update
 }
 
 func (r *mutationResolver) DeactivateUser(ctx context.Context) (*models.User, error) {
-	userID := ctx.Value("userID")
-
-	objectID, err := primitive.ObjectIDFromHex(fmt.Sprintf("%v", userID))
+	objectID, err := userIDFromContext(ctx)
 
 	if err != nil {
 		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to deactivate the user user")
 	}
 
-	user, err := userDao.UpdateByID(objectID, models.User{
-		Active: false,
-	})
+	user, err := userDao.SetActive(objectID, false, auditEvent(ctx, models.AuditUserDeactivated, objectID, nil))
 
 	if err != nil {
 		log.Printf("Error while trying to deactivate the user: %v", err)
@@ -212,6 +368,112 @@ func (r *mutationResolver) DeactivateUser(ctx context.Context) (*models.User, er
 	return user, nil
 }
 
+func (r *mutationResolver) ExportMyData(ctx context.Context) (string, error) {
+	user, err := findCaller(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := exportUser(ctx, user)
+
+	if err != nil {
+		log.Printf("Error while trying to export the data of the user: %v", err)
+		return "", gqlerrors.CreateInternalServerError("Error while trying to export the data of the user")
+	}
+
+	return archive, nil
+}
+
+func (r *mutationResolver) DeleteMyAccount(ctx context.Context, password string) (*models.User, error) {
+	user, err := findCaller(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !crypt.ComparePassword(user.Password, password) {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	// Requesting the deletion again doesn't postpone it
+	if !user.DeletionScheduledAt.IsZero() {
+		return user, nil
+	}
+
+	scheduledAt := time.Now().Add(r.DeletionGracePeriod)
+	updatedUser, err := accountDeletionDao.Schedule(user.ID, scheduledAt)
+
+	if err != nil {
+		log.Printf("Error while trying to schedule the deletion of the user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to schedule the deletion of the user")
+	}
+
+	recordAuditEvent(ctx, models.AuditDeletionScheduled, user.ID, map[string]interface{}{
+		"scheduled_at": scheduledAt,
+	})
+
+	return updatedUser, nil
+}
+
+func (r *mutationResolver) CancelAccountDeletion(ctx context.Context) (*models.User, error) {
+	user, err := findCaller(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt.IsZero() {
+		return nil, gqlerrors.CreateBadUserInputError("The deletion of the account wasn't requested")
+	}
+
+	now := time.Now()
+
+	if !user.DeletionScheduledAt.After(now) {
+		return nil, gqlerrors.CreateBadUserInputError("The grace period of the deletion is over")
+	}
+
+	updatedUser, err := accountDeletionDao.Cancel(user.ID, now)
+
+	if err != nil {
+		log.Printf("Error while trying to cancel the deletion of the user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to cancel the deletion of the user")
+	}
+
+	recordAuditEvent(ctx, models.AuditDeletionCancelled, user.ID, nil)
+
+	return updatedUser, nil
+}
+
+// ReportUnrecognizedLogin revokes all the sessions of the user with the token of the "this wasn't me" link,
+// sent when the user logs in from a new device
+func (r *mutationResolver) ReportUnrecognizedLogin(ctx context.Context, token string) (bool, error) {
+	claims, err := jsonwebtoken.Decode(token)
+
+	if err != nil || claims.Scope != jsonwebtoken.ScopeRevokeSessions {
+		return false, gqlerrors.CreateAuthorizationError()
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(claims.Subject)
+
+	if err != nil {
+		return false, gqlerrors.CreateAuthorizationError()
+	}
+
+	user, err := refreshTokenDao.DeleteAll(objectID)
+
+	if err != nil {
+		log.Printf("Error while trying to revoke the sessions: %v", err)
+		return false, gqlerrors.CreateInternalServerError("Error while trying to revoke the sessions")
+	}
+
+	recordUserAuditEvent(ctx, models.AuditSessionsRevoked, &user, "", map[string]interface{}{
+		"reason": "unrecognized_login",
+	})
+
+	return true, nil
+}
+
 func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*gqlmodels.AuthUserPayload, error) {
 	claims, err := jsonwebtoken.Decode(refreshToken)
 
@@ -247,12 +509,33 @@ func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string
 		return nil, gqlerrors.CreateAuthorizationError()
 	}
 
-	token, err := jsonwebtoken.Encode(jsonwebtoken.CreateRefreshTokenClaims(user.ID.Hex()))
+	if user.PasswordExpired(r.PasswordPolicy.MaxAge, time.Now()) {
+		return nil, passwordExpiredError(user)
+	}
+
+	if claims.Organization != "" {
+		organizationID, err := primitive.ObjectIDFromHex(claims.Organization)
+
+		if err != nil || user.MembershipOf(organizationID) == nil {
+			return nil, gqlerrors.CreateAuthorizationError()
+		}
+	}
+
+	accessClaims, err := createAccessClaims(user, claims.Organization)
+
+	if err != nil {
+		log.Printf("Error while trying to get a refreshed token: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to refresh token")
+	}
+
+	token, err := jsonwebtoken.Encode(accessClaims)
 
 	if err != nil {
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to refresh token")
 	}
 
+	recordUserAuditEvent(ctx, models.AuditTokenRefreshed, user, claims.Organization, nil)
+
 	return &gqlmodels.AuthUserPayload{
 		User:         user,
 		RefreshToken: refreshToken,
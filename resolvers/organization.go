@@ -0,0 +1,27 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+type organizationResolver struct{ *Resolver }
+
+func (r *organizationResolver) ID(ctx context.Context, obj *models.Organization) (string, error) {
+	return obj.ID.Hex(), nil
+}
+
+func (r *organizationResolver) CreatedAt(ctx context.Context, obj *models.Organization) (string, error) {
+	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+func (r *organizationResolver) UpdatedAt(ctx context.Context, obj *models.Organization) (string, error) {
+	return obj.UpdatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+type membershipResolver struct{ *Resolver }
+
+func (r *membershipResolver) Organization(ctx context.Context, obj *models.Membership) (*models.Organization, error) {
+	return organizationDao.FindByID(obj.OrganizationID)
+}
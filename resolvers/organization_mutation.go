@@ -0,0 +1,182 @@
+package resolvers
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *mutationResolver) CreateOrganization(ctx context.Context, data gqlmodels.CreateOrganizationInput) (*models.Organization, error) {
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create organization")
+	}
+
+	name := strings.TrimSpace(data.Name)
+	slug := strings.ToLower(strings.TrimSpace(data.Slug))
+
+	if name == "" || slug == "" {
+		return nil, gqlerrors.CreateBadUserInputError("The organization name and slug cannot be empty")
+	}
+
+	if registered, _ := organizationDao.FindBySlug(slug); registered != nil {
+		return nil, gqlerrors.CreateConflictError("Organization already exists")
+	}
+
+	organization := models.Organization{
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	organization.ID, err = organizationDao.CreateOne(organization)
+
+	if err != nil {
+		log.Printf("Error while trying to create organization: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create organization")
+	}
+
+	_, err = membershipDao.Save(userID, models.Membership{
+		OrganizationID: organization.ID,
+		Role:           models.RoleOwner,
+	})
+
+	if err != nil {
+		log.Printf("Error while trying to add the owner of the organization: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create organization")
+	}
+
+	return &organization, nil
+}
+
+func (r *mutationResolver) AddOrganizationMember(ctx context.Context, email string, role string) (*models.User, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	if !isOrganizationRole(role) {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid organization role")
+	}
+
+	if !callerCanManageRole(ctx, organizationID, role) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	// Only the role of the members is changed here. The users outside of the organization must accept an invitation
+	// to join it, so their accounts aren't linked without their consent
+	user, err := userDao.FindOneInOrganization(models.User{Email: email}, organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to change the role of the organization member: %v\n", err)
+		return nil, gqlerrors.CreateNotFoundError("The user isn't a member of the organization, invite the user instead")
+	}
+
+	previousRole := user.MembershipOf(organizationID).Role
+
+	if !callerCanManageRole(ctx, organizationID, previousRole) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	updatedUser, err := membershipDao.Save(user.ID, models.Membership{
+		OrganizationID: organizationID,
+		Role:           role,
+	})
+
+	if err != nil {
+		log.Printf("Error while trying to change the role of the organization member: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to change the role of the organization member")
+	}
+
+	recordAuditEvent(ctx, models.AuditOrganizationRoleChanged, user.ID, map[string]interface{}{
+		"from": previousRole,
+		"to":   role,
+	})
+
+	return updatedUser, nil
+}
+
+func (r *mutationResolver) RemoveOrganizationMember(ctx context.Context, userID string) (*models.User, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid user id")
+	}
+
+	user, err := userDao.FindByID(objectID)
+
+	if err != nil || user.MembershipOf(organizationID) == nil {
+		return nil, gqlerrors.CreateNotFoundError("User not found")
+	}
+
+	if !callerCanManageRole(ctx, organizationID, user.MembershipOf(organizationID).Role) {
+		return nil, gqlerrors.CreateForbiddenError()
+	}
+
+	updatedUser, err := membershipDao.DeleteOne(objectID, organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to remove organization member: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to remove organization member")
+	}
+
+	if err = groupDao.RemoveUserFromOrganization(organizationID, objectID); err != nil {
+		log.Printf("Error while trying to remove organization member from its groups: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to remove organization member")
+	}
+
+	recordAuditEvent(ctx, models.AuditOrganizationRoleChanged, user.ID, map[string]interface{}{
+		"from": user.MembershipOf(organizationID).Role,
+		"to":   "",
+	})
+
+	return updatedUser, nil
+}
+
+func isOrganizationRole(role string) bool {
+	for _, value := range models.OrganizationRoles {
+		if value == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// callerCanManageRole checks if the authenticated user can give or take the role on the organization.
+// Only owners and sysadmins can manage other owners
+func callerCanManageRole(ctx context.Context, organizationID primitive.ObjectID, role string) bool {
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		return false
+	}
+
+	caller, err := userDao.FindByID(userID)
+
+	if err != nil || !caller.IsOrganizationAdmin(organizationID) {
+		return false
+	}
+
+	if role != models.RoleOwner || caller.HasRole(models.RoleSysadmin) {
+		return true
+	}
+
+	return caller.MembershipOf(organizationID).Role == models.RoleOwner
+}
@@ -0,0 +1,98 @@
+package resolvers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/LucasFrezarini/go-auth-manager/crypt"
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/password"
+)
+
+// validatePassword checks the password of the account with the email against the password policy,
+// returning a bad user input error with every rule that failed
+func (r *Resolver) validatePassword(plainPassword, email string) error {
+	return passwordPolicyError(r.PasswordPolicy.Validate(plainPassword, email))
+}
+
+// validatePasswordChange checks the new password of the user against the password policy
+// and against the current and the previous passwords of the user
+func (r *Resolver) validatePasswordChange(user *models.User, plainPassword string) error {
+	violations := r.PasswordPolicy.Validate(plainPassword, user.Email)
+
+	if passwordReused(user, plainPassword, r.PasswordPolicy.HistorySize) {
+		violations = append(violations, password.Violation{
+			Rule:    password.RuleHistory,
+			Message: "The password can't be one of the last used passwords",
+		})
+	}
+
+	return passwordPolicyError(violations)
+}
+
+// passwordReused checks if the password is the current or one of the last historySize passwords of the user. The
+// history can be longer than the size when the size was reduced since the last password change
+func passwordReused(user *models.User, plainPassword string, historySize int) bool {
+	history := user.PasswordHistory
+
+	if len(history) > historySize {
+		history = history[:historySize]
+	}
+
+	for _, hash := range append([]string{user.Password}, history...) {
+		if crypt.ComparePassword(hash, plainPassword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// passwordExpiredError returns the error sent when the password of the user expired. It carries a token that can
+// only be used to change the password, since the user must change it before getting a token for anything else
+func passwordExpiredError(user *models.User) error {
+	token, err := jsonwebtoken.Encode(jsonwebtoken.CreatePasswordChangeClaims(user.ID.Hex()))
+
+	if err != nil {
+		log.Printf("Error while trying to create the password change token: %v\n", err)
+		return gqlerrors.CreateInternalServerError("Error while trying to create the password change token")
+	}
+
+	return gqlerrors.CreatePasswordExpiredError(token)
+}
+
+// rehashPassword hashes the password again with the current hasher. The login doesn't fail
+// when it can't be done, since the old hash is still valid
+func rehashPassword(user *models.User, plainPassword string) {
+	hash, err := crypt.HashPassword(plainPassword)
+
+	if err == nil {
+		err = userDao.RehashPassword(user.ID, user.Password, hash)
+	}
+
+	if err != nil {
+		log.Printf("Error while trying to rehash the password: %v\n", err)
+		return
+	}
+
+	user.Password = hash
+}
+
+func passwordPolicyError(violations []password.Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(violations))
+
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+
+	err := gqlerrors.CreateBadUserInputError("The password doesn't follow the password policy: " + strings.Join(messages, "; "))
+	err.Extensions["rules"] = violations
+
+	return err
+}
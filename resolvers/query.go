@@ -2,21 +2,416 @@ package resolvers
 
 import (
 	"context"
+	"log"
+	"time"
 
+	"github.com/LucasFrezarini/go-auth-manager/dao"
 	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
 	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/pagination"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // QueryResolver defines the root resolver from the query in GraphQL schema
 type queryResolver struct{ *Resolver }
 
-// Users is the resolver of Users on graphql schema
-func (r *queryResolver) Users(ctx context.Context) ([]*models.User, error) {
-	users, err := userDao.GetAll()
+// Me is the resolver of the authenticated user
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	userID, err := userIDFromContext(ctx)
 
 	if err != nil {
+		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the user")
+	}
+
+	user, err := userDao.FindByID(userID)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the user: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the user")
+	}
+
+	return user, nil
+}
+
+// User is the resolver of an user by his id. Sysadmins can fetch any user, while the
+// organization admins can only fetch the members of their organization
+func (r *queryResolver) User(ctx context.Context, id string) (*models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid user id")
+	}
+
+	caller, err := findCaller(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userDao.FindByID(objectID)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	if caller.HasRole(models.RoleSysadmin) {
+		return user, nil
+	}
+
+	organizationID, _ := organizationIDFromContext(ctx)
+
+	if user.MembershipOf(organizationID) == nil {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+// Users is the resolver of the paginated Users on graphql schema. The users are scoped to the organization
+// of the caller, unless the caller is a sysadmin using a token without organization
+func (r *queryResolver) Users(ctx context.Context, first *int, after *string, filter *gqlmodels.UserFilter, sort *gqlmodels.UserSort) (*gqlmodels.UserConnection, error) {
+	query := dao.UserQuery{
+		SortField: dao.UserSortCreatedAt,
+		Limit:     pagination.PageSize(first),
+	}
+
+	if organizationID, ok := organizationIDFromContext(ctx); ok {
+		query.OrganizationID = organizationID
+	}
+
+	if sort != nil {
+		if sort.Field == gqlmodels.UserSortFieldEmail {
+			query.SortField = dao.UserSortEmail
+		}
+
+		query.Descending = sort.Direction == gqlmodels.SortDirectionDesc
+	}
+
+	if after != nil {
+		cursor, err := pagination.Decode(*after)
+
+		if err != nil {
+			return nil, gqlerrors.CreateBadUserInputError("Invalid cursor")
+		}
+
+		query.After = &cursor
+	}
+
+	if filter != nil {
+		if err := applyUserFilter(&query, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	users, total, hasNextPage, err := userDao.FindPage(query)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the users: %v", err)
 		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch all users")
 	}
 
-	return users, nil
+	connection := &gqlmodels.UserConnection{
+		Edges:      make([]*gqlmodels.UserEdge, len(users)),
+		TotalCount: int(total),
+		PageInfo: &gqlmodels.PageInfo{
+			HasNextPage:     hasNextPage,
+			HasPreviousPage: after != nil,
+		},
+	}
+
+	for i, user := range users {
+		connection.Edges[i] = &gqlmodels.UserEdge{
+			Cursor: dao.UserCursor(user, query.SortField).Encode(),
+			Node:   user,
+		}
+	}
+
+	if len(users) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(users)-1].Cursor
+	}
+
+	return connection, nil
+}
+
+func (r *queryResolver) AuditEvents(ctx context.Context, first *int, after *string, filter *gqlmodels.AuditEventFilter) (*gqlmodels.AuditEventConnection, error) {
+	query := dao.AuditEventQuery{
+		Limit: pagination.PageSize(first),
+	}
+
+	if organizationID, ok := organizationIDFromContext(ctx); ok {
+		query.OrganizationID = organizationID
+	}
+
+	if filter != nil {
+		if err := applyAuditEventFilter(&query, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	return findAuditEvents(query, after)
+}
+
+func (r *queryResolver) MyLoginHistory(ctx context.Context, first *int, after *string) (*gqlmodels.AuditEventConnection, error) {
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	return findAuditEvents(dao.AuditEventQuery{
+		Actions:  []string{models.AuditLoginSucceeded, models.AuditLoginFailed},
+		TargetID: userID,
+		Limit:    pagination.PageSize(first),
+	}, after)
+}
+
+// findAuditEvents returns the page of the audit events matching the query after the cursor
+func findAuditEvents(query dao.AuditEventQuery, after *string) (*gqlmodels.AuditEventConnection, error) {
+	if after != nil {
+		cursor, err := pagination.Decode(*after)
+
+		if err != nil {
+			return nil, gqlerrors.CreateBadUserInputError("Invalid cursor")
+		}
+
+		query.After = &cursor
+	}
+
+	events, total, hasNextPage, err := auditEventDao.FindPage(query)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the audit events: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the audit events")
+	}
+
+	connection := &gqlmodels.AuditEventConnection{
+		Edges:      make([]*gqlmodels.AuditEventEdge, len(events)),
+		TotalCount: int(total),
+		PageInfo: &gqlmodels.PageInfo{
+			HasNextPage:     hasNextPage,
+			HasPreviousPage: after != nil,
+		},
+	}
+
+	for i, event := range events {
+		connection.Edges[i] = &gqlmodels.AuditEventEdge{
+			Cursor: dao.AuditEventCursor(event).Encode(),
+			Node:   event,
+		}
+	}
+
+	if len(events) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(events)-1].Cursor
+	}
+
+	return connection, nil
+}
+
+func applyAuditEventFilter(query *dao.AuditEventQuery, filter *gqlmodels.AuditEventFilter) error {
+	var err error
+
+	if filter.Action != nil {
+		query.Actions = []string{*filter.Action}
+	}
+
+	if filter.IP != nil {
+		query.IP = *filter.IP
+	}
+
+	if filter.ActorID != nil {
+		if query.ActorID, err = primitive.ObjectIDFromHex(*filter.ActorID); err != nil {
+			return gqlerrors.CreateBadUserInputError("Invalid actor id")
+		}
+	}
+
+	if filter.TargetID != nil {
+		if query.TargetID, err = primitive.ObjectIDFromHex(*filter.TargetID); err != nil {
+			return gqlerrors.CreateBadUserInputError("Invalid target id")
+		}
+	}
+
+	if filter.CreatedAfter != nil {
+		createdAfter, err := time.Parse(time.RFC3339, *filter.CreatedAfter)
+
+		if err != nil {
+			return gqlerrors.CreateBadUserInputError("The createdAfter filter must be a RFC 3339 date")
+		}
+
+		query.CreatedAfter = &createdAfter
+	}
+
+	if filter.CreatedBefore != nil {
+		createdBefore, err := time.Parse(time.RFC3339, *filter.CreatedBefore)
+
+		if err != nil {
+			return gqlerrors.CreateBadUserInputError("The createdBefore filter must be a RFC 3339 date")
+		}
+
+		query.CreatedBefore = &createdBefore
+	}
+
+	return nil
+}
+
+func applyUserFilter(query *dao.UserQuery, filter *gqlmodels.UserFilter) error {
+	if filter.EmailPrefix != nil {
+		query.EmailPrefix = *filter.EmailPrefix
+	}
+
+	if filter.Role != nil {
+		query.Role = *filter.Role
+	}
+
+	query.Active = filter.Active
+
+	if filter.CreatedAfter != nil {
+		createdAfter, err := time.Parse(time.RFC3339, *filter.CreatedAfter)
+
+		if err != nil {
+			return gqlerrors.CreateBadUserInputError("The createdAfter filter must be a RFC 3339 date")
+		}
+
+		query.CreatedAfter = &createdAfter
+	}
+
+	if filter.CreatedBefore != nil {
+		createdBefore, err := time.Parse(time.RFC3339, *filter.CreatedBefore)
+
+		if err != nil {
+			return gqlerrors.CreateBadUserInputError("The createdBefore filter must be a RFC 3339 date")
+		}
+
+		query.CreatedBefore = &createdBefore
+	}
+
+	return nil
+}
+
+// Organization is the resolver of the organization the caller token is scoped to
+func (r *queryResolver) Organization(ctx context.Context) (*models.Organization, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, nil
+	}
+
+	organization, err := organizationDao.FindByID(organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the organization: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the organization")
+	}
+
+	return organization, nil
+}
+
+// Invitations is the resolver of the pending invitations of the caller organization
+func (r *queryResolver) Invitations(ctx context.Context) ([]*models.Invitation, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateBadUserInputError("The token isn't scoped to an organization")
+	}
+
+	invitations, err := invitationDao.GetPendingByOrganization(organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the invitations: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the invitations")
+	}
+
+	return invitations, nil
+}
+
+// WebhookSubscriptions is the resolver of the webhook subscriptions of the caller organization, or of the global
+// subscriptions when the token of the sysadmin isn't scoped to any organization
+func (r *queryResolver) WebhookSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	organizationID, _ := organizationIDFromContext(ctx)
+	subscriptions, err := webhookDao.GetSubscriptionsByOrganization(organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the webhook subscriptions: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the webhook subscriptions")
+	}
+
+	return subscriptions, nil
+}
+
+// WebhookDeliveries is the resolver of the delivery log of a webhook subscription, from the newest to the oldest
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, subscriptionID string, first *int) ([]*models.WebhookDelivery, error) {
+	subscription, err := findWebhookSubscription(ctx, subscriptionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := webhookDao.GetDeliveriesBySubscription(subscription.ID, pagination.PageSize(first))
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the webhook deliveries: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// Groups is the resolver of the groups of the caller organization
+func (r *queryResolver) Groups(ctx context.Context) ([]*models.Group, error) {
+	organizationID, ok := organizationIDFromContext(ctx)
+
+	if !ok {
+		return []*models.Group{}, nil
+	}
+
+	groups, err := groupDao.GetAllByOrganization(organizationID)
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the groups: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the groups")
+	}
+
+	return groups, nil
+}
+
+// Group is the resolver of a group of the caller organization
+func (r *queryResolver) Group(ctx context.Context, id string) (*models.Group, error) {
+	group, err := findOrganizationGroup(ctx, id)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	return group, nil
+}
+
+// Authorize is the resolver that evaluates the access policy for the caller
+func (r *queryResolver) Authorize(ctx context.Context, action string, resource string, attributes *map[string]interface{}) (*policy.Decision, error) {
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to authorize")
+	}
+
+	user, err := userDao.FindByID(userID)
+
+	if err != nil {
+		log.Printf("Error while trying to authorize: %v", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to authorize")
+	}
+
+	var values map[string]interface{}
+
+	if attributes != nil {
+		values = *attributes
+	}
+
+	decision := r.Policy.Evaluate(policy.NewInput(action, resource, user, claimsFromContext(ctx), policy.RequestAttributes(ctx), values))
+
+	return &decision, nil
 }
@@ -1,22 +1,65 @@
 package resolvers
 
 import (
+	"time"
+
 	"github.com/LucasFrezarini/go-auth-manager/dao"
 	"github.com/LucasFrezarini/go-auth-manager/generated"
+	"github.com/LucasFrezarini/go-auth-manager/lockout"
+	"github.com/LucasFrezarini/go-auth-manager/mailer"
 	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/notifier"
+	"github.com/LucasFrezarini/go-auth-manager/password"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
 )
 
 var userDao dao.UserDao
 var refreshTokenDao dao.RefreshTokenDao
+var organizationDao dao.OrganizationDao
+var membershipDao dao.MembershipDao
+var invitationDao dao.InvitationDao
+var groupDao dao.GroupDao
+var auditEventDao dao.AuditEventDao
+var accountDeletionDao dao.AccountDeletionDao
+var webhookDao dao.WebhookDao
 
 func init() {
 	userDao = dao.UserDao{}
 	refreshTokenDao = dao.RefreshTokenDao{}
+	organizationDao = dao.OrganizationDao{}
+	membershipDao = dao.MembershipDao{}
+	invitationDao = dao.InvitationDao{}
+	groupDao = dao.GroupDao{}
+	auditEventDao = dao.AuditEventDao{}
+	accountDeletionDao = dao.AccountDeletionDao{}
+	webhookDao = dao.WebhookDao{}
 }
 
 // Resolver is the structure of the graphql root resolver
 type Resolver struct {
 	users []*models.User
+
+	// Mailer delivers the emails sent by the resolvers, like invitations
+	Mailer mailer.Mailer
+
+	// Notifier warns the users about the logins from new devices
+	Notifier notifier.Notifier
+
+	// Policy evaluates the access rules used by the authorize query
+	Policy *policy.Engine
+
+	// Lockout refuses the logins of the accounts and IPs with too many failed attempts
+	Lockout *lockout.Guard
+
+	// PasswordPolicy defines the rules of the passwords chosen by the users
+	PasswordPolicy *password.Policy
+
+	// DeletionGracePeriod is how long the deletion of an account can be cancelled before it's purged
+	DeletionGracePeriod time.Duration
+
+	// Events feeds the subscriptions with the events published by the outbox relay
+	Events pubsub.PubSub
 }
 
 // Mutation returns the root mutation resolver from GraphQL schema
@@ -29,6 +72,11 @@ func (r *Resolver) Query() generated.QueryResolver {
 	return &queryResolver{r}
 }
 
+// Subscription returns the root subscription resolver from GraphQL schema
+func (r *Resolver) Subscription() generated.SubscriptionResolver {
+	return &subscriptionResolver{r}
+}
+
 // User returns the user resolver from GraphQL schema
 func (r *Resolver) User() generated.UserResolver {
 	return &userResolver{r}
@@ -38,3 +86,38 @@ func (r *Resolver) User() generated.UserResolver {
 func (r *Resolver) Claims() generated.ClaimsResolver {
 	return &claimsResolver{r}
 }
+
+// AuditEvent returns the audit event resolver from GraphQL schema
+func (r *Resolver) AuditEvent() generated.AuditEventResolver {
+	return &auditEventResolver{r}
+}
+
+// Organization returns the organization resolver from GraphQL schema
+func (r *Resolver) Organization() generated.OrganizationResolver {
+	return &organizationResolver{r}
+}
+
+// Membership returns the membership resolver from GraphQL schema
+func (r *Resolver) Membership() generated.MembershipResolver {
+	return &membershipResolver{r}
+}
+
+// Invitation returns the invitation resolver from GraphQL schema
+func (r *Resolver) Invitation() generated.InvitationResolver {
+	return &invitationResolver{r}
+}
+
+// Group returns the group resolver from GraphQL schema
+func (r *Resolver) Group() generated.GroupResolver {
+	return &groupResolver{r}
+}
+
+// WebhookSubscription returns the webhook subscription resolver from GraphQL schema
+func (r *Resolver) WebhookSubscription() generated.WebhookSubscriptionResolver {
+	return &webhookSubscriptionResolver{r}
+}
+
+// WebhookDelivery returns the webhook delivery resolver from GraphQL schema
+func (r *Resolver) WebhookDelivery() generated.WebhookDeliveryResolver {
+	return &webhookDeliveryResolver{r}
+}
@@ -0,0 +1,84 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
+)
+
+// sessionEventActions are the events that end or change the sessions of an user, so its clients must
+// authenticate again or refresh their tokens
+var sessionEventActions = []string{
+	models.AuditSessionsRevoked,
+	models.AuditPasswordChanged,
+	models.AuditPasswordReset,
+	models.AuditRolesChanged,
+	models.AuditOrganizationRoleChanged,
+	models.AuditUserDeactivated,
+	models.AuditUserDeleted,
+	models.AuditDeletionScheduled,
+}
+
+type subscriptionResolver struct{ *Resolver }
+
+func (r *subscriptionResolver) UserEvents(ctx context.Context) (<-chan *models.AuditEvent, error) {
+	organizationID, scoped := organizationIDFromContext(ctx)
+
+	return r.subscribe(ctx, func(event models.AuditEvent) bool {
+		return !scoped || event.OrganizationID == organizationID
+	}), nil
+}
+
+func (r *subscriptionResolver) MySessionEvents(ctx context.Context) (<-chan *models.AuditEvent, error) {
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	return r.subscribe(ctx, func(event models.AuditEvent) bool {
+		return event.TargetID == userID && containsAction(sessionEventActions, event.Action)
+	}), nil
+}
+
+// subscribe streams the events accepted by the filter until the client ends the subscription
+func (r *subscriptionResolver) subscribe(ctx context.Context, filter pubsub.Filter) <-chan *models.AuditEvent {
+	events, cancel := r.Events.Subscribe(filter)
+	results := make(chan *models.AuditEvent)
+
+	go func() {
+		defer close(results)
+		defer cancel()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				select {
+				case results <- &event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func containsAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
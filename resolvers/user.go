@@ -12,6 +12,16 @@ func (r *userResolver) ID(ctx context.Context, obj *models.User) (string, error)
 	return obj.ID.Hex(), nil
 }
 
+func (r *userResolver) DeletionScheduledAt(ctx context.Context, obj *models.User) (*string, error) {
+	if obj.DeletionScheduledAt.IsZero() {
+		return nil, nil
+	}
+
+	scheduledAt := obj.DeletionScheduledAt.Format("2006-01-02 15:04:05")
+
+	return &scheduledAt, nil
+}
+
 func (r *userResolver) CreatedAt(ctx context.Context, obj *models.User) (string, error) {
 	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
 }
@@ -0,0 +1,76 @@
+package resolvers
+
+import (
+	"context"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+)
+
+type webhookSubscriptionResolver struct{ *Resolver }
+
+func (r *webhookSubscriptionResolver) ID(ctx context.Context, obj *models.WebhookSubscription) (string, error) {
+	return obj.ID.Hex(), nil
+}
+
+func (r *webhookSubscriptionResolver) CreatedAt(ctx context.Context, obj *models.WebhookSubscription) (string, error) {
+	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+func (r *webhookSubscriptionResolver) UpdatedAt(ctx context.Context, obj *models.WebhookSubscription) (string, error) {
+	return obj.UpdatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+type webhookDeliveryResolver struct{ *Resolver }
+
+func (r *webhookDeliveryResolver) ID(ctx context.Context, obj *models.WebhookDelivery) (string, error) {
+	return obj.ID.Hex(), nil
+}
+
+func (r *webhookDeliveryResolver) EventID(ctx context.Context, obj *models.WebhookDelivery) (string, error) {
+	return obj.EventID.Hex(), nil
+}
+
+func (r *webhookDeliveryResolver) ResponseStatus(ctx context.Context, obj *models.WebhookDelivery) (*int, error) {
+	if obj.ResponseStatus == 0 {
+		return nil, nil
+	}
+
+	return &obj.ResponseStatus, nil
+}
+
+func (r *webhookDeliveryResolver) LastError(ctx context.Context, obj *models.WebhookDelivery) (*string, error) {
+	if obj.LastError == "" {
+		return nil, nil
+	}
+
+	return &obj.LastError, nil
+}
+
+// NextAttemptAt is only returned while the delivery is pending
+func (r *webhookDeliveryResolver) NextAttemptAt(ctx context.Context, obj *models.WebhookDelivery) (*string, error) {
+	if obj.Status != models.WebhookDeliveryPending {
+		return nil, nil
+	}
+
+	return optionalTime(obj.NextAttemptAt), nil
+}
+
+func (r *webhookDeliveryResolver) DeliveredAt(ctx context.Context, obj *models.WebhookDelivery) (*string, error) {
+	return optionalTime(obj.DeliveredAt), nil
+}
+
+func (r *webhookDeliveryResolver) CreatedAt(ctx context.Context, obj *models.WebhookDelivery) (string, error) {
+	return obj.CreatedAt.Format("2006-01-02 15:04:05"), nil
+}
+
+// optionalTime returns the formatted time, or nil when the time is empty
+func optionalTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+
+	formatted := t.Format("2006-01-02 15:04:05")
+
+	return &formatted
+}
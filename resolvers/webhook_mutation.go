@@ -0,0 +1,129 @@
+package resolvers
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+	"github.com/LucasFrezarini/go-auth-manager/gqlmodels"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateWebhookSubscription subscribes the URL to the event types. The subscription receives the events of the
+// caller organization, or the events of every organization when the token of the sysadmin isn't scoped to any
+func (r *mutationResolver) CreateWebhookSubscription(ctx context.Context, data gqlmodels.CreateWebhookSubscriptionInput) (*models.WebhookSubscription, error) {
+	organizationID, _ := organizationIDFromContext(ctx)
+	userID, err := userIDFromContext(ctx)
+
+	if err != nil {
+		log.Printf("Error while trying to convert userID to objectID: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create the webhook subscription")
+	}
+
+	subscription := models.WebhookSubscription{
+		OrganizationID: organizationID,
+		URL:            strings.TrimSpace(data.URL),
+		Secret:         data.Secret,
+		EventTypes:     data.EventTypes,
+		Active:         true,
+		CreatedBy:      userID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := webhook.Validate(subscription, webhook.ConfigFromEnv()); err != nil {
+		return nil, gqlerrors.CreateBadUserInputError(err.Error())
+	}
+
+	subscription.ID, err = webhookDao.CreateSubscription(subscription)
+
+	if err != nil {
+		log.Printf("Error while trying to create the webhook subscription: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to create the webhook subscription")
+	}
+
+	return &subscription, nil
+}
+
+// UpdateWebhookSubscription changes the fields of the subscription sent on the input, like rotating its secret
+// or disabling it. The pending deliveries of a disabled subscription fail instead of being sent
+func (r *mutationResolver) UpdateWebhookSubscription(ctx context.Context, id string, data gqlmodels.UpdateWebhookSubscriptionInput) (*models.WebhookSubscription, error) {
+	subscription, err := findWebhookSubscription(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if data.URL != nil {
+		subscription.URL = strings.TrimSpace(*data.URL)
+	}
+
+	if data.Secret != nil {
+		subscription.Secret = *data.Secret
+	}
+
+	if data.EventTypes != nil {
+		subscription.EventTypes = data.EventTypes
+	}
+
+	if data.Active != nil {
+		subscription.Active = *data.Active
+	}
+
+	if err := webhook.Validate(*subscription, webhook.ConfigFromEnv()); err != nil {
+		return nil, gqlerrors.CreateBadUserInputError(err.Error())
+	}
+
+	updated, err := webhookDao.UpdateSubscription(subscription)
+
+	if err != nil {
+		log.Printf("Error while trying to update the webhook subscription: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to update the webhook subscription")
+	}
+
+	return updated, nil
+}
+
+// DeleteWebhookSubscription removes the subscription, keeping its deliveries on the log
+func (r *mutationResolver) DeleteWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	subscription, err := findWebhookSubscription(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = webhookDao.DeleteSubscription(subscription.ID); err != nil {
+		log.Printf("Error while trying to delete the webhook subscription: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to delete the webhook subscription")
+	}
+
+	return subscription, nil
+}
+
+// findWebhookSubscription returns the subscription with the id, as long as it belongs to the caller organization.
+// The global subscriptions are only found with the unscoped token of a sysadmin
+func findWebhookSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	organizationID, _ := organizationIDFromContext(ctx)
+	objectID, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return nil, gqlerrors.CreateBadUserInputError("Invalid webhook subscription id")
+	}
+
+	subscription, err := webhookDao.Subscription(objectID)
+
+	if err == models.ErrWebhookSubscriptionNotFound || (err == nil && subscription.OrganizationID != organizationID) {
+		return nil, gqlerrors.CreateNotFoundError("Webhook subscription not found")
+	}
+
+	if err != nil {
+		log.Printf("Error while trying to fetch the webhook subscription: %v\n", err)
+		return nil, gqlerrors.CreateInternalServerError("Error while trying to fetch the webhook subscription")
+	}
+
+	return subscription, nil
+}
@@ -6,7 +6,15 @@ import (
 	"os"
 
 	"github.com/99designs/gqlgen/handler"
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/deletion"
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/grpcserver"
+	"github.com/LucasFrezarini/go-auth-manager/lockout"
 	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/LucasFrezarini/go-auth-manager/outbox"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
 )
 
 const defaultPort = "8080"
@@ -18,7 +26,46 @@ func main() {
 	}
 
 	http.Handle("/", handler.Playground("GraphQL playground", "/query"))
-	http.Handle("/query", middlewares.MakeHandlers())
+	// The GraphQL and the REST APIs share the rate limits and the resolver
+	services := middlewares.NewServices()
+
+	http.Handle("/query", middlewares.MakeHandlers(services))
+	http.Handle("/authorize", middlewares.MakeAuthorizeHandler())
+	http.Handle("/v1/", middlewares.MakeRESTHandlers(services))
+	http.Handle("/forward-auth", middlewares.MakeForwardAuthHandler())
+
+	// Purges the accounts whose deletion grace period is over
+	purger := deletion.New(&dao.AccountDeletionDao{}, lockout.New(&dao.LoginAttemptDao{}, lockout.ConfigFromEnv()))
+	purger.Start(env.Config.AccountPurgeInterval)
+
+	// Publishes the events of the outbox to the sinks
+	sinks, err := outbox.SinksFromEnv(&dao.WebhookDao{})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The GraphQL subscriptions are fed by the relay too
+	sinks = append(sinks, &outbox.PubSubSink{PubSub: middlewares.Events})
+
+	relay := outbox.New(&dao.OutboxDao{}, outbox.ConfigFromEnv(), sinks...)
+	relay.Start(env.Config.OutboxRelayInterval)
+
+	// Sends the pending deliveries of the webhook outbox
+	dispatcher := webhook.New(&dao.WebhookDao{}, webhook.ConfigFromEnv())
+	dispatcher.Start(env.Config.WebhookDispatchInterval)
+
+	// Serves the gRPC API on its own port
+	accessPolicy, err := policy.FromEnv()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		log.Printf("serving the gRPC API on port %s", env.Config.GRPCPort)
+		log.Fatal(grpcserver.ListenAndServe(env.Config.GRPCPort, accessPolicy))
+	}()
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
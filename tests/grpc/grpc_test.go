@@ -0,0 +1,146 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/authpb"
+	"github.com/LucasFrezarini/go-auth-manager/grpcserver"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/policy"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func generateOrganizationToken(t *testing.T, subject, organization string) string {
+	token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
+		Organization: organization,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    "http://test.io",
+			Subject:   subject,
+			IssuedAt:  time.Now().UTC().Unix(),
+			ExpiresAt: time.Now().UTC().Add(15 * time.Minute).Unix(),
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("Error while trying to get the token for test: %v", err)
+	}
+
+	return token
+}
+
+// startServer serves the gRPC API on a random port and returns a connection to it
+func startServer(t *testing.T) *grpc.ClientConn {
+	engine, err := policy.Load("../policy.yml")
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpcserver.New(engine)
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+}
+
+func TestGRPC(t *testing.T) {
+	conn := startServer(t)
+	defer conn.Close()
+
+	auth := authpb.NewAuthClient(conn)
+	adminToken := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3")
+
+	t.Run("Should validate a token", func(t *testing.T) {
+		response, err := auth.ValidateToken(context.Background(), &authpb.ValidateTokenRequest{Token: adminToken})
+
+		require.NoError(t, err)
+		require.True(t, response.Valid)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d1", response.Claims.Subject)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", response.Claims.Organization)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d1", response.User.Id)
+	})
+
+	t.Run("Should answer an invalid token with valid false", func(t *testing.T) {
+		response, err := auth.ValidateToken(context.Background(), &authpb.ValidateTokenRequest{Token: "invalid"})
+
+		require.NoError(t, err)
+		require.False(t, response.Valid)
+		require.Nil(t, response.User)
+	})
+
+	t.Run("Should let an admin get a member of its organization", func(t *testing.T) {
+		user, err := auth.GetUser(withToken(adminToken), &authpb.GetUserRequest{Id: "5d6e9d1b1c9d440000a1b2d2"})
+
+		require.NoError(t, err)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d2", user.Id)
+		require.NotEmpty(t, user.Email)
+	})
+
+	t.Run("Should let an user get itself", func(t *testing.T) {
+		token := generateOrganizationToken(t, "5d470b3e98b0116d7d8ca48c", "")
+		user, err := auth.GetUser(withToken(token), &authpb.GetUserRequest{Id: "5d470b3e98b0116d7d8ca48c"})
+
+		require.NoError(t, err)
+		require.Equal(t, "test1@test.com", user.Email)
+	})
+
+	t.Run("Should not let a member get other users", func(t *testing.T) {
+		token := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3")
+		_, err := auth.GetUser(withToken(token), &authpb.GetUserRequest{Id: "5d6e9d1b1c9d440000a1b2d1"})
+
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Should not get an user without a token", func(t *testing.T) {
+		_, err := auth.GetUser(context.Background(), &authpb.GetUserRequest{Id: "5d6e9d1b1c9d440000a1b2d1"})
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Should allow the actions granted by the access policy", func(t *testing.T) {
+		response, err := auth.CheckPermission(context.Background(), &authpb.CheckPermissionRequest{
+			Token:    adminToken,
+			Action:   "users:delete",
+			Resource: "users/5d6e9d1b1c9d440000a1b2d2",
+		})
+
+		require.NoError(t, err)
+		require.True(t, response.Allowed)
+		require.Equal(t, "admins-manage-users", response.Rule)
+	})
+
+	t.Run("Should deny the actions not granted by the access policy", func(t *testing.T) {
+		response, err := auth.CheckPermission(context.Background(), &authpb.CheckPermissionRequest{
+			Token:    generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3"),
+			Action:   "users:delete",
+			Resource: "users/5d6e9d1b1c9d440000a1b2d1",
+		})
+
+		require.NoError(t, err)
+		require.False(t, response.Allowed)
+	})
+
+	t.Run("Should report the service as serving", func(t *testing.T) {
+		response, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+			Service: grpcserver.ServiceName,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, healthpb.HealthCheckResponse_SERVING, response.Status)
+	})
+}
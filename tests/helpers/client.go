@@ -26,7 +26,7 @@ func (h HTTPClient) DoRequest(URL, query string, headers map[string]string) ([]b
 		return nil, fmt.Errorf("Error while trying to create request body: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/graphql", URL), bytes.NewBuffer(requestBody))
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/query", URL), bytes.NewBuffer(requestBody))
 
 	if err != nil {
 		return nil, fmt.Errorf("Error while trying to create the request: %v", err)
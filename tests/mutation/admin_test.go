@@ -0,0 +1,157 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	doAdminRequest := func(t *testing.T, query string, response interface{}) {
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	t.Run("Should create an user honoring the roles and active status", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				AdminCreateUser struct {
+					Email  string   `json:"email"`
+					Roles  []string `json:"roles"`
+					Active bool     `json:"active"`
+				} `json:"adminCreateUser"`
+			} `json:"data"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminCreateUser(data: {
+					email: "admin-created@test.com"
+					password: "Str0ng-Passw0rd"
+					roles: ["user", "billing"]
+					active: false
+				}) {
+					email
+					roles
+					active
+				}
+			}
+		`, &expectedResponse)
+
+		data := expectedResponse.Data.AdminCreateUser
+
+		require.Equal(t, "admin-created@test.com", data.Email)
+		require.Equal(t, []string{"user", "billing"}, data.Roles)
+		require.False(t, data.Active)
+	})
+
+	t.Run("Should not allow an organization admin to give the sysadmin role", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminUpdateUser(id: "5d6e9d1b1c9d440000a1b2d3", data: { roles: ["sysadmin"] }) {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "FORBIDDEN", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not allow an admin to manage users of other organizations", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminSetActive(id: "5d470b3e98b0116d7d8ca48c", active: false) {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "NOT_FOUND", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not allow an admin to manage the accounts that joined the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminSetActive(id: "5d6e9d1b1c9d440000a1b2d6", active: false) {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "FORBIDDEN", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should deactivate an user of the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				AdminSetActive struct {
+					Active bool `json:"active"`
+				} `json:"adminSetActive"`
+			} `json:"data"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminSetActive(id: "5d6e9d1b1c9d440000a1b2d3", active: false) {
+					active
+				}
+			}
+		`, &expectedResponse)
+
+		require.False(t, expectedResponse.Data.AdminSetActive.Active)
+	})
+
+	t.Run("Should delete an user of the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				AdminDeleteUser struct {
+					ID string `json:"id"`
+				} `json:"adminDeleteUser"`
+			} `json:"data"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminDeleteUser(id: "5d6e9d1b1c9d440000a1b2d3") {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d3", expectedResponse.Data.AdminDeleteUser.ID)
+	})
+}
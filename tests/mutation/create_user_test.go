@@ -1,145 +1,107 @@
 package mutation_test
 
 import (
-	"encoding/json"
+	"context"
 	"net/http/httptest"
 	"testing"
 
-	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/authclient"
 	"github.com/LucasFrezarini/go-auth-manager/middlewares"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreateUser(t *testing.T) {
-	srv := httptest.NewServer(middlewares.MakeHandlers())
-	c := client.New(srv.URL)
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := authclient.New(srv.URL, authclient.Config{})
+	ctx := context.Background()
 
 	t.Run("Should create a user", func(t *testing.T) {
-		var resp struct {
-			CreateUser struct {
-				User struct {
-					ID        string
-					Email     string
-					Roles     []string
-					CreatedAt string
-					UpdatedAt string
-					Active    bool
-				}
-				Token        string
-				RefreshToken string
-			}
-		}
+		resp, err := c.CreateUser(ctx, authclient.CreateUserInput{
+			Email:    "test@email.com",
+			Password: "Str0ng-Passw0rd",
+			Roles:    []string{"user"},
+		})
 
-		c.MustPost(`
-			mutation {
-				createUser(data:{
-					email: "test@email.com"
-					password: "12345"
-					roles: ["user"]
-				  }) {
-					user {
-					  id
-					  email
-					  roles
-					  active
-					  createdAt
-					  updatedAt
-					}
-					token
-					refreshToken
-				  }
-			}
-		`, &resp)
-
-		require.NotEmpty(t, resp.CreateUser.User.ID)
-		require.Equal(t, "test@email.com", resp.CreateUser.User.Email)
-		require.Equal(t, []string{"user"}, resp.CreateUser.User.Roles)
-		require.True(t, resp.CreateUser.User.Active)
-		require.NotEmpty(t, resp.CreateUser.Token)
-		require.NotEmpty(t, resp.CreateUser.RefreshToken)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.User.ID)
+		require.Equal(t, "test@email.com", resp.User.Email)
+		require.Equal(t, []string{"user"}, resp.User.Roles)
+		require.True(t, resp.User.Active)
+		require.NotEmpty(t, resp.Token)
+		require.NotEmpty(t, resp.RefreshToken)
 	})
 
 	t.Run("Should not create a new user if email already exists", func(t *testing.T) {
-		var errorResponse []struct {
-			Message    string   `json:"message"`
-			Path       []string `json:"path"`
-			Extensions struct {
-				Code string `json:"code"`
-			} `json:"extensions"`
-		}
+		_, err := c.CreateUser(ctx, authclient.CreateUserInput{
+			Email:    "test@email.com",
+			Password: "Str0ng-Passw0rd",
+			Roles:    []string{"user"},
+		})
 
-		err := c.Post(`
-			mutation {
-				createUser(data:{
-					email: "test@email.com"
-					password: "12345"
-					roles: ["user"]
-				  }) {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					token
-				  }
-			}
-		`, &errorResponse)
-
-		json.Unmarshal([]byte(err.Error()), &errorResponse)
-		require.NotZero(t, len(errorResponse))
+		require.Error(t, err)
 
-		response := errorResponse[0]
+		response := err.(*authclient.Error)
 
-		require.Equal(t, response.Message, "User already exists")
-		require.Equal(t, response.Path[0], "createUser")
-		require.Equal(t, response.Extensions.Code, "CONFLICT")
+		require.Equal(t, "User already exists", response.Message)
+		require.Equal(t, []interface{}{"createUser"}, response.Path)
+		require.True(t, response.Is(authclient.ErrConflict))
 	})
 
-	t.Run("Should allow to create a user deactivated by default", func(t *testing.T) {
-		var resp struct {
-			CreateUser struct {
-				User struct {
-					ID        string
-					Email     string
-					Roles     []string
-					Active    bool
-					CreatedAt string
-					UpdatedAt string
-				}
-				Token        string
-				RefreshToken string
-			}
+	t.Run("Should not create a new user if the password doesn't follow the password policy", func(t *testing.T) {
+		var rules []struct {
+			Rule string `json:"rule"`
 		}
 
-		c.MustPost(`
+		_, err := c.CreateUser(ctx, authclient.CreateUserInput{
+			Email:    "weak-password@email.com",
+			Password: "weak",
+		})
+
+		require.Error(t, err)
+
+		response := err.(*authclient.Error)
+
+		require.True(t, response.Is(authclient.ErrBadUserInput))
+		require.NoError(t, response.Extension("rules", &rules))
+		require.Equal(t, 2, len(rules))
+		require.Equal(t, "min_length", rules[0].Rule)
+		require.Equal(t, "character_classes", rules[1].Rule)
+	})
+
+	t.Run("Should not allow to join an organization without an invitation", func(t *testing.T) {
+		err := c.Do(ctx, `
 			mutation {
 				createUser(data:{
-					email: "testdeactivated@email.com"
-					password: "12345"
+					email: "intruder@email.com"
+					password: "Str0ng-Passw0rd"
 					roles: ["user"]
-					active: false
+					organization: "acme"
 				  }) {
-					user {
-					  id
-					  email
-					  roles
-					  active
-					  createdAt
-					  updatedAt
-					}
 					token
-					refreshToken
 				  }
 			}
-		`, &resp)
-
-		require.NotEmpty(t, resp.CreateUser.User.ID)
-		require.Equal(t, "testdeactivated@email.com", resp.CreateUser.User.Email)
-		require.Equal(t, []string{"user"}, resp.CreateUser.User.Roles)
-		require.False(t, resp.CreateUser.User.Active)
-		require.NotEmpty(t, resp.CreateUser.Token)
-		require.NotEmpty(t, resp.CreateUser.RefreshToken)
+		`, nil, nil)
+
+		require.Error(t, err)
+		require.True(t, err.(*authclient.Error).Is(authclient.ErrBadUserInput))
+	})
+
+	t.Run("Should allow to create a user deactivated by default", func(t *testing.T) {
+		active := false
+
+		resp, err := c.CreateUser(ctx, authclient.CreateUserInput{
+			Email:    "testdeactivated@email.com",
+			Password: "Str0ng-Passw0rd",
+			Roles:    []string{"user"},
+			Active:   &active,
+		})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.User.ID)
+		require.Equal(t, "testdeactivated@email.com", resp.User.Email)
+		require.Equal(t, []string{"user"}, resp.User.Roles)
+		require.False(t, resp.User.Active)
+		require.NotEmpty(t, resp.Token)
+		require.NotEmpty(t, resp.RefreshToken)
 	})
 }
@@ -15,7 +15,7 @@ import (
 )
 
 func TestDeactivateUser(t *testing.T) {
-	srv := httptest.NewServer(middlewares.MakeHandlers())
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
 	c := client.New(srv.URL)
 
 	t.Run("Should verify if token is present before deactivate user", func(t *testing.T) {
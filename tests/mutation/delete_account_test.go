@@ -0,0 +1,83 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	doRequest := func(t *testing.T, query string, response interface{}) {
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d4a22e9587f3dbb8d33fd38", ""),
+			"Content-Type":  "application/json",
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	t.Run("Should not schedule the deletion with a wrong password", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doRequest(t, `mutation { deleteMyAccount(password: "wrong") { id } }`, &expectedResponse)
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "UNAUTHORIZED", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should schedule the deletion after the grace period", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				DeleteMyAccount struct {
+					DeletionScheduledAt *string `json:"deletionScheduledAt"`
+				} `json:"deleteMyAccount"`
+			} `json:"data"`
+		}
+
+		doRequest(t, `mutation { deleteMyAccount(password: "12345") { deletionScheduledAt } }`, &expectedResponse)
+
+		require.NotNil(t, expectedResponse.Data.DeleteMyAccount.DeletionScheduledAt)
+	})
+
+	t.Run("Should cancel the scheduled deletion", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				CancelAccountDeletion struct {
+					DeletionScheduledAt *string `json:"deletionScheduledAt"`
+				} `json:"cancelAccountDeletion"`
+			} `json:"data"`
+		}
+
+		doRequest(t, `mutation { cancelAccountDeletion { deletionScheduledAt } }`, &expectedResponse)
+
+		require.Nil(t, expectedResponse.Data.CancelAccountDeletion.DeletionScheduledAt)
+	})
+
+	t.Run("Should not cancel a deletion that wasn't requested", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doRequest(t, `mutation { cancelAccountDeletion { id } }`, &expectedResponse)
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "BAD_USER_INPUT", expectedResponse.Errors[0].Extensions.Code)
+	})
+}
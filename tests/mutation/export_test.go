@@ -0,0 +1,100 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+type exportArchive struct {
+	Profile struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	} `json:"profile"`
+	Memberships []struct {
+		OrganizationSlug string `json:"organization_slug"`
+		Role             string `json:"role"`
+	} `json:"memberships"`
+	Groups []struct {
+		Name string `json:"name"`
+	} `json:"groups"`
+}
+
+func TestExport(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	doRequest := func(t *testing.T, subject, query string, response interface{}) {
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, subject, "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	t.Run("Should export the data of the caller", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				ExportMyData string `json:"exportMyData"`
+			} `json:"data"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d2", `mutation { exportMyData }`, &expectedResponse)
+
+		var archive exportArchive
+
+		if err := json.Unmarshal([]byte(expectedResponse.Data.ExportMyData), &archive); err != nil {
+			t.Fatalf("Error while trying to Unmarshal the archive: %v", err)
+		}
+
+		require.Equal(t, "test6@test.com", archive.Profile.Email)
+		require.Equal(t, 1, len(archive.Memberships))
+		require.Equal(t, "acme", archive.Memberships[0].OrganizationSlug)
+		require.Equal(t, "member", archive.Memberships[0].Role)
+		require.Equal(t, 1, len(archive.Groups))
+		require.Equal(t, "on-call", archive.Groups[0].Name)
+		require.NotContains(t, expectedResponse.Data.ExportMyData, "$2a$10$")
+	})
+
+	t.Run("Should allow an admin to export the data of an user of the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				AdminExportUser string `json:"adminExportUser"`
+			} `json:"data"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", `mutation { adminExportUser(id: "5d6e9d1b1c9d440000a1b2d2") }`, &expectedResponse)
+
+		var archive exportArchive
+
+		if err := json.Unmarshal([]byte(expectedResponse.Data.AdminExportUser), &archive); err != nil {
+			t.Fatalf("Error while trying to Unmarshal the archive: %v", err)
+		}
+
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d2", archive.Profile.ID)
+	})
+
+	t.Run("Should not allow a member to export the data of other users", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d2", `mutation { adminExportUser(id: "5d6e9d1b1c9d440000a1b2d1") }`, &expectedResponse)
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "FORBIDDEN", expectedResponse.Errors[0].Extensions.Code)
+	})
+}
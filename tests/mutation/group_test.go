@@ -0,0 +1,80 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := client.New(srv.URL)
+
+	t.Run("Should add the transitive groups of the user on the access token", func(t *testing.T) {
+		var resp struct {
+			Login struct {
+				Token string
+			}
+		}
+
+		c.MustPost(`
+			mutation {
+				login(data:{
+					email: "test6@test.com"
+					password: "12345"
+					organization: "acme"
+				}) {
+					token
+				}
+			}
+		`, &resp)
+
+		claims, err := jsonwebtoken.Decode(resp.Login.Token)
+
+		require.Empty(t, err)
+		require.Equal(t, []string{"engineering", "on-call"}, claims.Groups)
+	})
+
+	t.Run("Should not allow a group to be nested inside one of its subgroups", func(t *testing.T) {
+		var expectedResponse struct {
+			Data   interface{}         `json:"data"`
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		query := `
+			mutation {
+				addSubgroup(groupId: "5d6e9d1b1c9d440000a1b2f2", subgroupId: "5d6e9d1b1c9d440000a1b2f1") {
+					id
+				}
+			}
+		`
+
+		response, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		err = json.Unmarshal(response, &expectedResponse)
+
+		if err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "BAD_USER_INPUT", expectedResponse.Errors[0].Extensions.Code)
+	})
+}
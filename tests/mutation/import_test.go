@@ -0,0 +1,125 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminImportUsers(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	// The Django hash of the password "12345"
+	query := `
+		mutation {
+			adminImportUsers(
+				format: CSV
+				data: "email,password_hash,roles\nimported@test.com,pbkdf2_sha256$1000$seasalt42$RQxHHc5tWqfPhFWZAfsjXsWJ42Dl1j8R5c8s/s8HRtw=,user\ntest1@test.com,pbkdf2_sha256$1000$seasalt42$RQxHHc5tWqfPhFWZAfsjXsWJ42Dl1j8R5c8s/s8HRtw=,user\nunsupported@test.com,md5$salt$hash,user"
+			) {
+				imported
+				duplicates
+				failures {
+					record
+					email
+					reason
+				}
+			}
+		}
+	`
+
+	t.Run("Should import the users, reporting the duplicates and failures", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				AdminImportUsers struct {
+					Imported   int      `json:"imported"`
+					Duplicates []string `json:"duplicates"`
+					Failures   []struct {
+						Record int    `json:"record"`
+						Email  string `json:"email"`
+					} `json:"failures"`
+				} `json:"adminImportUsers"`
+			} `json:"data"`
+		}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		response, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(response, &expectedResponse); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		data := expectedResponse.Data.AdminImportUsers
+
+		require.Equal(t, 1, data.Imported)
+		require.Equal(t, []string{"test1@test.com"}, data.Duplicates)
+		require.Equal(t, 1, len(data.Failures))
+		require.Equal(t, 3, data.Failures[0].Record)
+		require.Equal(t, "unsupported@test.com", data.Failures[0].Email)
+	})
+
+	t.Run("Should login the imported user with the password of the export", func(t *testing.T) {
+		var resp struct {
+			Login struct {
+				User struct {
+					Email string
+				}
+			}
+		}
+
+		c := client.New(srv.URL)
+
+		c.MustPost(`
+			mutation {
+				login(data:{
+					email: "imported@test.com"
+					password: "12345"
+					organization: "acme"
+				}) {
+					user {
+						email
+					}
+				}
+			}
+		`, &resp)
+
+		require.Equal(t, "imported@test.com", resp.Login.User.Email)
+	})
+
+	t.Run("Should not allow a member to import users", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		response, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(response, &expectedResponse); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "FORBIDDEN", expectedResponse.Errors[0].Extensions.Code)
+	})
+}
@@ -0,0 +1,148 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitation(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := client.New(srv.URL)
+
+	t.Run("Should allow an admin to invite an user to the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				InviteUser struct {
+					Email  string `json:"email"`
+					Role   string `json:"role"`
+					Status string `json:"status"`
+				} `json:"inviteUser"`
+			} `json:"data"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		query := `
+			mutation {
+				inviteUser(email: "invited@test.com", role: "admin") {
+					email
+					role
+					status
+				}
+			}
+		`
+
+		response, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		err = json.Unmarshal(response, &expectedResponse)
+
+		if err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		data := expectedResponse.Data.InviteUser
+
+		require.Equal(t, "invited@test.com", data.Email)
+		require.Equal(t, "admin", data.Role)
+		require.Equal(t, "pending", data.Status)
+	})
+
+	t.Run("Should keep the invitation pending when the invited user can't be created", func(t *testing.T) {
+		var resp tests.ErrorResponse
+
+		err := c.Post(`
+			mutation {
+				acceptInvitation(token: "seeded-invitation-token", password: "weak") {
+					token
+				}
+			}
+		`, &resp)
+
+		json.Unmarshal([]byte(err.Error()), &resp)
+
+		require.Equal(t, 1, len(resp))
+		require.Equal(t, "BAD_USER_INPUT", resp[0].Extensions.Code)
+	})
+
+	t.Run("Should create the invited user when the invitation is accepted", func(t *testing.T) {
+		var resp struct {
+			AcceptInvitation struct {
+				User struct {
+					Email       string
+					Memberships []struct {
+						Role string
+					}
+				}
+				Token string
+			}
+		}
+
+		c.MustPost(`
+			mutation {
+				acceptInvitation(token: "seeded-invitation-token", password: "Str0ng-Passw0rd") {
+					token
+					user {
+						email
+						memberships {
+							role
+						}
+					}
+				}
+			}
+		`, &resp)
+
+		require.Equal(t, "test7@test.com", resp.AcceptInvitation.User.Email)
+		require.Equal(t, 1, len(resp.AcceptInvitation.User.Memberships))
+		require.Equal(t, "member", resp.AcceptInvitation.User.Memberships[0].Role)
+		require.NotEmpty(t, resp.AcceptInvitation.Token)
+	})
+
+	t.Run("Should not allow an invitation to be accepted twice", func(t *testing.T) {
+		var resp tests.ErrorResponse
+
+		err := c.Post(`
+			mutation {
+				acceptInvitation(token: "seeded-invitation-token", password: "Str0ng-Passw0rd") {
+					token
+				}
+			}
+		`, &resp)
+
+		json.Unmarshal([]byte(err.Error()), &resp)
+
+		require.Equal(t, 1, len(resp))
+		require.Equal(t, "UNAUTHORIZED", resp[0].Extensions.Code)
+	})
+
+	t.Run("Should not allow an expired invitation to be accepted", func(t *testing.T) {
+		var resp tests.ErrorResponse
+
+		err := c.Post(`
+			mutation {
+				acceptInvitation(token: "expired-invitation-token", password: "Str0ng-Passw0rd") {
+					token
+				}
+			}
+		`, &resp)
+
+		json.Unmarshal([]byte(err.Error()), &resp)
+
+		require.Equal(t, 1, len(resp))
+		require.Equal(t, "UNAUTHORIZED", resp[0].Extensions.Code)
+	})
+}
@@ -0,0 +1,103 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockout(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	login := func(t *testing.T, password string) tests.ErrorResponse {
+		var response struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		query := fmt.Sprintf(`
+			mutation {
+				login(data: {
+					email: "test10@test.com"
+					password: "%s"
+				}) {
+					token
+				}
+			}
+		`, password)
+
+		body, err := httpClient.DoRequest(srv.URL, query, map[string]string{"Content-Type": "application/json"})
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, &response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		return response.Errors
+	}
+
+	t.Run("Should lock the account after too many failed logins", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			errors := login(t, "wrong")
+
+			require.Equal(t, 1, len(errors))
+			require.Equal(t, "UNAUTHORIZED", errors[0].Extensions.Code)
+		}
+
+		errors := login(t, "wrong")
+
+		require.Equal(t, 1, len(errors))
+		require.Equal(t, "ACCOUNT_LOCKED", errors[0].Extensions.Code)
+	})
+
+	t.Run("Should refuse the right password while the account is locked", func(t *testing.T) {
+		errors := login(t, "12345")
+
+		require.Equal(t, 1, len(errors))
+		require.Equal(t, "ACCOUNT_LOCKED", errors[0].Extensions.Code)
+	})
+
+	t.Run("Should allow the login after an admin unlocks the account", func(t *testing.T) {
+		var response struct {
+			Data struct {
+				AdminUnlockUser struct {
+					Email string `json:"email"`
+				} `json:"adminUnlockUser"`
+			} `json:"data"`
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, `
+			mutation {
+				adminUnlockUser(id: "5d6e9d1b1c9d440000a1b2d4") {
+					email
+				}
+			}
+		`, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, &response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		require.Equal(t, 0, len(response.Errors))
+		require.Equal(t, "test10@test.com", response.Data.AdminUnlockUser.Email)
+		require.Equal(t, 0, len(login(t, "12345")))
+	})
+}
@@ -0,0 +1,231 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+func generateOrganizationToken(t *testing.T, subject, organization string) string {
+	token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
+		Organization: organization,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    "http://test.io",
+			Subject:   subject,
+			IssuedAt:  time.Now().UTC().Unix(),
+			ExpiresAt: time.Now().UTC().Add(15 * time.Minute).Unix(),
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("Error while trying to get the token for test: %v", err)
+	}
+
+	return token
+}
+
+func TestOrganization(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := client.New(srv.URL)
+
+	t.Run("Should scope the token to the organization used on login", func(t *testing.T) {
+		var resp struct {
+			Login struct {
+				Token string
+			}
+		}
+
+		c.MustPost(`
+			mutation {
+				login(data:{
+					email: "test5@test.com"
+					password: "12345"
+					organization: "acme"
+				}) {
+					token
+				}
+			}
+		`, &resp)
+
+		claims, err := jsonwebtoken.Decode(resp.Login.Token)
+
+		require.Empty(t, err)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", claims.Organization)
+	})
+
+	t.Run("Should not allow the login on an organization the user isn't a member of", func(t *testing.T) {
+		var resp tests.ErrorResponse
+
+		err := c.Post(`
+			mutation {
+				login(data:{
+					email: "test1@test.com"
+					password: "12345"
+					organization: "acme"
+				}) {
+					token
+				}
+			}
+		`, &resp)
+
+		json.Unmarshal([]byte(err.Error()), &resp)
+
+		require.Equal(t, 1, len(resp))
+		require.Equal(t, "UNAUTHORIZED", resp[0].Extensions.Code)
+	})
+
+	t.Run("Should list only the users of the caller organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				Users struct {
+					Edges []struct {
+						Node struct {
+							Email string `json:"email"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"users"`
+			} `json:"data"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		response, err := httpClient.DoRequest(srv.URL, `query { users { edges { node { email } } } }`, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		err = json.Unmarshal(response, &expectedResponse)
+
+		if err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		var emails []string
+		for _, edge := range expectedResponse.Data.Users.Edges {
+			emails = append(emails, edge.Node.Email)
+		}
+
+		require.Contains(t, emails, "test5@test.com")
+		require.Contains(t, emails, "test6@test.com")
+		require.NotContains(t, emails, "test1@test.com")
+	})
+
+	t.Run("Should not allow a member to add users to the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data   interface{}         `json:"data"`
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		query := `
+			mutation {
+				addOrganizationMember(email: "test3@test.com", role: "admin") {
+					id
+				}
+			}
+		`
+
+		response, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		err = json.Unmarshal(response, &expectedResponse)
+
+		if err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		require.Empty(t, expectedResponse.Data)
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "FORBIDDEN", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not add a user outside of the organization without an invitation", func(t *testing.T) {
+		var expectedResponse struct {
+			Data   interface{}         `json:"data"`
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		query := `
+			mutation {
+				addOrganizationMember(email: "test1@test.com", role: "member") {
+					id
+				}
+			}
+		`
+
+		response, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		err = json.Unmarshal(response, &expectedResponse)
+
+		if err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		require.Empty(t, expectedResponse.Data)
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "NOT_FOUND", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not accept a token scoped to an organization the user isn't a member of", func(t *testing.T) {
+		var expectedResponse struct {
+			Data   interface{}         `json:"data"`
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d470b3e98b0116d7d8ca48c", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		response, err := httpClient.DoRequest(srv.URL, `query { users { totalCount } }`, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		err = json.Unmarshal(response, &expectedResponse)
+
+		if err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "UNAUTHORIZED", expectedResponse.Errors[0].Extensions.Code)
+	})
+}
@@ -0,0 +1,74 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/LucasFrezarini/go-auth-manager/outbox"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+// relayOutbox publishes all the pending messages of the outbox to the sinks
+func relayOutbox(t *testing.T, sinks ...outbox.Sink) {
+	relay := outbox.New(&dao.OutboxDao{}, outbox.Config{RetryDelay: time.Second, MaxRetryDelay: time.Minute, Lease: time.Minute}, sinks...)
+
+	for {
+		processed, err := relay.Run()
+
+		require.NoError(t, err)
+
+		if processed == 0 {
+			return
+		}
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	c := client.New(httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices())).URL)
+
+	t.Run("Should publish the signup of an user to the sinks", func(t *testing.T) {
+		var resp struct {
+			CreateUser struct {
+				User struct {
+					ID string
+				}
+			}
+		}
+
+		c.MustPost(`
+			mutation {
+				createUser(data: {email: "outbox@test.com", password: "Str0ng-Passw0rd", roles: ["user"]}) {
+					user {
+						id
+					}
+				}
+			}
+		`, &resp)
+
+		broker := &outbox.MemoryBroker{}
+		relayOutbox(t, outbox.NewBrokerSink("test", broker, "auth."))
+
+		var published *webhook.Payload
+
+		for _, message := range broker.Messages() {
+			var payload webhook.Payload
+			require.NoError(t, json.Unmarshal(message.Message, &payload))
+
+			if payload.UserID == resp.CreateUser.User.ID {
+				require.Equal(t, "auth.user.signed_up", message.Topic)
+				require.Equal(t, resp.CreateUser.User.ID, message.Key)
+				published = &payload
+			}
+		}
+
+		require.NotNil(t, published)
+		require.Equal(t, "user.signed_up", published.Type)
+		require.Equal(t, "outbox@test.com", published.Metadata["email"])
+	})
+}
@@ -0,0 +1,156 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordExpiry(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	type errorResponse []struct {
+		Extensions struct {
+			Code  string `json:"code"`
+			Token string `json:"token"`
+		} `json:"extensions"`
+	}
+
+	doRequest := func(t *testing.T, query, token string, response interface{}) {
+		headers := map[string]string{"Content-Type": "application/json"}
+
+		if token != "" {
+			headers["Authorization"] = token
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	login := func(password string) string {
+		return fmt.Sprintf(`
+			mutation {
+				login(data: {
+					email: "test11@test.com"
+					password: "%s"
+				}) {
+					token
+				}
+			}
+		`, password)
+	}
+
+	var restrictedToken string
+
+	t.Run("Should return a restricted token when the password expired", func(t *testing.T) {
+		var response struct {
+			Data   interface{}   `json:"data"`
+			Errors errorResponse `json:"errors"`
+		}
+
+		doRequest(t, login("12345"), "", &response)
+
+		require.Nil(t, response.Data)
+		require.Equal(t, 1, len(response.Errors))
+		require.Equal(t, "PASSWORD_EXPIRED", response.Errors[0].Extensions.Code)
+		require.NotEmpty(t, response.Errors[0].Extensions.Token)
+
+		restrictedToken = response.Errors[0].Extensions.Token
+	})
+
+	t.Run("Should not allow the restricted token to be used for anything else", func(t *testing.T) {
+		var response struct {
+			Errors errorResponse `json:"errors"`
+		}
+
+		doRequest(t, `query { me { id } }`, restrictedToken, &response)
+
+		require.Equal(t, 1, len(response.Errors))
+		require.Equal(t, "FORBIDDEN", response.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not accept the restricted token as a valid token", func(t *testing.T) {
+		var response struct {
+			Data struct {
+				ValidateToken struct {
+					Valid bool `json:"valid"`
+				} `json:"validateToken"`
+			} `json:"data"`
+		}
+
+		doRequest(t, fmt.Sprintf(`mutation { validateToken(token: "%s") { valid } }`, restrictedToken), "", &response)
+
+		require.False(t, response.Data.ValidateToken.Valid)
+	})
+
+	t.Run("Should not allow the expired password to be reused", func(t *testing.T) {
+		var response struct {
+			Errors []struct {
+				Extensions struct {
+					Code  string `json:"code"`
+					Rules []struct {
+						Rule string `json:"rule"`
+					} `json:"rules"`
+				} `json:"extensions"`
+			} `json:"errors"`
+		}
+
+		doRequest(t, `mutation { updateUser(data: { password: "12345" }) { id } }`, restrictedToken, &response)
+
+		require.Equal(t, 1, len(response.Errors))
+		require.Equal(t, "BAD_USER_INPUT", response.Errors[0].Extensions.Code)
+
+		rules := []string{}
+
+		for _, rule := range response.Errors[0].Extensions.Rules {
+			rules = append(rules, rule.Rule)
+		}
+
+		require.Contains(t, rules, "history")
+	})
+
+	t.Run("Should allow the restricted token to change the password", func(t *testing.T) {
+		var response struct {
+			Data struct {
+				UpdateUser struct {
+					Email string `json:"email"`
+				} `json:"updateUser"`
+			} `json:"data"`
+			Errors errorResponse `json:"errors"`
+		}
+
+		doRequest(t, `mutation { updateUser(data: { password: "Renewed-Passw0rd" }) { email } }`, restrictedToken, &response)
+
+		require.Equal(t, 0, len(response.Errors))
+		require.Equal(t, "test11@test.com", response.Data.UpdateUser.Email)
+	})
+
+	t.Run("Should login with the new password", func(t *testing.T) {
+		var response struct {
+			Data struct {
+				Login struct {
+					Token string `json:"token"`
+				} `json:"login"`
+			} `json:"data"`
+			Errors errorResponse `json:"errors"`
+		}
+
+		doRequest(t, login("Renewed-Passw0rd"), "", &response)
+
+		require.Equal(t, 0, len(response.Errors))
+		require.NotEmpty(t, response.Data.Login.Token)
+	})
+}
@@ -0,0 +1,75 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportUnrecognizedLogin(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := client.New(srv.URL)
+
+	t.Run("Should revoke all the sessions of the user", func(t *testing.T) {
+		var loginResponse struct {
+			Login struct {
+				RefreshToken string
+			}
+		}
+
+		c.MustPost(`
+			mutation {
+				login(data: { email: "test3@test.com", password: "12345" }) {
+					refreshToken
+				}
+			}
+		`, &loginResponse)
+
+		token, err := jsonwebtoken.Encode(jsonwebtoken.CreateRevokeSessionsClaims("5d4a22e9587f3dbb8d33fd38"))
+
+		if err != nil {
+			t.Fatalf("Error while trying to get the token for test: %v", err)
+		}
+
+		var resp struct {
+			ReportUnrecognizedLogin bool
+		}
+
+		c.MustPost(fmt.Sprintf(`mutation { reportUnrecognizedLogin(token: "%s") }`, token), &resp)
+
+		require.True(t, resp.ReportUnrecognizedLogin)
+
+		var errorResponse tests.ErrorResponse
+
+		err = c.Post(fmt.Sprintf(`mutation { refreshToken(refreshToken: "%s") { token } }`, loginResponse.Login.RefreshToken), &errorResponse)
+
+		json.Unmarshal([]byte(err.Error()), &errorResponse)
+
+		require.Equal(t, 1, len(errorResponse))
+		require.Equal(t, "UNAUTHORIZED", errorResponse[0].Extensions.Code)
+	})
+
+	t.Run("Should not accept a token of other scope", func(t *testing.T) {
+		token, err := jsonwebtoken.Encode(jsonwebtoken.CreateDefaultClaims("5d4a22e9587f3dbb8d33fd38"))
+
+		if err != nil {
+			t.Fatalf("Error while trying to get the token for test: %v", err)
+		}
+
+		var errorResponse tests.ErrorResponse
+
+		err = c.Post(fmt.Sprintf(`mutation { reportUnrecognizedLogin(token: "%s") }`, token), &errorResponse)
+
+		json.Unmarshal([]byte(err.Error()), &errorResponse)
+
+		require.Equal(t, 1, len(errorResponse))
+		require.Equal(t, "UNAUTHORIZED", errorResponse[0].Extensions.Code)
+	})
+}
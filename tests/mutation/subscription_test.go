@@ -0,0 +1,184 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/LucasFrezarini/go-auth-manager/outbox"
+	"github.com/LucasFrezarini/go-auth-manager/pubsub"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForSubscribers waits until the number of subscriptions listening to the events is the expected. The websockets
+// start and end their subscriptions in the background, after the client is done
+func waitForSubscribers(t *testing.T, subscribers int) {
+	events := middlewares.Events.(*pubsub.Memory)
+
+	for i := 0; i < 100; i++ {
+		if events.Subscribers() == subscribers {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("Expected %d subscribers, got %d", subscribers, events.Subscribers())
+}
+
+type subscriptionEvent struct {
+	ID             string `json:"id"`
+	Action         string `json:"action"`
+	TargetID       string `json:"targetId"`
+	OrganizationID string `json:"organizationId"`
+}
+
+func TestSubscriptions(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	defer srv.Close()
+
+	c := client.New(srv.URL)
+	httpClient := tests.HTTPClient{}
+	adminToken := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3")
+	var userID string
+
+	doAdminRequest := func(t *testing.T, query string, response interface{}) {
+		body, err := httpClient.DoRequest(srv.URL, query, map[string]string{
+			"Authorization": adminToken,
+			"Content-Type":  "application/json",
+		})
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	t.Run("Should stream the events of the organization to the admins", func(t *testing.T) {
+		waitForSubscribers(t, 0)
+
+		subscription := c.WebsocketWithPayload(`subscription { userEvents { id action targetId organizationId } }`,
+			map[string]interface{}{"Authorization": adminToken})
+		defer subscription.Close()
+
+		waitForSubscribers(t, 1)
+
+		var createResponse struct {
+			Data struct {
+				AdminCreateUser struct {
+					ID string `json:"id"`
+				} `json:"adminCreateUser"`
+			} `json:"data"`
+		}
+
+		doAdminRequest(t, `
+			mutation {
+				adminCreateUser(data: {email: "subscription@test.com", password: "Str0ng-Passw0rd", roles: ["user"]}) {
+					id
+				}
+			}
+		`, &createResponse)
+
+		userID = createResponse.Data.AdminCreateUser.ID
+
+		relayOutbox(t, &outbox.PubSubSink{PubSub: middlewares.Events})
+
+		var resp struct {
+			UserEvents subscriptionEvent
+		}
+
+		require.NoError(t, subscription.Next(&resp))
+		require.Equal(t, "user.created", resp.UserEvents.Action)
+		require.Equal(t, userID, resp.UserEvents.TargetID)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", resp.UserEvents.OrganizationID)
+	})
+
+	t.Run("Should tell an user that its sessions were revoked", func(t *testing.T) {
+		waitForSubscribers(t, 0)
+
+		subscription := c.WebsocketWithPayload(`subscription { mySessionEvents { action targetId } }`,
+			map[string]interface{}{"Authorization": generateOrganizationToken(t, userID, "")})
+		defer subscription.Close()
+
+		waitForSubscribers(t, 1)
+
+		doAdminRequest(t, fmt.Sprintf(`
+			mutation {
+				adminRevokeSessions(id: "%s") {
+					id
+				}
+			}
+		`, userID), &struct{}{})
+
+		relayOutbox(t, &outbox.PubSubSink{PubSub: middlewares.Events})
+
+		var resp struct {
+			MySessionEvents subscriptionEvent
+		}
+
+		require.NoError(t, subscription.Next(&resp))
+		require.Equal(t, "user.sessions_revoked", resp.MySessionEvents.Action)
+		require.Equal(t, userID, resp.MySessionEvents.TargetID)
+	})
+
+	t.Run("Should end the subscription when its token expires", func(t *testing.T) {
+		waitForSubscribers(t, 0)
+
+		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
+			Organization: "5d6e9d1b1c9d440000a1b2c3",
+			StandardClaims: jwt.StandardClaims{
+				Issuer:    "http://test.io",
+				Subject:   "5d6e9d1b1c9d440000a1b2d1",
+				IssuedAt:  time.Now().UTC().Unix(),
+				ExpiresAt: time.Now().UTC().Add(time.Second).Unix(),
+			},
+		})
+
+		require.NoError(t, err)
+
+		subscription := c.WebsocketWithPayload(`subscription { userEvents { id } }`,
+			map[string]interface{}{"Authorization": token})
+		defer subscription.Close()
+
+		waitForSubscribers(t, 1)
+		time.Sleep(2 * time.Second)
+		waitForSubscribers(t, 0)
+	})
+
+	t.Run("Should not stream the events of the organization to a member", func(t *testing.T) {
+		subscription := c.WebsocketWithPayload(`subscription { userEvents { id } }`,
+			map[string]interface{}{"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3")})
+		defer subscription.Close()
+
+		var resp struct {
+			UserEvents subscriptionEvent
+		}
+
+		err := subscription.Next(&resp)
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Forbidden")
+	})
+
+	t.Run("Should refuse the websockets opened with an invalid token", func(t *testing.T) {
+		subscription := c.WebsocketWithPayload(`subscription { mySessionEvents { id } }`,
+			map[string]interface{}{"Authorization": "invalid"})
+		defer subscription.Close()
+
+		var resp struct {
+			MySessionEvents subscriptionEvent
+		}
+
+		require.Error(t, subscription.Next(&resp))
+	})
+}
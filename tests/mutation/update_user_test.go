@@ -15,7 +15,7 @@ import (
 )
 
 func TestUpdateUser(t *testing.T) {
-	srv := httptest.NewServer(middlewares.MakeHandlers())
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
 	c := client.New(srv.URL)
 
 	t.Run("Should verify if token is present before update user", func(t *testing.T) {
@@ -59,7 +59,7 @@ func TestUpdateUser(t *testing.T) {
 		query := `
 			mutation {
 				updateUser(data:{
-					password: "changed"
+					password: "Changed-Passw0rd"
 				}) {
 					id
 					email
@@ -123,7 +123,7 @@ func TestUpdateUser(t *testing.T) {
 		query := `
 			mutation {
 				updateUser(data:{
-					password: "changed"
+					password: "Changed-Passw0rd"
 				}) {
 					id
 					email
@@ -191,7 +191,7 @@ func TestUpdateUser(t *testing.T) {
 		query := `
 			mutation {
 				updateUser(data:{
-					password: "changed"
+					password: "Changed-Passw0rd"
 				}) {
 					id
 					email
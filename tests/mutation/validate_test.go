@@ -1,57 +1,30 @@
 package mutation_test
 
 import (
-	"fmt"
+	"context"
 	"net/http/httptest"
 	"testing"
 	"time"
 
-	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/authclient"
 	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
 	"github.com/LucasFrezarini/go-auth-manager/middlewares"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/stretchr/testify/require"
 )
 
-type validateResponse struct {
-	ValidateToken struct {
-		User struct {
-			ID        string
-			Email     string
-			Roles     []string
-			CreatedAt string
-			UpdatedAt string
-		}
-		Claims struct {
-			Iss string
-			Sub string
-			Exp float64
-			Iat float64
-		}
-		Valid bool
-	}
-}
-
-func requireResponseIsInvalid(t *testing.T, resp validateResponse) {
-	validateToken := resp.ValidateToken
-
-	require.Empty(t, validateToken.User.ID)
-	require.Empty(t, validateToken.User.Email)
-	require.Empty(t, validateToken.User.Roles)
-	require.Empty(t, validateToken.Claims.Iss)
-	require.Empty(t, validateToken.Claims.Sub)
-	require.Empty(t, validateToken.Claims.Exp)
-	require.Empty(t, validateToken.Claims.Iat)
-	require.False(t, validateToken.Valid)
+func requireResponseIsInvalid(t *testing.T, resp *authclient.ValidateTokenPayload) {
+	require.Nil(t, resp.User)
+	require.Nil(t, resp.Claims)
+	require.False(t, resp.Valid)
 }
 
 func TestValidate(t *testing.T) {
-	srv := httptest.NewServer(middlewares.MakeHandlers())
-	c := client.New(srv.URL)
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := authclient.New(srv.URL, authclient.Config{})
+	ctx := context.Background()
 
 	t.Run("Should return true in validate if token is valid", func(t *testing.T) {
-		var resp validateResponse
-
 		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
 			StandardClaims: jwt.StandardClaims{
 				Issuer:    "http://test.io",
@@ -65,68 +38,30 @@ func TestValidate(t *testing.T) {
 			t.FailNow()
 		}
 
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "%s") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-						exp
-						iat
-					}
-					valid
-				  }
-			}
-		`, token), &resp)
-
-		validateToken := resp.ValidateToken
+		resp, err := c.ValidateToken(ctx, token)
+		require.NoError(t, err)
 
-		require.Equal(t, validateToken.User.ID, "5d470b3e98b0116d7d8ca48c")
-		require.Equal(t, "test1@test.com", validateToken.User.Email)
-		require.Equal(t, []string{"user"}, validateToken.User.Roles)
+		require.NotNil(t, resp.User)
+		require.NotNil(t, resp.Claims)
+		require.Equal(t, resp.User.ID, "5d470b3e98b0116d7d8ca48c")
+		require.Equal(t, "test1@test.com", resp.User.Email)
+		require.Equal(t, []string{"user"}, resp.User.Roles)
 
-		require.Equal(t, validateToken.Claims.Iss, "http://test.io")
-		require.Equal(t, validateToken.Claims.Sub, "5d470b3e98b0116d7d8ca48c")
-		require.NotEmpty(t, validateToken.Claims.Exp)
-		require.NotEmpty(t, validateToken.Claims.Iat)
-		require.True(t, validateToken.Valid)
+		require.Equal(t, resp.Claims.Iss, "http://test.io")
+		require.Equal(t, resp.Claims.Sub, "5d470b3e98b0116d7d8ca48c")
+		require.NotEmpty(t, resp.Claims.Exp)
+		require.NotEmpty(t, resp.Claims.Iat)
+		require.True(t, resp.Valid)
 	})
 
 	t.Run("Should return false in validate if token is invalid and no infos", func(t *testing.T) {
-		var resp validateResponse
-
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "invalid") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-					}
-					valid
-				  }
-			}
-		`), &resp)
+		resp, err := c.ValidateToken(ctx, "invalid")
+		require.NoError(t, err)
 
 		requireResponseIsInvalid(t, resp)
 	})
 
 	t.Run("Should return false and empty fields if the owner of the token is inactive", func(t *testing.T) {
-		var resp validateResponse
-
 		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
 			StandardClaims: jwt.StandardClaims{
 				Issuer:    "http://test.io",
@@ -140,31 +75,13 @@ func TestValidate(t *testing.T) {
 			t.FailNow()
 		}
 
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "%s") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-					}
-					valid
-				  }
-			}
-		`, token), &resp)
+		resp, err := c.ValidateToken(ctx, token)
+		require.NoError(t, err)
 
 		requireResponseIsInvalid(t, resp)
 	})
 
 	t.Run("Token should be invalid if the sub id doesn't exists on the database", func(t *testing.T) {
-		var resp validateResponse
-
 		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
 			StandardClaims: jwt.StandardClaims{
 				Issuer:    "http://test.io",
@@ -178,31 +95,13 @@ func TestValidate(t *testing.T) {
 			t.FailNow()
 		}
 
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "%s") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-					}
-					valid
-				  }
-			}
-		`, token), &resp)
+		resp, err := c.ValidateToken(ctx, token)
+		require.NoError(t, err)
 
 		requireResponseIsInvalid(t, resp)
 	})
 
 	t.Run("Token should be invalid if the issuer from token is different than the auth manager server", func(t *testing.T) {
-		var resp validateResponse
-
 		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
 			StandardClaims: jwt.StandardClaims{
 				Issuer:    "http://another.site.io",
@@ -216,31 +115,13 @@ func TestValidate(t *testing.T) {
 			t.FailNow()
 		}
 
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "%s") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-					}
-					valid
-				  }
-			}
-		`, token), &resp)
+		resp, err := c.ValidateToken(ctx, token)
+		require.NoError(t, err)
 
 		requireResponseIsInvalid(t, resp)
 	})
 
 	t.Run("Token should be invalid if its expired", func(t *testing.T) {
-		var resp validateResponse
-
 		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
 			StandardClaims: jwt.StandardClaims{
 				Issuer:    "http://test.io",
@@ -254,31 +135,13 @@ func TestValidate(t *testing.T) {
 			t.FailNow()
 		}
 
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "%s") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-					}
-					valid
-				  }
-			}
-		`, token), &resp)
+		resp, err := c.ValidateToken(ctx, token)
+		require.NoError(t, err)
 
 		requireResponseIsInvalid(t, resp)
 	})
 
 	t.Run("Token should be invalid if has no exp claim", func(t *testing.T) {
-		var resp validateResponse
-
 		token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
 			StandardClaims: jwt.StandardClaims{
 				Issuer:   "http://test.io",
@@ -291,24 +154,8 @@ func TestValidate(t *testing.T) {
 			t.FailNow()
 		}
 
-		c.MustPost(fmt.Sprintf(`
-			mutation {
-				validateToken(token: "%s") {
-					user {
-					  id
-					  email
-					  roles
-					  createdAt
-					  updatedAt
-					}
-					claims {
-						iss
-						sub
-					}
-					valid
-				  }
-			}
-		`, token), &resp)
+		resp, err := c.ValidateToken(ctx, token)
+		require.NoError(t, err)
 
 		requireResponseIsInvalid(t, resp)
 	})
@@ -0,0 +1,226 @@
+package mutation_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/dao"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/LucasFrezarini/go-auth-manager/outbox"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	var mutex sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		received = append(received, r)
+		bodies = append(bodies, body)
+	}))
+	defer receiver.Close()
+
+	const secret = "integration-webhook-secret"
+	var subscriptionID string
+
+	doRequest := func(t *testing.T, subject, query string, response interface{}) {
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, subject, "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	t.Run("Should subscribe an URL to the events of the organization", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				CreateWebhookSubscription struct {
+					ID         string   `json:"id"`
+					URL        string   `json:"url"`
+					EventTypes []string `json:"eventTypes"`
+					Active     bool     `json:"active"`
+				} `json:"createWebhookSubscription"`
+			} `json:"data"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", fmt.Sprintf(`
+			mutation {
+				createWebhookSubscription(data: {url: "%s", secret: "%s", eventTypes: ["user.created"]}) {
+					id
+					url
+					eventTypes
+					active
+				}
+			}
+		`, receiver.URL, secret), &expectedResponse)
+
+		data := expectedResponse.Data.CreateWebhookSubscription
+		subscriptionID = data.ID
+
+		require.NotEmpty(t, data.ID)
+		require.Equal(t, receiver.URL, data.URL)
+		require.Equal(t, []string{"user.created"}, data.EventTypes)
+		require.True(t, data.Active)
+	})
+
+	t.Run("Should deliver the signed event to the subscription", func(t *testing.T) {
+		var createResponse struct {
+			Data struct {
+				AdminCreateUser struct {
+					ID string `json:"id"`
+				} `json:"adminCreateUser"`
+			} `json:"data"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", `
+			mutation {
+				adminCreateUser(data: {email: "webhook@test.com", password: "Str0ng-Passw0rd", roles: ["user"]}) {
+					id
+				}
+			}
+		`, &createResponse)
+
+		relayOutbox(t, &outbox.WebhookSink{Store: &dao.WebhookDao{}})
+
+		config := webhook.Config{MaxAttempts: 3, RetryDelay: time.Second, MaxRetryDelay: time.Minute, Timeout: 5 * time.Second, AllowPrivateNetworks: true}
+		delivered, err := webhook.New(&dao.WebhookDao{}, config).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, delivered)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		require.Equal(t, 1, len(received))
+
+		timestamp, err := strconv.ParseInt(received[0].Header.Get(webhook.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.True(t, webhook.Verify(secret, timestamp, bodies[0], received[0].Header.Get(webhook.HeaderSignature)))
+
+		var payload webhook.Payload
+		require.NoError(t, json.Unmarshal(bodies[0], &payload))
+		require.Equal(t, "user.created", payload.Type)
+		require.Equal(t, createResponse.Data.AdminCreateUser.ID, payload.UserID)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", payload.OrganizationID)
+		require.Equal(t, "webhook@test.com", payload.Metadata["email"])
+	})
+
+	t.Run("Should list the deliveries of the subscription", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				WebhookDeliveries []struct {
+					EventType      string `json:"eventType"`
+					Status         string `json:"status"`
+					Attempts       int    `json:"attempts"`
+					ResponseStatus int    `json:"responseStatus"`
+				} `json:"webhookDeliveries"`
+			} `json:"data"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", fmt.Sprintf(`
+			query {
+				webhookDeliveries(subscriptionId: "%s") {
+					eventType
+					status
+					attempts
+					responseStatus
+				}
+			}
+		`, subscriptionID), &expectedResponse)
+
+		deliveries := expectedResponse.Data.WebhookDeliveries
+
+		require.Equal(t, 1, len(deliveries))
+		require.Equal(t, "user.created", deliveries[0].EventType)
+		require.Equal(t, "delivered", deliveries[0].Status)
+		require.Equal(t, 1, deliveries[0].Attempts)
+		require.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+	})
+
+	t.Run("Should refuse an invalid subscription", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", `
+			mutation {
+				createWebhookSubscription(data: {url: "http://hooks.test", secret: "short", eventTypes: ["user.created"]}) {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Equal(t, "BAD_USER_INPUT", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not allow a member to manage the webhooks", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d2", `
+			query {
+				webhookSubscriptions {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Equal(t, "FORBIDDEN", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should remove the subscription", func(t *testing.T) {
+		var expectedResponse struct {
+			Data struct {
+				WebhookSubscriptions []struct {
+					ID string `json:"id"`
+				} `json:"webhookSubscriptions"`
+			} `json:"data"`
+		}
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", fmt.Sprintf(`
+			mutation {
+				deleteWebhookSubscription(id: "%s") {
+					id
+				}
+			}
+		`, subscriptionID), &struct{}{})
+
+		doRequest(t, "5d6e9d1b1c9d440000a1b2d1", `
+			query {
+				webhookSubscriptions {
+					id
+				}
+			}
+		`, &expectedResponse)
+
+		require.Empty(t, expectedResponse.Data.WebhookSubscriptions)
+	})
+}
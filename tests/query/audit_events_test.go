@@ -0,0 +1,123 @@
+package query_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+type auditEventsResponse struct {
+	Data struct {
+		AuditEvents struct {
+			Edges []struct {
+				Node struct {
+					Action         string                 `json:"action"`
+					TargetID       *string                `json:"targetId"`
+					OrganizationID *string                `json:"organizationId"`
+					IP             *string                `json:"ip"`
+					Metadata       map[string]interface{} `json:"metadata"`
+				} `json:"node"`
+			} `json:"edges"`
+			PageInfo struct {
+				HasNextPage bool    `json:"hasNextPage"`
+				EndCursor   *string `json:"endCursor"`
+			} `json:"pageInfo"`
+			TotalCount int `json:"totalCount"`
+		} `json:"auditEvents"`
+	} `json:"data"`
+	Errors tests.ErrorResponse `json:"errors"`
+}
+
+func TestAuditEvents(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	doRequest := func(t *testing.T, query string, headers map[string]string, response interface{}) {
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, response); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+	}
+
+	adminHeaders := map[string]string{
+		"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+		"Content-Type":  "application/json",
+	}
+
+	t.Run("Should record the failed logins with the account and the organization", func(t *testing.T) {
+		var loginResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		doRequest(t, `
+			mutation {
+				login(data: { email: "test6@test.com", password: "wrong", organization: "acme" }) {
+					token
+				}
+			}
+		`, map[string]string{"Content-Type": "application/json"}, &loginResponse)
+
+		require.Equal(t, "UNAUTHORIZED", loginResponse.Errors[0].Extensions.Code)
+
+		var resp auditEventsResponse
+
+		doRequest(t, `
+			query {
+				auditEvents(filter: { action: "user.login_failed", targetId: "5d6e9d1b1c9d440000a1b2d2" }) {
+					edges { node { action targetId organizationId ip metadata } }
+					totalCount
+				}
+			}
+		`, adminHeaders, &resp)
+
+		require.Equal(t, 0, len(resp.Errors))
+		require.NotEmpty(t, resp.Data.AuditEvents.Edges)
+
+		event := resp.Data.AuditEvents.Edges[0].Node
+
+		require.Equal(t, "user.login_failed", event.Action)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d2", *event.TargetID)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", *event.OrganizationID)
+		require.NotEmpty(t, *event.IP)
+		require.Equal(t, "invalid_password", event.Metadata["reason"])
+	})
+
+	t.Run("Should paginate the events", func(t *testing.T) {
+		var firstPage auditEventsResponse
+
+		doRequest(t, `query { auditEvents(first: 1) { edges { node { action } } pageInfo { hasNextPage endCursor } totalCount } }`, adminHeaders, &firstPage)
+
+		require.Equal(t, 1, len(firstPage.Data.AuditEvents.Edges))
+
+		if firstPage.Data.AuditEvents.TotalCount > 1 {
+			require.True(t, firstPage.Data.AuditEvents.PageInfo.HasNextPage)
+
+			var secondPage auditEventsResponse
+
+			doRequest(t, `query { auditEvents(first: 1, after: "`+*firstPage.Data.AuditEvents.PageInfo.EndCursor+`") { edges { node { action } } } }`, adminHeaders, &secondPage)
+
+			require.Equal(t, 1, len(secondPage.Data.AuditEvents.Edges))
+		}
+	})
+
+	t.Run("Should not allow a member to list the audit events", func(t *testing.T) {
+		var resp auditEventsResponse
+
+		doRequest(t, `query { auditEvents { totalCount } }`, map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}, &resp)
+
+		require.Equal(t, 1, len(resp.Errors))
+		require.Equal(t, "FORBIDDEN", resp.Errors[0].Extensions.Code)
+	})
+}
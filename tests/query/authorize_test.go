@@ -0,0 +1,88 @@
+package query_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+func generateOrganizationToken(t *testing.T, subject, organization string) string {
+	token, err := jsonwebtoken.Encode(jsonwebtoken.Claims{
+		Organization: organization,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    "http://test.io",
+			Subject:   subject,
+			IssuedAt:  time.Now().UTC().Unix(),
+			ExpiresAt: time.Now().UTC().Add(15 * time.Minute).Unix(),
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("Error while trying to get the token for test: %v", err)
+	}
+
+	return token
+}
+
+func TestAuthorizeEndpoint(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeAuthorizeHandler())
+
+	doRequest := func(t *testing.T, token string) (*http.Response, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"action":   "users:update",
+			"resource": "users/5d470b3e98b0116d7d8ca48c",
+		})
+
+		req, err := http.NewRequest("POST", srv.URL+"/authorize", bytes.NewBuffer(body))
+
+		if err != nil {
+			t.Fatalf("Error while trying to create the request: %v", err)
+		}
+
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		defer resp.Body.Close()
+
+		var decision map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&decision)
+
+		return resp, decision
+	}
+
+	t.Run("Should allow the action when a rule of the policy matches", func(t *testing.T) {
+		resp, decision := doRequest(t, generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"))
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, true, decision["allowed"])
+		require.Equal(t, "admins-manage-users", decision["rule"])
+	})
+
+	t.Run("Should deny the action when no rule of the policy matches", func(t *testing.T) {
+		resp, decision := doRequest(t, generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3"))
+
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Equal(t, false, decision["allowed"])
+	})
+
+	t.Run("Should require an authenticated user", func(t *testing.T) {
+		resp, _ := doRequest(t, "")
+
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,67 @@
+package query_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMyLoginHistory(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	c := client.New(srv.URL)
+
+	t.Run("Should list the logins of the authenticated user, the newest first", func(t *testing.T) {
+		var loginResponse struct {
+			Login struct {
+				Token string
+			}
+		}
+
+		c.MustPost(`
+			mutation {
+				login(data: { email: "test1@test.com", password: "12345" }) {
+					token
+				}
+			}
+		`, &loginResponse)
+
+		var resp struct {
+			Data struct {
+				MyLoginHistory struct {
+					Edges []struct {
+						Node struct {
+							Action   string `json:"action"`
+							TargetID string `json:"targetId"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"myLoginHistory"`
+			} `json:"data"`
+		}
+
+		httpClient := tests.HTTPClient{}
+
+		body, err := httpClient.DoRequest(srv.URL, `query { myLoginHistory(first: 5) { edges { node { action targetId } } } }`, map[string]string{
+			"Authorization": loginResponse.Login.Token,
+			"Content-Type":  "application/json",
+		})
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		edges := resp.Data.MyLoginHistory.Edges
+
+		require.NotEmpty(t, edges)
+		require.Equal(t, "user.login", edges[0].Node.Action)
+		require.Equal(t, "5d470b3e98b0116d7d8ca48c", edges[0].Node.TargetID)
+	})
+}
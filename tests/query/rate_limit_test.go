@@ -0,0 +1,117 @@
+package query_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/LucasFrezarini/go-auth-manager/ratelimit"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		ratelimit.DefaultOperation: {Requests: 100, Period: time.Minute},
+		"login":                    {Requests: 2, Period: time.Minute},
+		"refreshToken":             {Requests: 2, Period: time.Minute},
+	})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{}}`))
+	})
+
+	srv := httptest.NewServer(middlewares.RateLimit(limiter, next))
+
+	doRequest := func(t *testing.T, query string) *http.Response {
+		body, _ := json.Marshal(map[string]string{"query": query})
+		resp, err := http.Post(srv.URL, "application/json", bytes.NewBuffer(body))
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		return resp
+	}
+
+	login := `mutation { login(data: { email: "test1@test.com", password: "12345" }) { token } }`
+
+	t.Run("Should allow the requests under the limit of the operation", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			resp := doRequest(t, login)
+			resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("Should refuse the requests over the limit with a Retry-After header", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		resp := doRequest(t, login)
+		defer resp.Body.Close()
+
+		json.NewDecoder(resp.Body).Decode(&expectedResponse)
+
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.NotEmpty(t, resp.Header.Get("Retry-After"))
+		require.Equal(t, 1, len(expectedResponse.Errors))
+		require.Equal(t, "RATE_LIMITED", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should count the operations called through fragments", func(t *testing.T) {
+		resp := doRequest(t, `
+			mutation { ...Login }
+			fragment Login on Mutation { login(data: { email: "test1@test.com", password: "12345" }) { token } }
+		`)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+
+	t.Run("Should keep allowing the operations with a different limit", func(t *testing.T) {
+		resp := doRequest(t, `query { me { id } }`)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Should refuse without a Retry-After the aliases calling an operation more times than its limit", func(t *testing.T) {
+		var expectedResponse struct {
+			Errors tests.ErrorResponse `json:"errors"`
+		}
+
+		resp := doRequest(t, `
+			mutation {
+				a: refreshToken(refreshToken: "token") { token }
+				b: refreshToken(refreshToken: "token") { token }
+				c: refreshToken(refreshToken: "token") { token }
+			}
+		`)
+		defer resp.Body.Close()
+
+		json.NewDecoder(resp.Body).Decode(&expectedResponse)
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Retry-After"))
+		require.Equal(t, "BAD_USER_INPUT", expectedResponse.Errors[0].Extensions.Code)
+	})
+
+	t.Run("Should not count the calls of a refused request", func(t *testing.T) {
+		resp := doRequest(t, `
+			mutation {
+				a: refreshToken(refreshToken: "token") { token }
+				b: refreshToken(refreshToken: "token") { token }
+			}
+		`)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,98 @@
+package query_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/authclient"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+type userResponse struct {
+	Data struct {
+		User *struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+		} `json:"user"`
+	} `json:"data"`
+	Errors tests.ErrorResponse `json:"errors"`
+}
+
+func TestUser(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	doRequest := func(t *testing.T, query, token string) userResponse {
+		var resp userResponse
+
+		headers := map[string]string{
+			"Content-Type": "application/json",
+		}
+
+		if token != "" {
+			headers["Authorization"] = token
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		return resp
+	}
+
+	t.Run("Should return the authenticated user", func(t *testing.T) {
+		token := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3")
+		c := authclient.New(srv.URL, authclient.Config{
+			Store: authclient.NewMemoryStore(authclient.Tokens{AccessToken: token}),
+		})
+
+		me, err := c.Me(context.Background())
+
+		require.NoError(t, err)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d2", me.ID)
+		require.Equal(t, "test6@test.com", me.Email)
+	})
+
+	t.Run("Should return error if the me query isn't authenticated", func(t *testing.T) {
+		c := authclient.New(srv.URL, authclient.Config{})
+
+		_, err := c.Me(context.Background())
+
+		require.Error(t, err)
+		require.True(t, err.(*authclient.Error).Is(authclient.ErrUnauthorized))
+	})
+
+	t.Run("Should return a member of the organization to its admins", func(t *testing.T) {
+		token := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3")
+		resp := doRequest(t, `query { user(id: "5d6e9d1b1c9d440000a1b2d2") { id email } }`, token)
+
+		require.Equal(t, 0, len(resp.Errors))
+		require.Equal(t, "test6@test.com", resp.Data.User.Email)
+	})
+
+	t.Run("Should not return users outside of the organization", func(t *testing.T) {
+		token := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3")
+		resp := doRequest(t, `query { user(id: "5d470b3e98b0116d7d8ca48c") { id email } }`, token)
+
+		require.Equal(t, 0, len(resp.Errors))
+		require.Nil(t, resp.Data.User)
+	})
+
+	t.Run("Should return error if the caller isn't an admin", func(t *testing.T) {
+		token := generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d2", "5d6e9d1b1c9d440000a1b2c3")
+		resp := doRequest(t, `query { user(id: "5d6e9d1b1c9d440000a1b2d1") { id email } }`, token)
+
+		require.Equal(t, 1, len(resp.Errors))
+		require.Equal(t, "FORBIDDEN", resp.Errors[0].Extensions.Code)
+	})
+}
@@ -0,0 +1,101 @@
+package query_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	tests "github.com/LucasFrezarini/go-auth-manager/tests/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+type usersResponse struct {
+	Data struct {
+		Users struct {
+			TotalCount int `json:"totalCount"`
+			Edges      []struct {
+				Node struct {
+					Email string `json:"email"`
+				} `json:"node"`
+			} `json:"edges"`
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+		} `json:"users"`
+	} `json:"data"`
+	Errors tests.ErrorResponse `json:"errors"`
+}
+
+func TestUsers(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeHandlers(middlewares.NewServices()))
+	httpClient := tests.HTTPClient{}
+
+	doRequest := func(t *testing.T, query string) usersResponse {
+		var resp usersResponse
+
+		headers := map[string]string{
+			"Authorization": generateOrganizationToken(t, "5d6e9d1b1c9d440000a1b2d1", "5d6e9d1b1c9d440000a1b2c3"),
+			"Content-Type":  "application/json",
+		}
+
+		body, err := httpClient.DoRequest(srv.URL, query, headers)
+
+		if err != nil {
+			t.Fatalf("Error while doing the request: %v", err)
+		}
+
+		if err = json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("Error while trying to Unmarshal response: %v", err)
+		}
+
+		return resp
+	}
+
+	t.Run("Should filter the users by the email prefix", func(t *testing.T) {
+		resp := doRequest(t, `
+			query {
+				users(filter: { emailPrefix: "test5" }) {
+					totalCount
+					edges { node { email } }
+				}
+			}
+		`)
+
+		require.Equal(t, 1, resp.Data.Users.TotalCount)
+		require.Equal(t, "test5@test.com", resp.Data.Users.Edges[0].Node.Email)
+	})
+
+	t.Run("Should paginate the users sorted by email", func(t *testing.T) {
+		query := `
+			query {
+				users(first: 1, %s filter: { emailPrefix: "test" }, sort: { field: EMAIL, direction: ASC }) {
+					totalCount
+					edges { node { email } }
+					pageInfo { hasNextPage endCursor }
+				}
+			}
+		`
+
+		first := doRequest(t, fmt.Sprintf(query, ""))
+
+		require.Equal(t, 1, len(first.Data.Users.Edges))
+		require.Equal(t, "test10@test.com", first.Data.Users.Edges[0].Node.Email)
+		require.True(t, first.Data.Users.PageInfo.HasNextPage)
+		require.True(t, first.Data.Users.TotalCount > 1)
+
+		second := doRequest(t, fmt.Sprintf(query, fmt.Sprintf(`after: "%s",`, first.Data.Users.PageInfo.EndCursor)))
+
+		require.Equal(t, 1, len(second.Data.Users.Edges))
+		require.Equal(t, "test5@test.com", second.Data.Users.Edges[0].Node.Email)
+	})
+
+	t.Run("Should return error if the cursor is invalid", func(t *testing.T) {
+		resp := doRequest(t, `query { users(after: "invalid") { totalCount } }`)
+
+		require.Equal(t, 1, len(resp.Errors))
+		require.Equal(t, "BAD_USER_INPUT", resp.Errors[0].Extensions.Code)
+	})
+}
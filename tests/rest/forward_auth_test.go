@@ -0,0 +1,141 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+func generateToken(t *testing.T, claims jsonwebtoken.Claims) string {
+	claims.Issuer = "http://test.io"
+	claims.IssuedAt = time.Now().UTC().Unix()
+	claims.ExpiresAt = time.Now().UTC().Add(15 * time.Minute).Unix()
+
+	token, err := jsonwebtoken.Encode(claims)
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestForwardAuth(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeForwardAuthHandler())
+	defer srv.Close()
+
+	doRequest := func(t *testing.T, headers map[string]string, cookie *http.Cookie) *http.Response {
+		request, err := http.NewRequest(http.MethodGet, srv.URL+"/forward-auth", nil)
+		require.NoError(t, err)
+
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+
+		if cookie != nil {
+			request.AddCookie(cookie)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+
+		response.Body.Close()
+
+		return response
+	}
+
+	member := jsonwebtoken.Claims{
+		Organization:   "5d6e9d1b1c9d440000a1b2c3",
+		StandardClaims: jwt.StandardClaims{Subject: "5d6e9d1b1c9d440000a1b2d2"},
+	}
+
+	t.Run("Should pass the user to the proxy when the Authorization header has a valid token", func(t *testing.T) {
+		response := doRequest(t, map[string]string{"Authorization": "Bearer " + generateToken(t, member)}, nil)
+
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d2", response.Header.Get("X-User-Id"))
+		require.Equal(t, "test6@test.com", response.Header.Get("X-User-Email"))
+		require.Equal(t, "user", response.Header.Get("X-User-Roles"))
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", response.Header.Get("X-User-Organization"))
+	})
+
+	t.Run("Should read the token from the cookie", func(t *testing.T) {
+		response := doRequest(t, nil, &http.Cookie{Name: "access_token", Value: generateToken(t, member)})
+
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2d2", response.Header.Get("X-User-Id"))
+	})
+
+	t.Run("Should refuse the request without a token, hinting the login page", func(t *testing.T) {
+		response := doRequest(t, map[string]string{
+			"X-Forwarded-Proto": "https",
+			"X-Forwarded-Host":  "internal.test.io",
+			"X-Forwarded-Uri":   "/reports?month=8",
+		}, nil)
+
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		require.Empty(t, response.Header.Get("X-User-Id"))
+
+		loginURL, err := url.Parse(response.Header.Get("X-Login-URL"))
+
+		require.NoError(t, err)
+		require.Equal(t, "app.test.io", loginURL.Host)
+		require.Equal(t, "/login", loginURL.Path)
+		require.Equal(t, "https://internal.test.io/reports?month=8", loginURL.Query().Get("redirect"))
+	})
+
+	t.Run("Should refuse the token of a deactivated user", func(t *testing.T) {
+		token := generateToken(t, jsonwebtoken.Claims{
+			StandardClaims: jwt.StandardClaims{Subject: "5d4a22b1106eded67d47c02e"}, // test2@test.com is inactive on seed.js
+		})
+
+		response := doRequest(t, map[string]string{"Authorization": token}, nil)
+
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("Should refuse the restricted tokens", func(t *testing.T) {
+		token := generateToken(t, jsonwebtoken.Claims{
+			Scope:          jsonwebtoken.ScopePasswordChange,
+			StandardClaims: jwt.StandardClaims{Subject: "5d470b3e98b0116d7d8ca48c"},
+		})
+
+		response := doRequest(t, map[string]string{"Authorization": token}, nil)
+
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("Should refuse the token of another issuer", func(t *testing.T) {
+		claims := jsonwebtoken.Claims{
+			StandardClaims: jwt.StandardClaims{
+				Issuer:    "http://another.site.io",
+				Subject:   "5d470b3e98b0116d7d8ca48c",
+				ExpiresAt: time.Now().UTC().Add(time.Minute).Unix(),
+			},
+		}
+
+		token, err := jsonwebtoken.Encode(claims)
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodGet, srv.URL+"/forward-auth", nil)
+		require.NoError(t, err)
+
+		request.Header.Set("Authorization", token)
+
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+
+		defer response.Body.Close()
+
+		var refusal map[string]string
+
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&refusal))
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		require.Equal(t, "Unauthorized", refusal["error"])
+	})
+}
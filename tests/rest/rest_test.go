@@ -0,0 +1,223 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/middlewares"
+	"github.com/LucasFrezarini/go-auth-manager/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID     string   `json:"id"`
+	Email  string   `json:"email"`
+	Roles  []string `json:"roles"`
+	Active bool     `json:"active"`
+}
+
+type authPayload struct {
+	User         user   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// doRequest sends the body as JSON and decodes the response into the result, returning the status code
+func doRequest(t *testing.T, method, url string, body interface{}, headers map[string]string, result interface{}) int {
+	var payload bytes.Buffer
+
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+
+	request, err := http.NewRequest(method, url, &payload)
+	require.NoError(t, err)
+
+	request.Header.Set("Content-Type", "application/json")
+
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	require.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(response.Body).Decode(result))
+
+	return response.StatusCode
+}
+
+func TestREST(t *testing.T) {
+	srv := httptest.NewServer(middlewares.MakeRESTHandlers(middlewares.NewServices()))
+	defer srv.Close()
+
+	var created authPayload
+	var loggedIn authPayload
+
+	t.Run("Should create an user", func(t *testing.T) {
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/users", map[string]interface{}{
+			"email":    "rest@test.com",
+			"password": "Str0ng-Passw0rd",
+			"roles":    []string{"user"},
+		}, nil, &created)
+
+		require.Equal(t, http.StatusCreated, status)
+		require.NotEmpty(t, created.User.ID)
+		require.Equal(t, "rest@test.com", created.User.Email)
+		require.NotEmpty(t, created.Token)
+		require.NotEmpty(t, created.RefreshToken)
+	})
+
+	t.Run("Should refuse an email already in use with a conflict", func(t *testing.T) {
+		var response errorResponse
+
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/users", map[string]interface{}{
+			"email":    "rest@test.com",
+			"password": "Str0ng-Passw0rd",
+			"roles":    []string{"user"},
+		}, nil, &response)
+
+		require.Equal(t, http.StatusConflict, status)
+		require.Equal(t, "CONFLICT", response.Error.Code)
+		require.Equal(t, "User already exists", response.Error.Message)
+	})
+
+	t.Run("Should login with the email and the password", func(t *testing.T) {
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/login", map[string]interface{}{
+			"email":    "rest@test.com",
+			"password": "Str0ng-Passw0rd",
+		}, nil, &loggedIn)
+
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, created.User.ID, loggedIn.User.ID)
+		require.NotEmpty(t, loggedIn.Token)
+	})
+
+	t.Run("Should refuse an invalid password as unauthorized", func(t *testing.T) {
+		var response errorResponse
+
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/login", map[string]interface{}{
+			"email":    "rest@test.com",
+			"password": "wrong",
+		}, nil, &response)
+
+		require.Equal(t, http.StatusUnauthorized, status)
+		require.Equal(t, "UNAUTHORIZED", response.Error.Code)
+	})
+
+	t.Run("Should refresh the token", func(t *testing.T) {
+		var response authPayload
+
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/token/refresh", map[string]interface{}{
+			"refreshToken": loggedIn.RefreshToken,
+		}, nil, &response)
+
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, created.User.ID, response.User.ID)
+		require.NotEmpty(t, response.Token)
+	})
+
+	t.Run("Should validate the token", func(t *testing.T) {
+		var response struct {
+			Valid  bool `json:"valid"`
+			Claims struct {
+				Subject string `json:"sub"`
+			} `json:"claims"`
+			User user `json:"user"`
+		}
+
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/token/validate", map[string]interface{}{
+			"token": loggedIn.Token,
+		}, nil, &response)
+
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, response.Valid)
+		require.Equal(t, created.User.ID, response.Claims.Subject)
+		require.Equal(t, created.User.ID, response.User.ID)
+	})
+
+	t.Run("Should return the authenticated user", func(t *testing.T) {
+		var response user
+
+		status := doRequest(t, http.MethodGet, srv.URL+"/v1/me", nil, map[string]string{
+			"Authorization": loggedIn.Token,
+		}, &response)
+
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, created.User.ID, response.ID)
+		require.Equal(t, "rest@test.com", response.Email)
+	})
+
+	t.Run("Should not return the user without a token", func(t *testing.T) {
+		var response errorResponse
+
+		status := doRequest(t, http.MethodGet, srv.URL+"/v1/me", nil, nil, &response)
+
+		require.Equal(t, http.StatusUnauthorized, status)
+		require.Equal(t, "UNAUTHORIZED", response.Error.Code)
+	})
+
+	t.Run("Should refuse an invalid body", func(t *testing.T) {
+		var response errorResponse
+
+		status := doRequest(t, http.MethodPost, srv.URL+"/v1/login", "not an object", nil, &response)
+
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, "BAD_USER_INPUT", response.Error.Code)
+	})
+
+	t.Run("Should refuse the other methods", func(t *testing.T) {
+		var response errorResponse
+
+		status := doRequest(t, http.MethodGet, srv.URL+"/v1/login", nil, nil, &response)
+
+		require.Equal(t, http.StatusMethodNotAllowed, status)
+		require.Equal(t, "METHOD_NOT_ALLOWED", response.Error.Code)
+	})
+}
+
+func TestRESTRateLimit(t *testing.T) {
+	services := middlewares.NewServices()
+	services.Limiter = ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		"login": {Requests: 1, Period: time.Minute},
+	})
+
+	rest := httptest.NewServer(middlewares.MakeRESTHandlers(services))
+	defer rest.Close()
+
+	graphQL := httptest.NewServer(middlewares.MakeHandlers(services))
+	defer graphQL.Close()
+
+	t.Run("Should count the REST and the GraphQL requests on the same limits", func(t *testing.T) {
+		var payload authPayload
+
+		status := doRequest(t, http.MethodPost, rest.URL+"/v1/login", map[string]interface{}{
+			"email":    "test1@test.com",
+			"password": "12345",
+		}, nil, &payload)
+
+		require.Equal(t, http.StatusOK, status)
+
+		var response map[string]interface{}
+
+		status = doRequest(t, http.MethodPost, graphQL.URL, map[string]interface{}{
+			"query": `mutation { login(data: { email: "test1@test.com", password: "12345" }) { token } }`,
+		}, nil, &response)
+
+		require.Equal(t, http.StatusTooManyRequests, status)
+	})
+}
@@ -0,0 +1,84 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/LucasFrezarini/go-auth-manager/gqlerrors"
+)
+
+// Middleware verifies the token of the Authorization header and puts its claims on the context of the request.
+// Like the AuthHandler of go-auth, the requests without a valid token go on unauthenticated, so the GraphQL
+// directives or RequireAuthentication decide what they can access
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+
+		if authorization == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := v.Verify(r.Context(), authorization)
+
+		if err != nil {
+			if err != ErrInvalidToken && err != ErrRestrictedToken && err != ErrRevokedToken {
+				log.Printf("Error while trying to verify the token: %v", err)
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+	})
+}
+
+// RequireAuthentication refuses with 401 the requests that weren't authenticated by the Middleware, with the same
+// error body as the REST API of go-auth
+func RequireAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := ClaimsFromContext(r.Context()); !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{"code": gqlerrors.Unauthorized, "message": "Unauthorized"},
+			})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsAuthenticated is a gqlgen directive, like the @isAuthenticated of go-auth, that requires the request to be
+// authenticated by the Middleware
+func IsAuthenticated(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	if _, ok := ClaimsFromContext(ctx); !ok {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	return next(ctx)
+}
+
+// InGroup is a gqlgen directive, declared as @inGroup(name: String!), that requires the user to belong to the group
+// inside the organization of the token
+func InGroup(ctx context.Context, obj interface{}, next graphql.Resolver, name string) (interface{}, error) {
+	claims, ok := ClaimsFromContext(ctx)
+
+	if !ok {
+		return nil, gqlerrors.CreateAuthorizationError()
+	}
+
+	for _, group := range claims.Groups {
+		if group == name {
+			return next(ctx)
+		}
+	}
+
+	return nil, gqlerrors.CreateForbiddenError()
+}
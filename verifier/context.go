@@ -0,0 +1,38 @@
+package verifier
+
+import (
+	"context"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+)
+
+type contextKey int
+
+const claimsKey contextKey = iota
+
+// NewContext returns a copy of the context carrying the claims of the verified token
+func NewContext(ctx context.Context, claims jsonwebtoken.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext returns the claims of the verified token. The second value is false when the request wasn't authenticated
+func ClaimsFromContext(ctx context.Context) (jsonwebtoken.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(jsonwebtoken.Claims)
+
+	return claims, ok
+}
+
+// UserIDFromContext returns the id of the authenticated user
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+
+	return claims.Subject, ok
+}
+
+// OrganizationIDFromContext returns the id of the organization the token was issued for. The second value is
+// false when the request wasn't authenticated, or the token isn't scoped to any organization
+func OrganizationIDFromContext(ctx context.Context) (string, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+
+	return claims.Organization, ok && claims.Organization != ""
+}
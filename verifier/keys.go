@@ -0,0 +1,32 @@
+package verifier
+
+import (
+	"fmt"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// KeySource returns the key that checks the signature of a token
+type KeySource interface {
+	// Key returns the key for the token, refusing the keys that don't match its signing method
+	Key(token *jwt.Token) (interface{}, error)
+}
+
+type hmacKey struct {
+	secret []byte
+}
+
+// HMACKey returns a KeySource with the secret shared with go-auth, which signs the tokens with HS256. It's the only
+// key source supported, since go-auth doesn't sign the tokens with asymmetric keys nor publishes a JWKS
+func HMACKey(secret []byte) KeySource {
+	return &hmacKey{secret: secret}
+}
+
+// Key returns the secret, if the token is signed with HMAC, so the tokens using other algorithms are refused
+func (k *hmacKey) Key(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return k.secret, nil
+}
@@ -0,0 +1,146 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCachedTokens bounds the answers kept by an OnlineRevoker. The expired ones are removed when it's reached
+const maxCachedTokens = 10000
+
+// Revoker checks online if a token is still valid. The signature of a token stays valid until it expires, even
+// after the user is deactivated or leaves the organization, so the services that can't wait for the expiration ask go-auth
+type Revoker interface {
+	Revoked(ctx context.Context, token string) (bool, error)
+}
+
+// OnlineRevoker asks go-auth through its REST endpoint /v1/token/validate, reusing each answer for a while
+type OnlineRevoker struct {
+	url    string
+	client *http.Client
+
+	// CacheTTL is how long the answer about a token is reused, so the verifications don't cost a request each, nor
+	// hit the rate limits of go-auth. A revocation takes up to it to be noticed. Zero turns the cache off
+	CacheTTL time.Duration
+
+	mutex   sync.Mutex
+	answers map[[sha256.Size]byte]answer
+}
+
+type answer struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// NewOnlineRevoker creates an OnlineRevoker for the go-auth server at the base url, like https://auth.example.com
+func NewOnlineRevoker(baseURL string) *OnlineRevoker {
+	return &OnlineRevoker{
+		url:      strings.TrimSuffix(baseURL, "/") + "/v1/token/validate",
+		client:   &http.Client{Timeout: 5 * time.Second},
+		CacheTTL: 30 * time.Second,
+		answers:  map[[sha256.Size]byte]answer{},
+	}
+}
+
+// Revoked returns true when go-auth doesn't consider the token valid anymore
+func (r *OnlineRevoker) Revoked(ctx context.Context, token string) (bool, error) {
+	key := sha256.Sum256([]byte(token))
+
+	if cached, ok := r.cached(key); ok {
+		return cached, nil
+	}
+
+	revoked, err := r.ask(ctx, token)
+
+	if err != nil {
+		return false, err
+	}
+
+	r.store(key, revoked)
+
+	return revoked, nil
+}
+
+func (r *OnlineRevoker) cached(key [sha256.Size]byte) (bool, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cached, ok := r.answers[key]
+
+	if !ok || !time.Now().Before(cached.expiresAt) {
+		return false, false
+	}
+
+	return cached.revoked, true
+}
+
+func (r *OnlineRevoker) store(key [sha256.Size]byte, revoked bool) {
+	if r.CacheTTL <= 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+
+	if len(r.answers) >= maxCachedTokens {
+		for cachedKey, cached := range r.answers {
+			if !now.Before(cached.expiresAt) {
+				delete(r.answers, cachedKey)
+			}
+		}
+	}
+
+	if len(r.answers) >= maxCachedTokens {
+		r.answers = map[[sha256.Size]byte]answer{}
+	}
+
+	r.answers[key] = answer{revoked: revoked, expiresAt: now.Add(r.CacheTTL)}
+}
+
+// ask requests go-auth to validate the token
+func (r *OnlineRevoker) ask(ctx context.Context, token string) (bool, error) {
+	body, err := json.Marshal(map[string]string{"token": token})
+
+	if err != nil {
+		return false, err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+
+	if err != nil {
+		return false, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := r.client.Do(request.WithContext(ctx))
+
+	if err != nil {
+		return false, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Unexpected status %d", response.StatusCode)
+	}
+
+	var result struct {
+		Valid bool `json:"valid"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return false, err
+	}
+
+	return !result.Valid, nil
+}
@@ -0,0 +1,88 @@
+// Package verifier checks the tokens issued by go-auth on the other services. The tokens are verified offline,
+// against the secret shared with go-auth, and an optional Revoker checks online that they're still valid.
+// It doesn't depend on the database, so the services can import it instead of copying the AuthHandler
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	// ErrMissingToken is returned when there isn't a token to verify
+	ErrMissingToken = errors.New("The token is missing")
+
+	// ErrInvalidToken is returned when the signature, the expiration or the issuer of the token aren't valid
+	ErrInvalidToken = errors.New("The token is invalid")
+
+	// ErrRestrictedToken is returned for the tokens that can only be used on go-auth, like the password change ones
+	ErrRestrictedToken = errors.New("The token is restricted")
+
+	// ErrRevokedToken is returned when the Revoker tells the token isn't valid anymore
+	ErrRevokedToken = errors.New("The token was revoked")
+)
+
+// Config defines what is checked besides the signature and the expiration of the tokens
+type Config struct {
+	// Issuer, when set, must match the issuer of the tokens. It's the SERVER_HOST of go-auth
+	Issuer string
+
+	// Revoker, when set, is asked on every verification if the token is still valid
+	Revoker Revoker
+}
+
+// Verifier checks the tokens. It's safe for concurrent use
+type Verifier struct {
+	keys   KeySource
+	config Config
+	now    func() time.Time
+}
+
+// New creates a Verifier that checks the signatures of the tokens with the keys of the source
+func New(keys KeySource, config Config) *Verifier {
+	return &Verifier{keys: keys, config: config, now: time.Now}
+}
+
+// Verify checks the token, which may have the Bearer prefix, and returns its claims
+func (v *Verifier) Verify(ctx context.Context, token string) (jsonwebtoken.Claims, error) {
+	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+
+	if token == "" {
+		return jsonwebtoken.Claims{}, ErrMissingToken
+	}
+
+	claims := jsonwebtoken.Claims{}
+	parsed, err := jwt.ParseWithClaims(token, &claims, v.keys.Key)
+
+	if err != nil || !parsed.Valid || !claims.VerifyExpiresAt(v.now().UTC().Unix(), true) {
+		return jsonwebtoken.Claims{}, ErrInvalidToken
+	}
+
+	if v.config.Issuer != "" && claims.Issuer != v.config.Issuer {
+		return jsonwebtoken.Claims{}, ErrInvalidToken
+	}
+
+	if claims.Restricted() {
+		return jsonwebtoken.Claims{}, ErrRestrictedToken
+	}
+
+	if v.config.Revoker != nil {
+		revoked, err := v.config.Revoker.Revoked(ctx, token)
+
+		if err != nil {
+			return jsonwebtoken.Claims{}, fmt.Errorf("Error while trying to check if the token was revoked: %v", err)
+		}
+
+		if revoked {
+			return jsonwebtoken.Claims{}, ErrRevokedToken
+		}
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,197 @@
+package verifier_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/jsonwebtoken"
+	"github.com/LucasFrezarini/go-auth-manager/verifier"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+var secret = []byte("verifier-test-secret")
+
+func claims(subject string) jsonwebtoken.Claims {
+	return jsonwebtoken.Claims{
+		Organization: "5d6e9d1b1c9d440000a1b2c3",
+		Groups:       []string{"engineering"},
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    "http://test.io",
+			Subject:   subject,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, c jsonwebtoken.Claims) string {
+	token := jwt.NewWithClaims(method, c)
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("Should verify a token signed with the shared secret", func(t *testing.T) {
+		v := verifier.New(verifier.HMACKey(secret), verifier.Config{Issuer: "http://test.io"})
+
+		verified, err := v.Verify(context.Background(), "Bearer "+sign(t, jwt.SigningMethodHS256, secret, claims("user-1")))
+
+		require.NoError(t, err)
+		require.Equal(t, "user-1", verified.Subject)
+		require.Equal(t, "5d6e9d1b1c9d440000a1b2c3", verified.Organization)
+	})
+
+	t.Run("Should refuse the tokens with another signature, issuer or expired", func(t *testing.T) {
+		v := verifier.New(verifier.HMACKey(secret), verifier.Config{Issuer: "http://test.io"})
+
+		expired := claims("user-1")
+		expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+
+		withoutExpiration := claims("user-1")
+		withoutExpiration.ExpiresAt = 0
+
+		otherIssuer := claims("user-1")
+		otherIssuer.Issuer = "http://other.io"
+
+		for _, token := range []string{
+			sign(t, jwt.SigningMethodHS256, []byte("another-secret"), claims("user-1")),
+			sign(t, jwt.SigningMethodHS256, secret, expired),
+			sign(t, jwt.SigningMethodHS256, secret, withoutExpiration),
+			sign(t, jwt.SigningMethodHS256, secret, otherIssuer),
+			"invalid",
+		} {
+			_, err := v.Verify(context.Background(), token)
+			require.Equal(t, verifier.ErrInvalidToken, err)
+		}
+
+		_, err := v.Verify(context.Background(), "")
+		require.Equal(t, verifier.ErrMissingToken, err)
+	})
+
+	t.Run("Should refuse the restricted tokens", func(t *testing.T) {
+		v := verifier.New(verifier.HMACKey(secret), verifier.Config{})
+		restricted := claims("user-1")
+		restricted.Scope = jsonwebtoken.ScopePasswordChange
+
+		_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodHS256, secret, restricted))
+
+		require.Equal(t, verifier.ErrRestrictedToken, err)
+	})
+
+	t.Run("Should refuse the tokens that aren't signed with HMAC", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		v := verifier.New(verifier.HMACKey(secret), verifier.Config{})
+
+		_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, key, claims("user-1")))
+		require.Equal(t, verifier.ErrInvalidToken, err)
+	})
+}
+
+func TestOnlineRevoker(t *testing.T) {
+	valid := true
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/token/validate", r.URL.Path)
+		atomic.AddInt32(&requests, 1)
+		json.NewEncoder(w).Encode(map[string]bool{"valid": valid})
+	}))
+	defer server.Close()
+
+	revoker := verifier.NewOnlineRevoker(server.URL)
+	revoker.CacheTTL = 0
+
+	v := verifier.New(verifier.HMACKey(secret), verifier.Config{Revoker: revoker})
+	token := sign(t, jwt.SigningMethodHS256, secret, claims("user-1"))
+
+	t.Run("Should accept a token that go-auth still considers valid", func(t *testing.T) {
+		_, err := v.Verify(context.Background(), token)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Should refuse a token that go-auth doesn't consider valid anymore", func(t *testing.T) {
+		valid = false
+
+		_, err := v.Verify(context.Background(), token)
+
+		require.Equal(t, verifier.ErrRevokedToken, err)
+	})
+
+	t.Run("Should reuse the answer of go-auth until the cache expires", func(t *testing.T) {
+		valid = true
+		atomic.StoreInt32(&requests, 0)
+
+		cached := verifier.New(verifier.HMACKey(secret), verifier.Config{Revoker: verifier.NewOnlineRevoker(server.URL)})
+		other := sign(t, jwt.SigningMethodHS256, secret, claims("user-2"))
+
+		for i := 0; i < 3; i++ {
+			_, err := cached.Verify(context.Background(), other)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+}
+
+func TestAdapters(t *testing.T) {
+	v := verifier.New(verifier.HMACKey(secret), verifier.Config{})
+	handler := v.Middleware(verifier.RequireAuthentication(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := verifier.UserIDFromContext(r.Context())
+		organizationID, _ := verifier.OrganizationIDFromContext(r.Context())
+
+		w.Write([]byte(userID + "@" + organizationID))
+	})))
+
+	t.Run("Should put the claims of the token on the context of the request", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", sign(t, jwt.SigningMethodHS256, secret, claims("user-1")))
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "user-1@5d6e9d1b1c9d440000a1b2c3", recorder.Body.String())
+	})
+
+	t.Run("Should refuse the requests without a valid token", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "invalid")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Contains(t, recorder.Body.String(), "UNAUTHORIZED")
+	})
+
+	t.Run("Should guard the GraphQL fields with the directives", func(t *testing.T) {
+		next := func(ctx context.Context) (interface{}, error) { return "resolved", nil }
+		ctx := verifier.NewContext(context.Background(), claims("user-1"))
+
+		_, err := verifier.IsAuthenticated(context.Background(), nil, next)
+		require.Error(t, err)
+
+		result, err := verifier.IsAuthenticated(ctx, nil, next)
+		require.NoError(t, err)
+		require.Equal(t, "resolved", result)
+
+		_, err = verifier.InGroup(ctx, nil, next, "engineering")
+		require.NoError(t, err)
+
+		_, err = verifier.InGroup(ctx, nil, next, "finance")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/env"
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/worker"
+)
+
+// BatchSize is the maximum number of deliveries sent on each run
+const BatchSize = 100
+
+// Config defines how the deliveries are sent and retried
+type Config struct {
+	// MaxAttempts is how many times a delivery is sent before giving up on it
+	MaxAttempts int
+
+	// RetryDelay is how long the first retry waits. It doubles on each new failure
+	RetryDelay time.Duration
+
+	// MaxRetryDelay is the longest a retry can wait
+	MaxRetryDelay time.Duration
+
+	// Timeout is how long the receiver has to answer
+	Timeout time.Duration
+
+	// AllowPrivateNetworks lets the subscriptions point to loopback, link-local and private addresses, like a
+	// receiver running on the same host during the development
+	AllowPrivateNetworks bool
+}
+
+// ConfigFromEnv returns the config defined by the WEBHOOK_* environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		MaxAttempts:   env.Config.WebhookMaxAttempts,
+		RetryDelay:    env.Config.WebhookRetryDelay,
+		MaxRetryDelay: env.Config.WebhookMaxRetryDelay,
+		Timeout:       env.Config.WebhookTimeout,
+
+		AllowPrivateNetworks: env.Config.WebhookAllowPrivateNetworks,
+	}
+}
+
+// Dispatcher sends the pending deliveries of the outbox to their subscriptions
+type Dispatcher struct {
+	store  Store
+	config Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// New creates a Dispatcher that sends the deliveries of the store
+func New(store Store, config Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		config: config,
+		client: newClient(config),
+		now:    time.Now,
+	}
+}
+
+// Run sends a batch of the due deliveries and returns how many were accepted by the receivers. Each delivery is
+// claimed before being sent, so a delivery interrupted by a crash is sent again once its claim expires. The
+// receivers must therefore be ready to get the same event more than once
+func (d *Dispatcher) Run() (int, error) {
+	delivered := 0
+
+	for i := 0; i < BatchSize; i++ {
+		now := d.now()
+		delivery, err := d.store.Claim(now, now.Add(2*d.config.Timeout))
+
+		if err != nil {
+			return delivered, err
+		}
+
+		if delivery == nil {
+			break
+		}
+
+		if d.deliver(delivery, now) {
+			delivered++
+		}
+
+		if err := d.store.Save(delivery); err != nil {
+			log.Printf("Error while trying to save the webhook delivery %s: %v", delivery.ID.Hex(), err)
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver sends the delivery and updates it with the outcome, returning true if it was accepted
+func (d *Dispatcher) deliver(delivery *models.WebhookDelivery, now time.Time) bool {
+	delivery.UpdatedAt = now
+
+	subscription, err := d.store.Subscription(delivery.SubscriptionID)
+
+	if err == models.ErrWebhookSubscriptionNotFound || (err == nil && !subscription.Active) {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = "The subscription was removed or disabled"
+		return false
+	}
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = now
+
+	if err == nil {
+		delivery.ResponseStatus, err = d.send(subscription, delivery, now)
+	}
+
+	if err == nil {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = now
+		delivery.LastError = ""
+		return true
+	}
+
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		return false
+	}
+
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(d.Backoff(delivery.Attempts))
+
+	return false
+}
+
+// send posts the signed payload to the subscription, returning the status code of the response. Any status
+// outside of the 2xx range is an error
+func (d *Dispatcher) send(subscription *models.WebhookSubscription, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := now.Unix()
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-auth-manager-webhooks")
+	req.Header.Set(HeaderDelivery, delivery.ID.Hex())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// Draining the body allows the connection to be reused, but a huge response isn't read to the end
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64*1024))
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("The receiver answered with the status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Backoff returns how long the delivery waits after the failed attempt: the retry delay, doubled after
+// each attempt, up to the max retry delay
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	return worker.Backoff(d.config.RetryDelay, d.config.MaxRetryDelay, attempts)
+}
+
+// Start sends the due deliveries on every interval, until the returned function is called
+func (d *Dispatcher) Start(interval time.Duration) (stop func()) {
+	return worker.Start(interval, "send the webhook deliveries", d.Run)
+}
@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryStore keeps the subscriptions and the deliveries in memory. It's used on the tests
+type MemoryStore struct {
+	mutex         sync.Mutex
+	subscriptions map[primitive.ObjectID]models.WebhookSubscription
+	deliveries    []models.WebhookDelivery
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{subscriptions: map[primitive.ObjectID]models.WebhookSubscription{}}
+}
+
+// AddSubscription stores the subscription, assigning an id to it when it doesn't have one, and returns the stored subscription
+func (s *MemoryStore) AddSubscription(subscription models.WebhookSubscription) models.WebhookSubscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if subscription.ID.IsZero() {
+		subscription.ID = primitive.NewObjectID()
+	}
+
+	s.subscriptions[subscription.ID] = subscription
+
+	return subscription
+}
+
+// RemoveSubscription removes the subscription with the id
+func (s *MemoryStore) RemoveSubscription(id primitive.ObjectID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.subscriptions, id)
+}
+
+// Deliveries returns a copy of all the deliveries, in the order they were enqueued
+func (s *MemoryStore) Deliveries() []models.WebhookDelivery {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]models.WebhookDelivery{}, s.deliveries...)
+}
+
+// Subscriptions returns the active subscriptions to the event type which receive the events of the organization
+func (s *MemoryStore) Subscriptions(eventType string, organizationID primitive.ObjectID) ([]*models.WebhookSubscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var subscriptions []*models.WebhookSubscription
+
+	for _, subscription := range s.subscriptions {
+		if !subscription.Active || !contains(subscription.EventTypes, eventType) {
+			continue
+		}
+
+		if !subscription.OrganizationID.IsZero() && subscription.OrganizationID != organizationID {
+			continue
+		}
+
+		subscription := subscription
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID.Hex() < subscriptions[j].ID.Hex()
+	})
+
+	return subscriptions, nil
+}
+
+// Subscription returns the subscription with the id, or models.ErrWebhookSubscriptionNotFound if it was removed
+func (s *MemoryStore) Subscription(id primitive.ObjectID) (*models.WebhookSubscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	subscription, ok := s.subscriptions[id]
+
+	if !ok {
+		return nil, models.ErrWebhookSubscriptionNotFound
+	}
+
+	return &subscription, nil
+}
+
+// Enqueue adds the deliveries to the outbox
+func (s *MemoryStore) Enqueue(deliveries []models.WebhookDelivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, delivery := range deliveries {
+		delivery.ID = primitive.NewObjectID()
+		s.deliveries = append(s.deliveries, delivery)
+	}
+
+	return nil
+}
+
+// Claim returns the oldest pending delivery due before now, pushing its next attempt to leaseUntil
+func (s *MemoryStore) Claim(now, leaseUntil time.Time) (*models.WebhookDelivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, delivery := range s.deliveries {
+		if delivery.Status == models.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			s.deliveries[i].NextAttemptAt = leaseUntil
+			claimed := s.deliveries[i]
+
+			return &claimed, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Save stores the outcome of an attempt to send the delivery
+func (s *MemoryStore) Save(delivery *models.WebhookDelivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == delivery.ID {
+			s.deliveries[i] = *delivery
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errRestrictedAddress is returned when a subscription points to an address of the internal network
+var errRestrictedAddress = errors.New("The URL can't point to a loopback, link-local or private address")
+
+// restrictedNetworks are the ranges of the internal networks, which the receivers can't be on, so a subscription
+// can't be used to reach the services behind the firewall, like the metadata endpoint of the cloud providers
+var restrictedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
+
+// restrictedIP returns true if the ip belongs to an internal network, or isn't an unicast address
+func restrictedIP(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsMulticast() {
+		return true
+	}
+
+	for _, network := range restrictedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateHost refuses the hosts that are, or resolve to, an internal address. The hosts that can't be resolved
+// are accepted, since their addresses are checked again when the deliveries are sent
+func validateHost(host string) error {
+	ips := []net.IP{net.ParseIP(host)}
+
+	if ips[0] == nil {
+		resolved, err := net.LookupIP(host)
+
+		if err != nil {
+			return nil
+		}
+
+		ips = resolved
+	}
+
+	for _, ip := range ips {
+		if restrictedIP(ip) {
+			return errRestrictedAddress
+		}
+	}
+
+	return nil
+}
+
+// newClient returns the client that sends the deliveries. Unless the private networks are allowed, it refuses to
+// connect to an internal address, which the host of a subscription may resolve to after it was validated
+func newClient(config Config) *http.Client {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+
+	if !config.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || restrictedIP(ip) {
+				return fmt.Errorf("%v: %s", errRestrictedAddress, host)
+			}
+
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
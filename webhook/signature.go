@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Headers sent along with the payloads
+const (
+	// HeaderDelivery is the id of the delivery, which is the same on all its attempts
+	HeaderDelivery = "X-Webhook-Delivery"
+
+	// HeaderEvent is the type of the event
+	HeaderEvent = "X-Webhook-Event"
+
+	// HeaderTimestamp is when the attempt was sent, in unix seconds. It's part of the signature, so the
+	// receivers can refuse the old payloads being replayed
+	HeaderTimestamp = "X-Webhook-Timestamp"
+
+	// HeaderSignature is the signature of the payload, as sha256=<hex encoded HMAC>
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the signature of the body sent at the timestamp: the HMAC-SHA256 of "<timestamp>.<body>"
+// using the secret of the subscription as the key
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature received along with the body and the timestamp, as a receiver would do
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,168 @@
+// Package webhook delivers the events of the users to the URLs subscribed to them. The events are written to
+// a durable outbox, one delivery for each subscription, and a dispatcher sends them as HMAC signed JSON payloads,
+// retrying the failures with an exponential backoff
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MinSecretLength is the minimum number of characters of the secrets used to sign the payloads
+const MinSecretLength = 16
+
+// EventTypes are the audit actions that can be subscribed to
+var EventTypes = []string{
+	models.AuditUserCreated,
+	models.AuditUserSignedUp,
+	models.AuditUserUpdated,
+	models.AuditUserActivated,
+	models.AuditUserDeactivated,
+	models.AuditUserDeleted,
+	models.AuditPasswordReset,
+	models.AuditPasswordChanged,
+	models.AuditRolesChanged,
+	models.AuditOrganizationRoleChanged,
+	models.AuditSessionsRevoked,
+	models.AuditUserUnlocked,
+	models.AuditLoginSucceeded,
+	models.AuditLoginFailed,
+	models.AuditDeletionScheduled,
+	models.AuditDeletionCancelled,
+	models.AuditUserPurged,
+}
+
+// Store keeps the subscriptions and the outbox of the deliveries. Implementations must be safe for concurrent use
+type Store interface {
+	// Subscriptions returns the active subscriptions to the event type which receive the events of the organization
+	Subscriptions(eventType string, organizationID primitive.ObjectID) ([]*models.WebhookSubscription, error)
+
+	// Subscription returns the subscription with the id, or models.ErrWebhookSubscriptionNotFound if it was removed
+	Subscription(id primitive.ObjectID) (*models.WebhookSubscription, error)
+
+	// Enqueue adds the deliveries to the outbox
+	Enqueue(deliveries []models.WebhookDelivery) error
+
+	// Claim returns the oldest pending delivery due before now, pushing its next attempt to leaseUntil so
+	// the other dispatchers don't send it at the same time. It returns nil when there isn't any due delivery
+	Claim(now, leaseUntil time.Time) (*models.WebhookDelivery, error)
+
+	// Save stores the outcome of an attempt to send the delivery
+	Save(delivery *models.WebhookDelivery) error
+}
+
+// Payload is the JSON body sent to the subscriptions
+type Payload struct {
+	// ID is the id of the audit event, which the receivers can use to ignore the events delivered twice
+	ID             string                 `json:"id"`
+	Type           string                 `json:"type"`
+	OrganizationID string                 `json:"organization_id,omitempty"`
+	ActorID        string                 `json:"actor_id,omitempty"`
+	UserID         string                 `json:"user_id,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// NewPayload returns the payload of the event. The ip and the user agent of the request aren't sent
+func NewPayload(event models.AuditEvent) Payload {
+	return Payload{
+		ID:             hexOrEmpty(event.ID),
+		Type:           event.Action,
+		OrganizationID: hexOrEmpty(event.OrganizationID),
+		ActorID:        hexOrEmpty(event.ActorID),
+		UserID:         hexOrEmpty(event.TargetID),
+		Metadata:       event.Metadata,
+		CreatedAt:      event.CreatedAt,
+	}
+}
+
+func hexOrEmpty(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+
+	return id.Hex()
+}
+
+// Publish adds a delivery of the event to the outbox for each subscription interested in it.
+// The event must already be stored, since its id is sent to the receivers
+func Publish(store Store, event models.AuditEvent) error {
+	subscriptions, err := store.Subscriptions(event.Action, event.OrganizationID)
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to fetch the webhook subscriptions: %v", err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(NewPayload(event))
+
+	if err != nil {
+		return fmt.Errorf("Error while trying to encode the webhook payload: %v", err)
+	}
+
+	now := time.Now()
+	deliveries := make([]models.WebhookDelivery, len(subscriptions))
+
+	for i, subscription := range subscriptions {
+		deliveries[i] = models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			OrganizationID: subscription.OrganizationID,
+			EventID:        event.ID,
+			EventType:      event.Action,
+			UserID:         event.TargetID,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+	}
+
+	return store.Enqueue(deliveries)
+}
+
+// Validate checks the URL, the secret and the event types of a subscription. The URL can't point to an internal
+// address unless the config allows the private networks
+func Validate(subscription models.WebhookSubscription, config Config) error {
+	address, err := url.Parse(subscription.URL)
+
+	if err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		return errors.New("The URL must be an absolute http or https URL")
+	}
+
+	if !config.AllowPrivateNetworks {
+		if err := validateHost(address.Hostname()); err != nil {
+			return err
+		}
+	}
+
+	if len(subscription.Secret) < MinSecretLength {
+		return fmt.Errorf("The secret must have at least %d characters", MinSecretLength)
+	}
+
+	if len(subscription.EventTypes) == 0 {
+		return errors.New("The subscription must have at least one event type")
+	}
+
+	for _, eventType := range subscription.EventTypes {
+		if !IsEventType(eventType) {
+			return fmt.Errorf("Unknown event type %s", eventType)
+		}
+	}
+
+	return nil
+}
+
+// IsEventType returns true if the event type can be subscribed to
+func IsEventType(eventType string) bool {
+	return contains(EventTypes, eventType)
+}
@@ -0,0 +1,324 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/models"
+	"github.com/LucasFrezarini/go-auth-manager/webhook"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const secret = "a-very-secret-webhook-key"
+
+type request struct {
+	Header http.Header
+	Body   []byte
+}
+
+// receiver is an httptest server answering with the status codes of the list, one per request,
+// and then with 200, recording every request it gets
+type receiver struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func newReceiver(statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.requests = append(r.requests, request{Header: req.Header, Body: body})
+		status := http.StatusOK
+
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+
+		w.WriteHeader(status)
+	}))
+
+	return r
+}
+
+func (r *receiver) received() []request {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]request{}, r.requests...)
+}
+
+func config() webhook.Config {
+	// The receivers of the tests listen on the loopback
+	return webhook.Config{MaxAttempts: 3, RetryDelay: time.Millisecond, MaxRetryDelay: 4 * time.Millisecond, Timeout: time.Second, AllowPrivateNetworks: true}
+}
+
+func event(action string, organizationID primitive.ObjectID) models.AuditEvent {
+	return models.AuditEvent{
+		ID:             primitive.NewObjectID(),
+		Action:         action,
+		TargetID:       primitive.NewObjectID(),
+		OrganizationID: organizationID,
+		IP:             "203.0.113.7",
+		Metadata:       map[string]interface{}{"email": "test@test.com"},
+		CreatedAt:      time.Now(),
+	}
+}
+
+func TestPublish(t *testing.T) {
+	t.Run("Should enqueue a delivery for each active subscription to the event", func(t *testing.T) {
+		store := webhook.NewMemoryStore()
+		subscribed := store.AddSubscription(models.WebhookSubscription{URL: "http://a.test", EventTypes: []string{models.AuditUserCreated}, Active: true})
+		store.AddSubscription(models.WebhookSubscription{URL: "http://b.test", EventTypes: []string{models.AuditUserDeleted}, Active: true})
+		store.AddSubscription(models.WebhookSubscription{URL: "http://c.test", EventTypes: []string{models.AuditUserCreated}})
+
+		err := webhook.Publish(store, event(models.AuditUserCreated, primitive.NilObjectID))
+
+		require.NoError(t, err)
+
+		deliveries := store.Deliveries()
+		require.Equal(t, 1, len(deliveries))
+		require.Equal(t, subscribed.ID, deliveries[0].SubscriptionID)
+		require.NotEqual(t, primitive.NilObjectID, deliveries[0].UserID)
+		require.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+	})
+
+	t.Run("Should deliver the events of an organization only to its subscriptions and to the global ones", func(t *testing.T) {
+		organizationID := primitive.NewObjectID()
+		store := webhook.NewMemoryStore()
+		global := store.AddSubscription(models.WebhookSubscription{URL: "http://a.test", EventTypes: []string{models.AuditUserCreated}, Active: true})
+		own := store.AddSubscription(models.WebhookSubscription{OrganizationID: organizationID, URL: "http://b.test", EventTypes: []string{models.AuditUserCreated}, Active: true})
+		store.AddSubscription(models.WebhookSubscription{OrganizationID: primitive.NewObjectID(), URL: "http://c.test", EventTypes: []string{models.AuditUserCreated}, Active: true})
+
+		err := webhook.Publish(store, event(models.AuditUserCreated, organizationID))
+
+		require.NoError(t, err)
+
+		var subscriptionIDs []primitive.ObjectID
+		for _, delivery := range store.Deliveries() {
+			subscriptionIDs = append(subscriptionIDs, delivery.SubscriptionID)
+		}
+
+		require.ElementsMatch(t, []primitive.ObjectID{global.ID, own.ID}, subscriptionIDs)
+	})
+
+	t.Run("Should not send the ip and the user agent of the request", func(t *testing.T) {
+		store := webhook.NewMemoryStore()
+		store.AddSubscription(models.WebhookSubscription{URL: "http://a.test", EventTypes: []string{models.AuditUserCreated}, Active: true})
+		e := event(models.AuditUserCreated, primitive.NilObjectID)
+
+		require.NoError(t, webhook.Publish(store, e))
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(store.Deliveries()[0].Payload), &payload))
+		require.Equal(t, e.ID.Hex(), payload["id"])
+		require.Equal(t, e.TargetID.Hex(), payload["user_id"])
+		require.NotContains(t, payload, "ip")
+		require.NotContains(t, store.Deliveries()[0].Payload, e.IP)
+	})
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Should send the signed payload to the subscription", func(t *testing.T) {
+		receiver := newReceiver()
+		defer receiver.Close()
+
+		store := webhook.NewMemoryStore()
+		store.AddSubscription(models.WebhookSubscription{URL: receiver.URL, Secret: secret, EventTypes: []string{models.AuditUserCreated}, Active: true})
+		require.NoError(t, webhook.Publish(store, event(models.AuditUserCreated, primitive.NilObjectID)))
+
+		delivered, err := webhook.New(store, config()).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, delivered)
+
+		requests := receiver.received()
+		require.Equal(t, 1, len(requests))
+
+		delivery := store.Deliveries()[0]
+		timestamp, err := strconv.ParseInt(requests[0].Header.Get(webhook.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		require.True(t, webhook.Verify(secret, timestamp, requests[0].Body, requests[0].Header.Get(webhook.HeaderSignature)))
+		require.False(t, webhook.Verify("another-secret-webhook-key", timestamp, requests[0].Body, requests[0].Header.Get(webhook.HeaderSignature)))
+		require.Equal(t, delivery.Payload, string(requests[0].Body))
+		require.Equal(t, delivery.ID.Hex(), requests[0].Header.Get(webhook.HeaderDelivery))
+		require.Equal(t, models.AuditUserCreated, requests[0].Header.Get(webhook.HeaderEvent))
+
+		require.Equal(t, models.WebhookDeliveryDelivered, delivery.Status)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusOK, delivery.ResponseStatus)
+		require.False(t, delivery.DeliveredAt.IsZero())
+	})
+
+	t.Run("Should retry the failed deliveries with the backoff", func(t *testing.T) {
+		receiver := newReceiver(http.StatusInternalServerError)
+		defer receiver.Close()
+
+		store := webhook.NewMemoryStore()
+		store.AddSubscription(models.WebhookSubscription{URL: receiver.URL, Secret: secret, EventTypes: []string{models.AuditUserCreated}, Active: true})
+		require.NoError(t, webhook.Publish(store, event(models.AuditUserCreated, primitive.NilObjectID)))
+
+		dispatcher := webhook.New(store, config())
+		delivered, err := dispatcher.Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, delivered)
+
+		delivery := store.Deliveries()[0]
+		require.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		require.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+		require.NotEmpty(t, delivery.LastError)
+		require.True(t, delivery.NextAttemptAt.After(delivery.LastAttemptAt))
+
+		time.Sleep(5 * time.Millisecond)
+		delivered, err = dispatcher.Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 1, delivered)
+		require.Equal(t, 2, len(receiver.received()))
+		require.Equal(t, models.WebhookDeliveryDelivered, store.Deliveries()[0].Status)
+		require.Equal(t, 2, store.Deliveries()[0].Attempts)
+		require.Empty(t, store.Deliveries()[0].LastError)
+	})
+
+	t.Run("Should give up on the delivery after the max attempts", func(t *testing.T) {
+		receiver := newReceiver(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+		defer receiver.Close()
+
+		store := webhook.NewMemoryStore()
+		store.AddSubscription(models.WebhookSubscription{URL: receiver.URL, Secret: secret, EventTypes: []string{models.AuditUserCreated}, Active: true})
+		require.NoError(t, webhook.Publish(store, event(models.AuditUserCreated, primitive.NilObjectID)))
+
+		dispatcher := webhook.New(store, config())
+
+		for i := 0; i < 5; i++ {
+			_, err := dispatcher.Run()
+			require.NoError(t, err)
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		require.Equal(t, 3, len(receiver.received()))
+		require.Equal(t, models.WebhookDeliveryFailed, store.Deliveries()[0].Status)
+		require.Equal(t, 3, store.Deliveries()[0].Attempts)
+	})
+
+	t.Run("Should not send the deliveries of a removed subscription", func(t *testing.T) {
+		receiver := newReceiver()
+		defer receiver.Close()
+
+		store := webhook.NewMemoryStore()
+		subscription := store.AddSubscription(models.WebhookSubscription{URL: receiver.URL, Secret: secret, EventTypes: []string{models.AuditUserCreated}, Active: true})
+		require.NoError(t, webhook.Publish(store, event(models.AuditUserCreated, primitive.NilObjectID)))
+		store.RemoveSubscription(subscription.ID)
+
+		delivered, err := webhook.New(store, config()).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, delivered)
+		require.Empty(t, receiver.received())
+		require.Equal(t, models.WebhookDeliveryFailed, store.Deliveries()[0].Status)
+	})
+
+	t.Run("Should not connect to an internal address when the private networks aren't allowed", func(t *testing.T) {
+		receiver := newReceiver()
+		defer receiver.Close()
+
+		store := webhook.NewMemoryStore()
+		store.AddSubscription(models.WebhookSubscription{URL: receiver.URL, Secret: secret, EventTypes: []string{models.AuditUserCreated}, Active: true})
+		require.NoError(t, webhook.Publish(store, event(models.AuditUserCreated, primitive.NilObjectID)))
+
+		restricted := config()
+		restricted.AllowPrivateNetworks = false
+
+		delivered, err := webhook.New(store, restricted).Run()
+
+		require.NoError(t, err)
+		require.Equal(t, 0, delivered)
+		require.Empty(t, receiver.received())
+		require.Contains(t, store.Deliveries()[0].LastError, "private address")
+	})
+
+	t.Run("Should double the backoff up to the max retry delay", func(t *testing.T) {
+		dispatcher := webhook.New(webhook.NewMemoryStore(), webhook.Config{RetryDelay: time.Second, MaxRetryDelay: 5 * time.Second})
+
+		require.Equal(t, time.Second, dispatcher.Backoff(1))
+		require.Equal(t, 2*time.Second, dispatcher.Backoff(2))
+		require.Equal(t, 4*time.Second, dispatcher.Backoff(3))
+		require.Equal(t, 5*time.Second, dispatcher.Backoff(4))
+		require.Equal(t, 5*time.Second, dispatcher.Backoff(40))
+	})
+}
+
+func TestValidate(t *testing.T) {
+	valid := models.WebhookSubscription{URL: "https://hooks.test/users", Secret: secret, EventTypes: []string{models.AuditUserCreated}}
+
+	t.Run("Should accept a valid subscription", func(t *testing.T) {
+		require.NoError(t, webhook.Validate(valid, config()))
+	})
+
+	t.Run("Should refuse an URL that isn't http or https", func(t *testing.T) {
+		subscription := valid
+		subscription.URL = "ftp://hooks.test/users"
+
+		require.Error(t, webhook.Validate(subscription, config()))
+
+		subscription.URL = "/users"
+
+		require.Error(t, webhook.Validate(subscription, config()))
+	})
+
+	t.Run("Should refuse a short secret", func(t *testing.T) {
+		subscription := valid
+		subscription.Secret = "short"
+
+		require.Error(t, webhook.Validate(subscription, config()))
+	})
+
+	t.Run("Should refuse the unknown event types", func(t *testing.T) {
+		subscription := valid
+		subscription.EventTypes = []string{"user.unknown"}
+
+		require.Error(t, webhook.Validate(subscription, config()))
+
+		subscription.EventTypes = nil
+
+		require.Error(t, webhook.Validate(subscription, config()))
+	})
+	t.Run("Should refuse the internal addresses unless the private networks are allowed", func(t *testing.T) {
+		restricted := config()
+		restricted.AllowPrivateNetworks = false
+
+		for _, address := range []string{
+			"http://127.0.0.1:8080/users",
+			"http://localhost/users",
+			"http://169.254.169.254/latest/meta-data",
+			"http://10.0.0.1/users",
+			"http://192.168.1.10/users",
+			"http://[::1]/users",
+			"http://0.0.0.0/users",
+		} {
+			subscription := valid
+			subscription.URL = address
+
+			require.Error(t, webhook.Validate(subscription, restricted), address)
+			require.NoError(t, webhook.Validate(subscription, config()), address)
+		}
+
+		subscription := valid
+		subscription.URL = "https://203.0.113.7/users"
+
+		require.NoError(t, webhook.Validate(subscription, restricted))
+	})
+}
@@ -0,0 +1,45 @@
+// Package worker runs the background jobs of the server, like the relay of the outbox and the dispatcher of the
+// webhooks, and computes the exponential backoff of the jobs that retry their failures
+package worker
+
+import (
+	"log"
+	"time"
+)
+
+// Start runs the job on every interval, until the returned function is called. The errors of the job are logged
+// with the description of what it does, like "publish the outbox messages", and don't stop the next runs
+func Start(interval time.Duration, description string, job func() (int, error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := job(); err != nil {
+					log.Printf("Error while trying to %s: %v", description, err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// Backoff returns how long an item waits after its failed attempts: the delay, doubled after each attempt, up to
+// the max delay
+func Backoff(delay, maxDelay time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
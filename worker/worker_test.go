@@ -0,0 +1,40 @@
+package worker_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LucasFrezarini/go-auth-manager/worker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart(t *testing.T) {
+	t.Run("Should run the job on every interval until it's stopped", func(t *testing.T) {
+		var runs int32
+
+		stop := worker.Start(time.Millisecond, "count the runs", func() (int, error) {
+			atomic.AddInt32(&runs, 1)
+			return 0, nil
+		})
+
+		time.Sleep(20 * time.Millisecond)
+		stop()
+
+		stopped := atomic.LoadInt32(&runs)
+		time.Sleep(10 * time.Millisecond)
+
+		require.True(t, stopped > 1)
+		require.True(t, atomic.LoadInt32(&runs) <= stopped+1)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("Should double the delay up to the max delay", func(t *testing.T) {
+		require.Equal(t, time.Second, worker.Backoff(time.Second, 5*time.Second, 1))
+		require.Equal(t, 2*time.Second, worker.Backoff(time.Second, 5*time.Second, 2))
+		require.Equal(t, 4*time.Second, worker.Backoff(time.Second, 5*time.Second, 3))
+		require.Equal(t, 5*time.Second, worker.Backoff(time.Second, 5*time.Second, 4))
+		require.Equal(t, 5*time.Second, worker.Backoff(time.Second, 5*time.Second, 40))
+	})
+}